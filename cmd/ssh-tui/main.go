@@ -1,15 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"ssh-tui/internal/cli"
+	"ssh-tui/internal/history"
+	"ssh-tui/internal/hostkey"
 	"ssh-tui/internal/parser"
+	"ssh-tui/internal/settings"
 	"ssh-tui/internal/ssh"
+	"ssh-tui/internal/sshagent"
+	"ssh-tui/internal/tui/agentkeys"
+	"ssh-tui/internal/tui/hostform"
+	"ssh-tui/internal/tui/hostkeys"
 	"ssh-tui/internal/tui/hostselector"
+	"ssh-tui/internal/tui/keychange"
+	"ssh-tui/internal/tui/keydeploy"
 	"ssh-tui/internal/tui/optionsentry"
 	"ssh-tui/internal/types"
-	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -23,6 +37,8 @@ func main() {
 	if len(os.Args) == 2 {
 		switch os.Args[1] {
 		case "--help":
+			cli.PrintUsage(os.Stdout)
+			fmt.Println()
 			_ = ssh.ExecuteSSHCommand("ssh")
 			return
 		case "--version":
@@ -31,31 +47,100 @@ func main() {
 		}
 	}
 
-	// If CLI args were provided, treat them as a direct ssh invocation and execute immediately
 	if len(os.Args) > 1 {
-		cmd := "ssh " + strings.Join(os.Args[1:], " ")
+		if command, ok := cli.Lookup(os.Args[1]); ok {
+			os.Exit(runCommand(command, os.Args[2:]))
+		}
 
-		_ = ssh.ExecuteSSHCommand(cmd)
-		return
+		// Anything else is a direct ssh invocation; "--" forces this for hosts named like a command.
+		// Without a destination the selector is opened and the ssh options are carried over.
+		args := os.Args[1:]
+		interactive := false
+		if args[0] == "--" {
+			args = args[1:]
+		} else {
+			var err error
+			launch, interactive, err = cli.ParseLaunch(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+		}
+
+		if !interactive {
+			os.Exit(sshExitCode(ssh.ExecuteSSH(args)))
+		}
 	}
 
-	hosts, err := parser.DiscoverHosts()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error discovering SSH hosts: %v\n", err)
+	userSettings = loadSettings()
+	registerSources(userSettings)
+
+	if err := runTUIFlow(nil); err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if !errors.Is(err, errNoHosts) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(1)
 	}
+}
 
-	if len(hosts) == 0 {
-		showNoHostsMessage()
-		os.Exit(1)
+// sshExitCode returns the exit code for the result of running ssh: ssh's own status if it ran,
+// or 255, which ssh uses for its own errors, after reporting why it could not be started
+func sshExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return 255
+}
+
+// runCommand runs a subcommand and returns the process exit code
+func runCommand(command *cli.Command, args []string) int {
+	env := cli.Env{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Sources: func() []parser.HostSource {
+			userSettings = loadSettings()
+			registerSources(userSettings)
+			return parser.EnabledSources(userSettings.SourceEnabled)
+		},
 	}
 
-	if err := runTUIFlow(hosts); err != nil {
+	err := command.Run(args, env)
+	switch {
+	case err == nil || errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, cli.ErrCancelled):
+		return 1
+	default:
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 }
 
+// hostKeyCheckTimeout bounds the host key check made before connecting
+const hostKeyCheckTimeout = 3 * time.Second
+
+// userSettings holds the settings loaded at startup
+var userSettings = &settings.Settings{}
+
+// launch holds the search query and ssh options given on the command line
+var launch cli.Launch
+
+// probeHosts keeps the reachability probes switched on when returning to the host selector
+var probeHosts bool
+
+// errNoHosts is returned when discovery finished without finding any hosts and the user left
+// the selector without choosing a custom host
+var errNoHosts = errors.New("no SSH hosts found")
+
 // showNoHostsMessage displays a helpful message when no hosts are found
 func showNoHostsMessage() {
 	titleStyle := lipgloss.NewStyle().
@@ -80,9 +165,32 @@ func showNoHostsMessage() {
 	fmt.Println(messageStyle.Render("    Port 22"))
 }
 
-// runTUIFlow runs the complete TUI flow for host selection and connection
+// runTUIFlow runs the complete TUI flow for host selection and connection.
+// When hosts is nil, hosts are discovered asynchronously while the selector is shown.
 func runTUIFlow(hosts []types.SSHHost) error {
-	hostSelectorModel := hostselector.NewHostSelectorModel(hosts)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sources := parser.EnabledSources(userSettings.SourceEnabled)
+
+	var hostSelectorModel *hostselector.HostSelectorModel
+	if hosts == nil {
+		hostSelectorModel = hostselector.NewStreamingHostSelectorModel(parser.SourceNames(sources), cli.StreamHosts(ctx, sources))
+	} else {
+		hostSelectorModel = hostselector.NewHostSelectorModel(hosts)
+	}
+	// Hosts are discovered again when the SSH config, its includes or known_hosts change
+	if changes := parser.WatchSources(ctx, sources); changes != nil {
+		hostSelectorModel.WatchSources(parser.SourceNames(sources), changes, func() <-chan parser.Batch {
+			return cli.StreamHosts(ctx, sources)
+		})
+	}
+	hostSelectorModel.SetConfigDiagnostics(configDiagnostics())
+	hostSelectorModel.SetProbing(probeHosts)
+	if launch.Query != "" {
+		// Only the first selector starts with the query, not the ones reached by going back
+		hostSelectorModel.SetQuery(launch.Query)
+		launch.Query = ""
+	}
 
 	program := tea.NewProgram(hostSelectorModel, tea.WithAltScreen())
 	finalModel, err := program.Run()
@@ -96,12 +204,40 @@ func runTUIFlow(hosts []types.SSHHost) error {
 	}
 
 	selectedHost := hostModel.GetSelectedHost()
+	probeHosts = hostModel.Probing()
+
+	// Reuse the discovered hosts when navigating back, unless discovery was cut short
+	hosts = hostModel.Hosts()
+	if !hostModel.DiscoveryDone() {
+		hosts = nil
+	}
+
+	switch hostModel.RequestedAction() {
+	case hostselector.ActionNewHost:
+		return runHostFormFlow(hostform.NewCreateModel(selectedHost), hosts)
+	case hostselector.ActionEditHost:
+		return runHostFormFlow(hostform.NewEditModel(selectedHost), hosts)
+	case hostselector.ActionDeleteHost:
+		return runHostFormFlow(hostform.NewDeleteModel(selectedHost), hosts)
+	case hostselector.ActionKnownHosts:
+		return runKnownHostsFlow(hostModel.Hosts(), hosts)
+	case hostselector.ActionAgent:
+		return runAgentFlow(hosts)
+	case hostselector.ActionKeys:
+		return runKeysFlow(selectedHost, hostModel.Hosts(), hosts)
+	}
+
 	if selectedHost == nil || !hostModel.IsSelected() {
+		if len(hosts) == 0 && hostModel.DiscoveryDone() {
+			showNoHostsMessage()
+			return errNoHosts
+		}
 		return nil
 	}
 
-	if hostModel.OpenOptionsRequested() {
-		return runOptionsFlow(selectedHost, hosts)
+	// Custom hosts go through the preview, which shows their host key before connecting
+	if hostModel.OpenOptionsRequested() || len(launch.SSHOptions) > 0 || selectedHost.Source == types.SourceCustom {
+		return runOptionsFlow(selectedHost, hosts, nil)
 	}
 
 	// Build command using default/empty options
@@ -111,6 +247,12 @@ func runTUIFlow(hosts []types.SSHHost) error {
 		return fmt.Errorf("invalid SSH command: %w", err)
 	}
 
+	if ok, err := confirmHostKey(selectedHost, nil, hosts); !ok {
+		return err
+	}
+
+	recordConnection(selectedHost)
+
 	if err := ssh.ExecuteSSHCommand(command); err != nil {
 		return fmt.Errorf("SSH execution failed: %w", err)
 	}
@@ -118,9 +260,24 @@ func runTUIFlow(hosts []types.SSHHost) error {
 	return nil
 }
 
-// runOptionsFlow runs the options entry and subsequent steps (for back navigation)
-func runOptionsFlow(selectedHost *types.SSHHost, hosts []types.SSHHost) error {
-	optionsEntryModel := optionsentry.NewOptionsEntryModel(selectedHost)
+// runOptionsFlow runs the options entry and subsequent steps (for back navigation). report is
+// a host key check already made, shown instead of checking again.
+func runOptionsFlow(selectedHost *types.SSHHost, hosts []types.SSHHost, report *hostkey.Report) error {
+	optionsEntryModel := optionsentry.NewOptionsEntryModelWithOptions(selectedHost, launch.SSHOptions)
+	if report != nil {
+		optionsEntryModel.SetHostKeyCheck(func() hostkey.Report {
+			return *report
+		})
+	} else if selectedHost.Source == types.SourceCustom {
+		optionsEntryModel.SetHostKeyCheck(func() hostkey.Report {
+			return hostKeyChecker().Check(context.Background(), *selectedHost)
+		})
+	}
+	if len(sshagent.IdentityFiles(*selectedHost)) > 0 {
+		optionsEntryModel.SetAgentCheck(func() sshagent.Report {
+			return sshagent.Check(sshagent.Socket(), *selectedHost)
+		})
+	}
 
 	program := tea.NewProgram(optionsEntryModel, tea.WithAltScreen())
 	finalModel, err := program.Run()
@@ -146,19 +303,321 @@ func runOptionsFlow(selectedHost *types.SSHHost, hosts []types.SSHHost) error {
 		return fmt.Errorf("invalid SSH options: contains potentially dangerous characters")
 	}
 
-	command := optionsModel.GetCommand()
-
-	if err := ssh.ValidateSSHCommand(command); err != nil {
+	// Arguments carried from the command line are passed on unchecked, like a direct ssh invocation
+	if err := ssh.ValidateSSHCommand(ssh.BuildSSHCommand(selectedHost, options)); err != nil {
 		return fmt.Errorf("invalid SSH command: %w", err)
 	}
 
-	if err := ssh.ExecuteSSHCommand(command); err != nil {
+	if ok, err := confirmHostKey(selectedHost, optionsModel.HostKeyReport(), hosts); !ok {
+		return err
+	}
+
+	// A key that fails to load is not fatal: ssh then asks for its passphrase itself
+	if keys, constraints := optionsModel.AgentLoad(); len(keys) > 0 {
+		if err := sshagent.Add(keys, constraints); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	recordConnection(selectedHost)
+
+	if err := ssh.ExecuteSSH(optionsModel.GetArgs()); err != nil {
 		return fmt.Errorf("SSH execution failed: %w", err)
 	}
 
 	return nil
 }
 
+// runHostFormFlow runs the host form, applies the change to the SSH config and returns to host selection
+func runHostFormFlow(formModel *hostform.HostFormModel, hosts []types.SSHHost) error {
+	program := tea.NewProgram(formModel, tea.WithAltScreen())
+	finalModel, err := program.Run()
+	if err != nil {
+		return fmt.Errorf("failed to run host form: %w", err)
+	}
+
+	formModel, ok := finalModel.(*hostform.HostFormModel)
+	if !ok {
+		return fmt.Errorf("unexpected model type from host form")
+	}
+
+	if formModel.IsCancelled() {
+		return runTUIFlow(hosts)
+	}
+
+	if !formModel.IsConfirmed() {
+		return nil
+	}
+
+	switch formModel.Mode() {
+	case hostform.ModeCreate:
+		configPath, err := parser.UserConfigPath()
+		if err != nil {
+			return err
+		}
+		if err := parser.AddHostBlock(configPath, formModel.Block()); err != nil {
+			return fmt.Errorf("failed to add host: %w", err)
+		}
+	case hostform.ModeEdit:
+		host := formModel.Host()
+		if err := parser.UpdateHostBlock(host.SourceFile, host.SourceLine, formModel.Block()); err != nil {
+			return fmt.Errorf("failed to update host: %w", err)
+		}
+	case hostform.ModeDelete:
+		host := formModel.Host()
+		if err := parser.DeleteHostBlock(host.SourceFile, host.SourceLine); err != nil {
+			return fmt.Errorf("failed to delete host: %w", err)
+		}
+	}
+
+	// Rediscover so the list reflects the change
+	return runTUIFlow(nil)
+}
+
+// runKnownHostsFlow runs the known_hosts management screen and returns to host selection,
+// rediscovering hosts when known_hosts was changed. discovered resolves the names in the
+// connection history, which tells stale entries apart.
+func runKnownHostsFlow(discovered, hosts []types.SSHHost) error {
+	path, err := parser.UserKnownHostsPath()
+	if err != nil {
+		return err
+	}
+	file, err := hostkey.LoadKnownHosts(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	program := tea.NewProgram(hostkeys.NewHostKeysModel(file, hostkey.Contacts(discovered, loadHistory())), tea.WithAltScreen())
+	finalModel, err := program.Run()
+	if err != nil {
+		return fmt.Errorf("failed to run known_hosts screen: %w", err)
+	}
+
+	keysModel, ok := finalModel.(*hostkeys.HostKeysModel)
+	if !ok {
+		return fmt.Errorf("unexpected model type from known_hosts screen")
+	}
+
+	if !keysModel.IsClosed() {
+		return nil
+	}
+	if keysModel.Changed() {
+		return runTUIFlow(nil)
+	}
+	return runTUIFlow(hosts)
+}
+
+// runAgentFlow runs the ssh-agent panel and returns to host selection
+func runAgentFlow(hosts []types.SSHHost) error {
+	socket := sshagent.Socket()
+	agentModel := agentkeys.NewAgentKeysModel(socket, func() ([]sshagent.Identity, error) {
+		return sshagent.List(socket)
+	})
+
+	program := tea.NewProgram(agentModel, tea.WithAltScreen())
+	finalModel, err := program.Run()
+	if err != nil {
+		return fmt.Errorf("failed to run agent panel: %w", err)
+	}
+
+	agentModel, ok := finalModel.(*agentkeys.AgentKeysModel)
+	if !ok {
+		return fmt.Errorf("unexpected model type from agent panel")
+	}
+
+	if !agentModel.IsClosed() {
+		return nil
+	}
+	return runTUIFlow(hosts)
+}
+
+// runKeysFlow runs the key screen for the discovered hosts, with selected preselected, and
+// returns to host selection, rediscovering hosts when IdentityFile lines were added
+func runKeysFlow(selected *types.SSHHost, discovered, hosts []types.SSHHost) error {
+	configPath, err := parser.UserConfigPath()
+	if err != nil {
+		return err
+	}
+
+	program := tea.NewProgram(keydeploy.NewKeyDeployModel(filepath.Dir(configPath), discovered, selected), tea.WithAltScreen())
+	finalModel, err := program.Run()
+	if err != nil {
+		return fmt.Errorf("failed to run key screen: %w", err)
+	}
+
+	keysModel, ok := finalModel.(*keydeploy.KeyDeployModel)
+	if !ok {
+		return fmt.Errorf("unexpected model type from key screen")
+	}
+
+	if !keysModel.IsClosed() {
+		return nil
+	}
+	if keysModel.Changed() {
+		return runTUIFlow(nil)
+	}
+	return runTUIFlow(hosts)
+}
+
+// loadSettings loads the user's settings; an unreadable file is reported and defaults are used
+func loadSettings() *settings.Settings {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		return &settings.Settings{}
+	}
+	s, err := settings.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using default settings\n", err)
+	}
+	return s
+}
+
+// registerSources registers the configurable host sources: hosts imported from other clients,
+// Ansible inventories, which are enabled once paths are configured, the opt-in hosts file source,
+// and the external commands declared in the settings. Sources that cannot be registered are
+// reported and skipped.
+func registerSources(s *settings.Settings) {
+	if importDir, err := settings.ImportDir(); err == nil {
+		if err := parser.Register(parser.NewImportedSource(importDir), true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	ansiblePaths := s.SourcePaths(parser.SourceAnsible)
+	if err := parser.Register(parser.NewAnsibleSource(ansiblePaths), len(ansiblePaths) > 0); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := parser.Register(parser.NewEtcHostsSource(s.SourcePaths(parser.SourceEtcHosts)), false); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = ""
+	}
+
+	for _, c := range s.Commands {
+		timeout, _ := c.TimeoutDuration() // validated when the settings were loaded
+		cfg := parser.CommandSourceConfig{Name: c.Name, Command: c.Command, Args: c.Args, Timeout: timeout}
+		if cacheDir != "" {
+			cfg.CachePath = filepath.Join(cacheDir, "ssh-tui", "sources", c.Name+".json")
+		}
+		if err := parser.Register(parser.NewCommandSource(cfg), true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// configDiagnostics lints the user's SSH config when the config source is enabled; failures are
+// ignored since the result is only shown as a hint
+func configDiagnostics() []parser.Diagnostic {
+	if !userSettings.SourceEnabled(types.SourceConfig, true) {
+		return nil
+	}
+	path, err := parser.UserConfigPath()
+	if err != nil {
+		return nil
+	}
+	diagnostics, _ := parser.LintConfigFile(path)
+	return diagnostics
+}
+
+// confirmHostKey compares the host's key with known_hosts before connecting, reusing report when
+// the preview already checked it. When the key changed, the key change screen shows the old and
+// new fingerprints and can remove the stale entries. A key not matching the published
+// fingerprints opens the preview, which asks for confirmation, unless the preview already did.
+// It returns true when the connection should go ahead; otherwise the flow continued elsewhere
+// and err is its result.
+func confirmHostKey(host *types.SSHHost, report *hostkey.Report, hosts []types.SSHHost) (bool, error) {
+	previewed := report != nil
+	if !previewed {
+		if !hostkey.Verifiable(parser.UserConfigOptions().For(*host)) {
+			return true, nil
+		}
+		checker := hostKeyChecker()
+		checker.Timeout = hostKeyCheckTimeout
+		r := checker.Check(context.Background(), *host)
+		report = &r
+	}
+	// Hosts that cannot be reached are left to ssh to report
+	if report.Err != nil {
+		return true, nil
+	}
+	if report.Known != hostkey.KnownChanged {
+		if !previewed && report.TrustedChecked && report.Trusted == hostkey.VerdictMismatch {
+			return false, runOptionsFlow(host, hosts, report)
+		}
+		return true, nil
+	}
+
+	knownHostsPath, err := parser.UserKnownHostsPath()
+	if err != nil {
+		return false, err
+	}
+	program := tea.NewProgram(keychange.NewKeyChangeModel(host, *report, knownHostsPath), tea.WithAltScreen())
+	finalModel, err := program.Run()
+	if err != nil {
+		return false, fmt.Errorf("failed to run key change screen: %w", err)
+	}
+
+	keyModel, ok := finalModel.(*keychange.KeyChangeModel)
+	if !ok {
+		return false, fmt.Errorf("unexpected model type from key change screen")
+	}
+
+	if keyModel.IsCancelled() {
+		return false, runTUIFlow(hosts)
+	}
+
+	if !keyModel.IsConfirmed() {
+		return false, nil
+	}
+
+	file, err := hostkey.LoadKnownHosts(knownHostsPath)
+	if err != nil {
+		return false, err
+	}
+	if len(file.RemoveHost(report.KnownHostsName)) == 0 {
+		return false, fmt.Errorf("no key naming %s found in %s; keys matching it through wildcard patterns must be removed by hand", report.KnownHostsName, knownHostsPath)
+	}
+	if err := file.Save(); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", knownHostsPath, err)
+	}
+	return true, nil
+}
+
+// hostKeyChecker returns the checker comparing host keys with the user's known_hosts and the
+// fingerprints published in the settings
+func hostKeyChecker() *hostkey.Checker {
+	var knownHosts []string
+	if path, err := parser.UserKnownHostsPath(); err == nil {
+		knownHosts = append(knownHosts, path)
+	}
+	return hostkey.NewChecker(knownHosts, userSettings.TrustedFingerprints)
+}
+
+// loadHistory loads the connection history, returning nil if it is unavailable
+func loadHistory() *history.History {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	h, err := history.Load(path)
+	if err != nil {
+		return nil
+	}
+	return h
+}
+
+// recordConnection stores the connection time for host; failures are ignored since history is best-effort
+func recordConnection(host *types.SSHHost) {
+	h := loadHistory()
+	if h == nil {
+		return
+	}
+	h.Record(host.Name, time.Now())
+	_ = h.Save()
+}
+
 func init() {
 	// Set up logging to suppress tea debug output
 	log.SetOutput(os.Stderr)
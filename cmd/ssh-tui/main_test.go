@@ -1,7 +1,11 @@
 package main
 
 import (
+	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -48,3 +52,38 @@ func TestDirectSSHCommand(t *testing.T) {
 	// err may be nil or not, depending on ssh availability
 	_ = err
 }
+
+// TestDirectSSHExitCode verifies that the exit status of ssh is passed through unchanged
+func TestDirectSSHExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ssh")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "ssh-tui")
+	if out, err := exec.Command("go", "build", "-o", binary, "./main.go").CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+
+	fakeDir := filepath.Join(dir, "bin")
+	if err := os.Mkdir(fakeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fakeDir, "ssh"), []byte("#!/bin/sh\nexit 42\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.Command(binary, "user@host")
+	run.Env = append(os.Environ(), "PATH="+fakeDir)
+	var exitErr *exec.ExitError
+	if err := run.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 42 {
+		t.Fatalf("expected exit status 42, got %v", err)
+	}
+
+	// Without ssh the failure is reported and ssh's error status is used
+	run = exec.Command(binary, "user@host")
+	run.Env = append(os.Environ(), "PATH="+dir+"/empty")
+	out, err := run.CombinedOutput()
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 255 || !strings.Contains(string(out), "Error:") {
+		t.Fatalf("expected exit status 255 with an error message, got %v\n%s", err, out)
+	}
+}
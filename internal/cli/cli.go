@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"ssh-tui/internal/history"
+	"ssh-tui/internal/parser"
+)
+
+// Env is what a subcommand needs from the process
+type Env struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Sources returns the enabled host sources; it is only called by commands that discover hosts
+	Sources func() []parser.HostSource
+}
+
+// Command is an ssh-tui subcommand
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(args []string, env Env) error
+	// Flags lists the flag names offered by shell completion; names of flags that take a value end in "="
+	Flags []string
+	// Args lists the fixed values offered by shell completion for positional arguments
+	Args []string
+	// Hidden commands are left out of the usage and completion
+	Hidden bool
+}
+
+// Commands lists the subcommands in the order shown by "ssh-tui help"
+var Commands []Command
+
+// init fills in Commands; it is not a plain initializer because "help" refers back to it
+func init() {
+	Commands = []Command{
+		{Name: "pick", Summary: "choose a host and print it instead of connecting", Run: func(args []string, env Env) error {
+			return Pick(args, env.Sources(), env.Stdout, env.Stderr)
+		}, Flags: []string{"print="}},
+		{Name: "list", Summary: "print the discovered hosts", Run: func(args []string, env Env) error {
+			return List(args, env.Sources(), env.Stdout, env.Stderr)
+		}, Flags: []string{"filter=", "json"}},
+		{Name: "export", Summary: "write the discovered hosts as JSON, CSV or ssh_config", Run: func(args []string, env Env) error {
+			return Export(args, env.Sources(), env.Stdout, env.Stderr)
+		}, Flags: []string{"format=", "output=", "source="}},
+		{Name: "import", Summary: "import sessions from PuTTY, Termius or MobaXterm", Run: func(args []string, env Env) error {
+			return Import(args, env.Stdout, env.Stderr)
+		}, Flags: []string{"format=", "write=", "name=", "dry-run"}},
+		{Name: "doctor", Summary: "check the SSH setup for common problems", Run: func(args []string, env Env) error {
+			return Doctor(args, env.Stdout, env.Stderr)
+		}, Flags: []string{"json"}},
+		{Name: "completion", Summary: "print a shell completion script (bash, zsh or fish)", Run: func(args []string, env Env) error {
+			return Completion(args, env.Stdout, env.Stderr)
+		}, Args: []string{"bash", "zsh", "fish"}},
+		{Name: "help", Summary: "show this help", Run: func(args []string, env Env) error {
+			PrintUsage(env.Stdout)
+			return nil
+		}},
+		{Name: "__complete", Summary: "print completion candidates", Run: func(args []string, env Env) error {
+			return Complete(args, env.Sources, env.Stdout)
+		}, Hidden: true},
+	}
+}
+
+// Lookup returns the subcommand with the given name
+func Lookup(name string) (*Command, bool) {
+	for i := range Commands {
+		if Commands[i].Name == name {
+			return &Commands[i], true
+		}
+	}
+	return nil, false
+}
+
+// PrintUsage describes how to invoke ssh-tui and lists the subcommands
+func PrintUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  ssh-tui [--query QUERY] [SSH_OPTIONS...]")
+	fmt.Fprintln(w, "                              choose a host interactively, with the search prefilled")
+	fmt.Fprintln(w, "                              and any ssh options carried into the options screen")
+	fmt.Fprintln(w, "  ssh-tui COMMAND [flags]     run a subcommand")
+	fmt.Fprintln(w, "  ssh-tui [--] SSH_ARGS...    run ssh directly (use -- for hosts named like a command)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range Commands {
+		if !c.Hidden {
+			fmt.Fprintf(w, "  %-11s %s\n", c.Name, c.Summary)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run \"ssh-tui COMMAND --help\" for a command's flags.")
+}
+
+// parseFlags parses args with fs, allowing flags to follow positional arguments
+// (e.g. "import export.reg --write out.conf"), and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors instead of exiting
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		io.WriteString(stderr, "Usage: ssh-tui "+usage+"\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadHistory loads the connection history, returning nil if it is unavailable
+func loadHistory() *history.History {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	h, err := history.Load(path)
+	if err != nil {
+		return nil
+	}
+	return h
+}
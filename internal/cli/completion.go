@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/ssh"
+	"ssh-tui/internal/types"
+)
+
+// completeTimeout bounds host discovery while completing, so a slow source cannot stall the shell
+const completeTimeout = time.Second
+
+// topLevelFlags are the flags accepted by ssh-tui itself
+var topLevelFlags = []string{"--help", "--version", "--query"}
+
+// completionScripts holds the completion script for each supported shell. Each one asks
+// "ssh-tui __complete" for candidates and falls back to file names when there are none.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for ssh-tui; load with: source <(ssh-tui completion bash)
+_ssh_tui() {
+    local cur words cword
+    # Keep user@host, host:port and --flag=value whole although COMP_WORDBREAKS splits them
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n @:= cur words cword
+    else
+        local line=${COMP_LINE:0:COMP_POINT}
+        read -ra words <<<"$line"
+        [[ $line == *[[:space:]] || ${#words[@]} -eq 0 ]] && words+=("")
+        cword=$((${#words[@]} - 1))
+        cur=${words[cword]}
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($(ssh-tui __complete "${words[@]:1:cword}" 2>/dev/null))
+    # Bash replaces only the part of the word after the last break character, like
+    # __ltrim_colon_completions does for colons
+    local prefix=${cur%"${cur##*[$COMP_WORDBREAKS]}"}
+    [[ -n $prefix ]] && COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+}
+complete -o default -F _ssh_tui ssh-tui
+`,
+	"zsh": `#compdef ssh-tui
+# zsh completion for ssh-tui; load with: source <(ssh-tui completion zsh)
+_ssh-tui() {
+    local -a candidates
+    candidates=(${(f)"$(ssh-tui __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#candidates} )); then
+        compadd -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+if [ "$funcstack[1]" = "_ssh-tui" ]; then
+    _ssh-tui "$@"
+else
+    compdef _ssh-tui ssh-tui
+fi
+`,
+	"fish": `# fish completion for ssh-tui; load with: ssh-tui completion fish | source
+function __ssh_tui_complete
+    set -l candidates (ssh-tui __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)
+    if test (count $candidates) -gt 0
+        printf '%s\n' $candidates
+    else
+        __fish_complete_path (commandline -ct)
+    end
+end
+complete -c ssh-tui -f -a '(__ssh_tui_complete)'
+`,
+}
+
+// Completion implements "ssh-tui completion SHELL": it prints the completion script for SHELL
+func Completion(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("completion", "completion bash|zsh|fish", stderr)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a shell name")
+	}
+
+	script, ok := completionScripts[rest[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", rest[0])
+	}
+	_, err = io.WriteString(stdout, script)
+	return err
+}
+
+// Complete implements the hidden "ssh-tui __complete" command used by the completion scripts.
+// words are the arguments typed after "ssh-tui", the last being the (possibly empty) word under
+// the cursor; the matching candidates are printed one per line. Hosts are only discovered when
+// a host can be completed.
+func Complete(words []string, sources func() []parser.HostSource, stdout io.Writer) error {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	prev, cur := words[:len(words)-1], words[len(words)-1]
+
+	var candidates []string
+	switch {
+	case len(prev) == 0:
+		// The first word: a command, one of ssh-tui's flags or, as with ssh, a destination
+		if strings.HasPrefix(cur, "-") {
+			candidates = topLevelFlags
+			break
+		}
+		for _, c := range Commands {
+			if !c.Hidden {
+				candidates = append(candidates, c.Name)
+			}
+		}
+		candidates = append(candidates, destinations(cur, sources)...)
+	default:
+		if command, ok := Lookup(prev[0]); ok {
+			candidates = command.completeArg(prev[1:], cur)
+			break
+		}
+		candidates = completeSSHArg(prev, cur, sources)
+	}
+
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) {
+			fmt.Fprintln(stdout, c)
+		}
+	}
+	return nil
+}
+
+// completeArg returns the candidates for an argument of the command, given the arguments
+// before it: the command's flags, or its fixed arguments. A flag's value is left to the shell.
+func (c *Command) completeArg(prev []string, cur string) []string {
+	if len(prev) > 0 && c.takesValue(prev[len(prev)-1]) {
+		return nil
+	}
+	if strings.HasPrefix(cur, "-") {
+		var flags []string
+		for _, f := range c.Flags {
+			flags = append(flags, "--"+strings.TrimSuffix(f, "="))
+		}
+		return flags
+	}
+	return c.Args
+}
+
+// takesValue reports whether arg is one of the command's flags that is followed by a value
+func (c *Command) takesValue(arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	if name == arg || strings.Contains(name, "=") {
+		return false
+	}
+	for _, f := range c.Flags {
+		if f == name+"=" {
+			return true
+		}
+	}
+	return false
+}
+
+// completeSSHArg returns the candidates for an argument in an ssh command line: the destination
+// until one has been given, or the host to search for after --query. Option values, the remote
+// command and its arguments are left to the shell.
+func completeSSHArg(prev []string, cur string, sources func() []parser.HostSource) []string {
+	last := prev[len(prev)-1]
+	if last == "--query" {
+		return destinations(cur, sources)
+	}
+
+	var sshArgs []string
+	for i := 0; i < len(prev); i++ {
+		if prev[i] == "--query" {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(prev[i], "--query=") {
+			sshArgs = append(sshArgs, prev[i])
+		}
+	}
+	if ssh.OptionTakesNextArg(last) || strings.HasPrefix(cur, "-") {
+		return nil
+	}
+	if !ssh.ParseArgs(sshArgs).NeedsDestination() {
+		return nil
+	}
+	return destinations(cur, sources)
+}
+
+// destinations returns the names and aliases of the discovered hosts. When cur has a "user@"
+// prefix, it is kept on every candidate.
+func destinations(cur string, sources func() []parser.HostSource) []string {
+	var user string
+	if at := strings.LastIndex(cur, "@"); at != -1 {
+		user = cur[:at+1]
+	}
+
+	var names []string
+	for _, h := range completionHosts(sources()) {
+		for _, name := range append([]string{h.Name}, h.Aliases...) {
+			names = append(names, user+name)
+		}
+	}
+	return names
+}
+
+// completionHosts discovers hosts for completion: sources that have not finished within
+// completeTimeout are left out and errors are ignored, since completion must stay quiet
+func completionHosts(sources []parser.HostSource) []types.SSHHost {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := parser.StreamHosts(ctx, sources)
+	timeout := time.After(completeTimeout)
+	results := make(map[string][]types.SSHHost, len(sources))
+collect:
+	for {
+		select {
+		case batch, ok := <-batches:
+			if !ok {
+				break collect
+			}
+			results[batch.Source] = batch.Hosts
+		case <-timeout:
+			break collect
+		}
+	}
+
+	lists := make([][]types.SSHHost, 0, len(sources))
+	for _, source := range sources {
+		lists = append(lists, results[source.Name()])
+	}
+	return parser.MergeHosts(lists...)
+}
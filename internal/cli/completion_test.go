@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/parser"
+)
+
+func TestCompletion_Scripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var stdout bytes.Buffer
+		if err := Completion([]string{shell}, &stdout, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Completion(%s): %v", shell, err)
+		}
+		if !strings.Contains(stdout.String(), "ssh-tui __complete") {
+			t.Errorf("expected the %s script to call __complete:\n%s", shell, stdout.String())
+		}
+	}
+	if err := Completion([]string{"tcsh"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for an unsupported shell")
+	}
+}
+
+// TestCompletion_BashWordBreaks runs the bash script the way bash calls it for "ssh-tui root@g",
+// which COMP_WORDBREAKS splits into "root", "@" and "g"
+func TestCompletion_BashWordBreaks(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	var script bytes.Buffer
+	if err := Completion([]string{"bash"}, &script, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	driver := script.String() + `
+exec 3>&2
+ssh-tui() { printf '%s\n' "$@" >&3; printf '%s\n' root@git.example.com; }
+COMP_WORDBREAKS=$' \t\n"\'@><=;|&(:'
+COMP_LINE="ssh-tui root@g" COMP_POINT=14 COMP_WORDS=(ssh-tui root @ g) COMP_CWORD=3
+_ssh_tui
+printf '%s\n' "${COMPREPLY[@]}"
+`
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bash, "--norc", "-c", driver)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("bash: %v\n%s", err, stderr.String())
+	}
+	if got := strings.TrimSpace(stderr.String()); got != "__complete\nroot@g" {
+		t.Errorf("expected __complete to get the whole word, got %q", got)
+	}
+	// Bash puts the reply in place of the "g" after the @
+	if got := strings.TrimSpace(stdout.String()); got != "git.example.com" {
+		t.Errorf("expected the reply without the user@ prefix, got %q", got)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"p"}, []string{"pick"}},
+		{[]string{"w"}, []string{"web", "w", "web.example.com"}},
+		{[]string{"--q"}, []string{"--query"}},
+		{[]string{"root@g"}, []string{"root@git.example.com"}},
+		{[]string{"-L", "8080:localhost:80", "g"}, []string{"git.example.com"}},
+		{[]string{"--query", "we"}, []string{"web", "web.example.com"}},
+		{[]string{"--", "li"}, nil},
+		// Option values and remote commands are left to the shell
+		{[]string{"-i", ""}, nil},
+		{[]string{"web", "w"}, nil},
+		{[]string{"export", "--f"}, []string{"--format"}},
+		{[]string{"export", "--format", ""}, nil},
+		{[]string{"import", "--dry-run", "--"}, []string{"--format", "--write", "--name", "--dry-run"}},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		if err := Complete(tt.words, testSources, &stdout); err != nil {
+			t.Fatalf("Complete(%q): %v", tt.words, err)
+		}
+		got := strings.Fields(stdout.String())
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Complete(%q) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+// TestCommandFlags checks that the flags offered by completion match each command's flag set
+func TestCommandFlags(t *testing.T) {
+	setupHome(t)
+	env := Env{Sources: func() []parser.HostSource { return nil }}
+	for _, c := range Commands {
+		var stderr bytes.Buffer
+		env.Stdout, env.Stderr = &bytes.Buffer{}, &stderr
+		if err := c.Run([]string{"--help"}, env); c.Flags != nil && !errors.Is(err, flag.ErrHelp) {
+			t.Fatalf("%s --help: %v", c.Name, err)
+		}
+		usage := stderr.String()
+		for _, f := range c.Flags {
+			name, value := strings.CutSuffix(f, "=")
+			// Flags with a value are listed with their type, as in "  -format string"
+			if value && !strings.Contains(usage, "  -"+name+" ") || !value && !strings.Contains(usage, "  -"+name+"\n") {
+				t.Errorf("%s: flag %q does not match the usage:\n%s", c.Name, f, usage)
+			}
+		}
+		if got, want := strings.Count(usage, "\n  -"), len(c.Flags); got != want {
+			t.Errorf("%s: %d flags in the usage, %d offered by completion", c.Name, got, want)
+		}
+	}
+}
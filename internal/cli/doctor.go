@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"ssh-tui/internal/doctor"
+)
+
+// statusMarks are the symbols printed before each check in the doctor report
+var statusMarks = map[doctor.Status]string{
+	doctor.StatusOK:   "✓",
+	doctor.StatusWarn: "!",
+	doctor.StatusFail: "✗",
+	doctor.StatusSkip: "-",
+}
+
+// Doctor implements "ssh-tui doctor": it diagnoses the SSH setup and prints a report, or with
+// --json the report as JSON. It fails when any check failed, so it can be used in scripts.
+func Doctor(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("doctor", "doctor [--json]", stderr)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", rest[0])
+	}
+
+	opts, err := doctor.DefaultOptions()
+	if err != nil {
+		return err
+	}
+	return runDoctor(opts, *asJSON, stdout)
+}
+
+// runDoctor runs the checks with opts and writes the report
+func runDoctor(opts doctor.Options, asJSON bool, stdout io.Writer) error {
+	report := doctor.Run(context.Background(), opts)
+
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		writeReport(stdout, report)
+	}
+
+	if n := report.Failed(); n > 0 {
+		return fmt.Errorf("%d of %d checks failed", n, len(report.Checks))
+	}
+	return nil
+}
+
+// writeReport prints one line per check followed by its findings
+func writeReport(w io.Writer, report doctor.Report) {
+	for _, c := range report.Checks {
+		fmt.Fprintf(w, "%s %s: %s\n", statusMarks[c.Status], c.Name, c.Summary)
+		for _, f := range c.Findings {
+			message := f.Message
+			if f.Severity != "" {
+				message = string(f.Severity) + ": " + message
+			}
+			switch {
+			case f.Line > 0:
+				fmt.Fprintf(w, "    %s:%d: %s\n", f.File, f.Line, message)
+			case f.File != "":
+				fmt.Fprintf(w, "    %s: %s\n", f.File, message)
+			default:
+				fmt.Fprintf(w, "    %s\n", message)
+			}
+		}
+	}
+}
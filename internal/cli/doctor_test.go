@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/doctor"
+)
+
+func TestRunDoctor(t *testing.T) {
+	home := setupHome(t)
+	configPath := filepath.Join(home, ".ssh", "config")
+	writeFile(t, configPath, "Include missing.conf\nHost web\n  HostName web.example.com\n")
+	opts := doctor.Options{Home: home, SSHDir: filepath.Dir(configPath), ConfigPath: configPath}
+
+	var stdout bytes.Buffer
+	_ = runDoctor(opts, false, &stdout)
+	if !strings.Contains(stdout.String(), "! Include directives: 1 include skipped\n    "+configPath+":1: warning: Include \"missing.conf\" matches no files\n") {
+		t.Errorf("unexpected report:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	_ = runDoctor(opts, true, &stdout)
+	var report doctor.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(report.Checks) == 0 || report.Checks[0].Name != "ssh client" {
+		t.Errorf("unexpected JSON report: %+v", report)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(configPath, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := runDoctor(opts, false, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error when a check fails")
+	}
+}
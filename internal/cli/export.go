@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+)
+
+// Export formats
+const (
+	FormatJSON      = "json"
+	FormatCSV       = "csv"
+	FormatSSHConfig = "ssh_config"
+)
+
+// HostRecord is the JSON form of a host used by export and list
+type HostRecord struct {
+	Name          string            `json:"name"`
+	HostName      string            `json:"hostname,omitempty"`
+	User          string            `json:"user,omitempty"`
+	Port          string            `json:"port,omitempty"`
+	Source        string            `json:"source"`
+	Aliases       []string          `json:"aliases,omitempty"`
+	Description   string            `json:"description,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+	Notes         []string          `json:"notes,omitempty"`
+	SourceFile    string            `json:"source_file,omitempty"`
+	SourceLine    int               `json:"source_line,omitempty"`
+	Directives    []types.Directive `json:"directives,omitempty"`
+	HostKeys      []HostKeyRecord   `json:"host_keys,omitempty"`
+	LastConnected *time.Time        `json:"last_connected,omitempty"`
+}
+
+// HostKeyRecord is the JSON form of a known_hosts key
+type HostKeyRecord struct {
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+}
+
+// NewHostRecord converts a host to its JSON form
+func NewHostRecord(h types.SSHHost) HostRecord {
+	r := HostRecord{
+		Name:        h.Name,
+		HostName:    h.HostName,
+		User:        h.User,
+		Port:        h.Port,
+		Source:      h.Source,
+		Aliases:     h.Aliases,
+		Description: h.Description,
+		Tags:        h.Tags,
+		Notes:       h.Notes,
+		SourceFile:  h.SourceFile,
+		SourceLine:  h.SourceLine,
+		Directives:  h.Directives,
+	}
+	for _, k := range h.HostKeys {
+		r.HostKeys = append(r.HostKeys, HostKeyRecord(k))
+	}
+	if !h.LastConnected.IsZero() {
+		t := h.LastConnected
+		r.LastConnected = &t
+	}
+	return r
+}
+
+// Export implements "ssh-tui export": it discovers hosts from the given sources and writes
+// them as JSON, CSV or SSH config Host blocks
+func Export(args []string, sources []parser.HostSource, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export", "export [flags]", stderr)
+	format := fs.String("format", FormatJSON, "output format: json, csv or ssh_config")
+	output := fs.String("output", "", "write to this file instead of standard output")
+	only := fs.String("source", "", "only export hosts from these sources (comma-separated, e.g. known_hosts)")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", rest[0])
+	}
+
+	var write func(io.Writer, []types.SSHHost) error
+	switch *format {
+	case FormatJSON:
+		write = writeJSON
+	case FormatCSV:
+		write = writeCSV
+	case FormatSSHConfig:
+		write = writeSSHConfig
+	default:
+		return fmt.Errorf("unknown format %q (supported: json, csv, ssh_config)", *format)
+	}
+
+	hosts := discover(sources, stderr)
+	if *only != "" {
+		hosts = filterSources(hosts, strings.Split(*only, ","))
+	}
+
+	if *output == "" {
+		return write(stdout, hosts)
+	}
+	path, err := expandPath(*output)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := write(file, hosts); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "Exported %d host(s) to %s\n", len(hosts), path)
+	return nil
+}
+
+// discover runs discovery for a subcommand, annotating hosts with connection history.
+// Failing sources are reported as warnings on stderr rather than aborting the command.
+func discover(sources []parser.HostSource, stderr io.Writer) []types.SSHHost {
+	hosts, err := parser.DiscoverHosts(context.Background(), sources)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	if h := loadHistory(); h != nil {
+		h.Annotate(hosts)
+	}
+	return hosts
+}
+
+// filterSources keeps the hosts from the named sources
+func filterSources(hosts []types.SSHHost, names []string) []types.SSHHost {
+	var filtered []types.SSHHost
+	for _, h := range hosts {
+		for _, name := range names {
+			if strings.TrimSpace(name) == h.Source {
+				filtered = append(filtered, h)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// writeJSON writes hosts as an indented JSON array
+func writeJSON(w io.Writer, hosts []types.SSHHost) error {
+	records := make([]HostRecord, 0, len(hosts))
+	for _, h := range hosts {
+		records = append(records, NewHostRecord(h))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// csvHeader lists the CSV columns; aliases and tags are space-separated within their column
+var csvHeader = []string{"name", "hostname", "user", "port", "source", "aliases", "tags", "description", "source_file", "source_line", "last_connected"}
+
+// writeCSV writes hosts as CSV with a header row
+func writeCSV(w io.Writer, hosts []types.SSHHost) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, h := range hosts {
+		line := ""
+		if h.SourceLine > 0 {
+			line = strconv.Itoa(h.SourceLine)
+		}
+		lastConnected := ""
+		if !h.LastConnected.IsZero() {
+			lastConnected = h.LastConnected.Format(time.RFC3339)
+		}
+		record := []string{
+			h.Name, h.HostName, h.User, h.Port, h.Source,
+			strings.Join(h.Aliases, " "), strings.Join(h.Tags, " "), h.Description,
+			h.SourceFile, line, lastConnected,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeSSHConfig writes hosts as SSH config Host blocks. Hosts from the SSH config keep all
+// their directives; other hosts get HostName, User and Port. Descriptions, tags and notes are
+// kept as comments so the result can be read back by ssh-tui.
+func writeSSHConfig(w io.Writer, hosts []types.SSHHost) error {
+	config, err := parser.ParseConfig(strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hosts {
+		block := hostBlock(h)
+		if h.Source == types.SourceConfig {
+			block.Directives = nil
+			for _, d := range h.Directives {
+				block.Directives = append(block.Directives, types.Directive{Key: d.Key, Value: d.Value})
+			}
+		}
+		patterns := append([]string{h.Name}, h.Aliases...)
+
+		added := config.AddHostBlock(patterns, block.Directives)
+		added.AddNote(fmt.Sprintf("from %s", describeOrigin(h)))
+		for _, note := range h.Notes {
+			added.AddNote(note)
+		}
+		for _, comment := range block.Comments {
+			added.AddNote(comment)
+		}
+	}
+
+	_, err = io.WriteString(w, config.String())
+	return err
+}
+
+// describeOrigin names where a host came from, e.g. "known_hosts (~/.ssh/known_hosts:3)"
+func describeOrigin(h types.SSHHost) string {
+	if h.SourceFile == "" {
+		return h.Source
+	}
+	origin := fmt.Sprintf("%s (%s", h.Source, h.SourceFile)
+	if h.SourceLine > 0 {
+		origin += fmt.Sprintf(":%d", h.SourceLine)
+	}
+	return origin + ")"
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+)
+
+// staticSource is a HostSource returning fixed results
+type staticSource struct {
+	name  string
+	hosts []types.SSHHost
+	err   error
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) Discover(context.Context) ([]types.SSHHost, error) { return s.hosts, s.err }
+
+// testSources returns a config and a known_hosts source with overlapping hosts
+func testSources() []parser.HostSource {
+	return []parser.HostSource{
+		staticSource{name: types.SourceConfig, hosts: []types.SSHHost{{
+			Name: "web", Aliases: []string{"w"}, HostName: "web.example.com", Source: types.SourceConfig,
+			SourceFile: "/home/me/.ssh/config", SourceLine: 4, Tags: []string{"prod"}, Description: "Frontend",
+			Directives: []types.Directive{{Key: "HostName", Value: "web.example.com", Line: 5}, {Key: "ForwardAgent", Value: "yes", Line: 6}},
+		}}},
+		staticSource{name: types.SourceKnownHosts, hosts: []types.SSHHost{
+			{Name: "web.example.com", HostName: "web.example.com", Source: types.SourceKnownHosts,
+				HostKeys: []types.HostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:abc", File: "/home/me/.ssh/known_hosts", Line: 1}}},
+			{Name: "git.example.com", HostName: "git.example.com", Port: "2222", Source: types.SourceKnownHosts},
+		}},
+		staticSource{name: "cmdb", err: errors.New("timed out")},
+	}
+}
+
+func TestExport_JSON(t *testing.T) {
+	setupHome(t)
+	var stdout, stderr bytes.Buffer
+	if err := Export(nil, testSources(), &stdout, &stderr); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "cmdb: timed out") {
+		t.Errorf("expected a warning for the failing source, got %q", stderr.String())
+	}
+
+	var records []HostRecord
+	if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 hosts, got %d", len(records))
+	}
+	web := records[0]
+	if web.Source != types.SourceConfig || len(web.HostKeys) != 1 || web.HostKeys[0].Fingerprint != "SHA256:abc" || len(web.Directives) != 2 {
+		t.Errorf("unexpected web record: %+v", web)
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	setupHome(t)
+	var stdout, stderr bytes.Buffer
+	if err := Export([]string{"--format", "csv", "--source", "known_hosts"}, testSources(), &stdout, &stderr); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "name" {
+		t.Fatalf("expected a header and 2 known_hosts rows, got %v", rows)
+	}
+	if got := strings.Join(rows[2], ","); got != "git.example.com,git.example.com,,2222,known_hosts,,,,,," {
+		t.Errorf("unexpected row %q", got)
+	}
+}
+
+func TestExport_SSHConfig(t *testing.T) {
+	setupHome(t)
+	var stdout, stderr bytes.Buffer
+	if err := Export([]string{"--format=ssh_config"}, testSources(), &stdout, &stderr); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	want := `# from config (/home/me/.ssh/config:4)
+# @desc Frontend
+# @tags prod
+Host web w
+    HostName web.example.com
+    ForwardAgent yes
+
+# from known_hosts
+Host web.example.com
+
+# from known_hosts
+Host git.example.com
+    Port 2222
+`
+	if stdout.String() != want {
+		t.Errorf("ssh_config export =\n%s\nwant\n%s", stdout.String(), want)
+	}
+
+	// The export must read back as the same hosts
+	config, err := parser.ParseConfig(strings.NewReader(stdout.String()))
+	if err != nil || len(config.HostBlocks()) != 3 {
+		t.Fatalf("export did not parse back: %v", err)
+	}
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Export([]string{"--format", "xml"}, nil, &stdout, &stderr); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ssh-tui/internal/importer"
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/settings"
+	"ssh-tui/internal/types"
+)
+
+// invalidImportNameChars matches characters not allowed in import names
+var invalidImportNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Import implements "ssh-tui import": it reads sessions exported from PuTTY, Termius or
+// MobaXterm and either saves them as the read-only "imported" source or, with --write,
+// appends them as Host blocks to an SSH config fragment
+func Import(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import", "import [flags] FILE", stderr)
+	format := fs.String("format", "", "export format: "+strings.Join(importer.Formats, ", ")+" (detected by default)")
+	write := fs.String("write", "", "append the hosts as Host blocks to this SSH config fragment instead")
+	name := fs.String("name", "", "name of the import; importing again under the same name replaces it (default: the file name)")
+	dryRun := fs.Bool("dry-run", false, "list the hosts without saving them")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one file to import")
+	}
+
+	imported, err := importer.ImportFile(files[0], *format)
+	if err != nil {
+		return err
+	}
+
+	var hosts []types.SSHHost
+	for _, h := range imported {
+		if err := parser.ValidateHostEntry(h); err != nil {
+			fmt.Fprintf(stderr, "Skipping %s: %v\n", h.Name, err)
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no importable SSH sessions found in %s", files[0])
+	}
+
+	if *dryRun {
+		for _, h := range hosts {
+			fmt.Fprintf(stdout, "%s\t%s\n", h.Name, hostTarget(h))
+		}
+		return nil
+	}
+
+	if *write != "" {
+		return writeFragment(*write, hosts, stdout, stderr)
+	}
+
+	importName := *name
+	if importName == "" {
+		base := filepath.Base(files[0])
+		importName = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	importName = strings.Trim(invalidImportNameChars.ReplaceAllString(importName, "-"), "-")
+	if importName == "" {
+		return fmt.Errorf("invalid import name")
+	}
+	return saveImport(importName, hosts, stdout)
+}
+
+// saveImport stores hosts as an import file read by the imported source
+func saveImport(name string, hosts []types.SSHHost, stdout io.Writer) error {
+	dir, err := settings.ImportDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := parser.WriteHostLines(&buf, hosts); err != nil {
+		return err
+	}
+	path := parser.ImportedFilePath(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to save import: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Imported %d host(s) as the read-only %q source (%s)\n", len(hosts), parser.SourceImported, path)
+	return nil
+}
+
+// writeFragment appends hosts as Host blocks to the config fragment at path, skipping names
+// already defined there
+func writeFragment(path string, hosts []types.SSHHost, stdout, stderr io.Writer) error {
+	path, err := expandPath(path)
+	if err != nil {
+		return err
+	}
+	existing, err := parser.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	var blocks []parser.HostBlock
+	for _, h := range hosts {
+		if existing.FindHost(h.Name) != nil {
+			fmt.Fprintf(stderr, "Skipping %s: already defined in %s\n", h.Name, path)
+			continue
+		}
+		blocks = append(blocks, hostBlock(h))
+	}
+	if len(blocks) == 0 {
+		fmt.Fprintln(stdout, "Nothing to write")
+		return nil
+	}
+
+	if err := parser.AddHostBlocks(path, blocks); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(stdout, "Wrote %d Host block(s) to %s\n", len(blocks), path)
+
+	if included, err := configIncludes(path); err == nil && !included {
+		fmt.Fprintf(stdout, "Add \"Include %s\" near the top of ~/.ssh/config to use them\n", path)
+	}
+	return nil
+}
+
+// hostBlock converts a host into a config Host block, keeping its description and tags as annotations
+func hostBlock(h types.SSHHost) parser.HostBlock {
+	block := parser.HostBlock{Patterns: []string{h.Name}}
+	if h.Description != "" {
+		block.Comments = append(block.Comments, "@desc "+h.Description)
+	}
+	if len(h.Tags) > 0 {
+		block.Comments = append(block.Comments, "@tags "+strings.Join(h.Tags, ","))
+	}
+	if h.HostName != "" && !strings.EqualFold(h.HostName, h.Name) {
+		block.Directives = append(block.Directives, types.Directive{Key: "HostName", Value: h.HostName})
+	}
+	if h.User != "" {
+		block.Directives = append(block.Directives, types.Directive{Key: "User", Value: h.User})
+	}
+	if h.Port != "" && h.Port != types.DefaultSSHPort {
+		block.Directives = append(block.Directives, types.Directive{Key: "Port", Value: h.Port})
+	}
+	return block
+}
+
+// configIncludes reports whether the user's SSH config includes the file at path
+func configIncludes(path string) (bool, error) {
+	configPath, err := parser.UserConfigPath()
+	if err != nil {
+		return false, err
+	}
+	config, err := parser.LoadConfigFile(configPath)
+	if err != nil {
+		return false, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range config.Lines() {
+		if line.Kind != parser.LineDirective || !strings.EqualFold(line.Key, "Include") {
+			continue
+		}
+		for _, pattern := range strings.Fields(line.Value) {
+			matches, err := parser.ResolveInclude(pattern)
+			if err != nil {
+				continue
+			}
+			for _, m := range matches {
+				if m == abs {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// expandPath expands a leading ~ and makes path absolute
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Abs(path)
+}
+
+// hostTarget formats a host as user@hostname:port for display
+func hostTarget(h types.SSHHost) string {
+	target := h.HostName
+	if target == "" {
+		target = h.Name
+	}
+	if h.User != "" {
+		target = h.User + "@" + target
+	}
+	if h.Port != "" && h.Port != types.DefaultSSHPort {
+		target += ":" + h.Port
+	}
+	return target
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/settings"
+)
+
+// setupHome points the home and config directories at a temporary directory
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return home
+}
+
+// writeFile writes content to path, creating parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const termiusExport = `{"hosts": [
+	{"label": "API", "address": "api.example.com", "username": "svc", "port": 2200, "tags": ["backend"]},
+	{"label": "bad", "address": "bad host"}
+]}`
+
+func TestImport_Source(t *testing.T) {
+	home := setupHome(t)
+	export := filepath.Join(home, "termius.json")
+	writeFile(t, export, termiusExport)
+
+	var stdout, stderr bytes.Buffer
+	if err := Import([]string{export}, &stdout, &stderr); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Skipping bad") {
+		t.Errorf("expected the invalid host to be reported, got %q", stderr.String())
+	}
+
+	dir, err := settings.ImportDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := parser.NewImportedSource(dir).Discover(context.Background())
+	if err != nil {
+		t.Fatalf("imported source failed: %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "api" || hosts[0].Port != "2200" || hosts[0].Source != parser.SourceImported {
+		t.Fatalf("unexpected imported hosts: %+v", hosts)
+	}
+	if hosts[0].SourceFile != filepath.Join(dir, "termius.jsonl") {
+		t.Errorf("unexpected source file %q", hosts[0].SourceFile)
+	}
+}
+
+func TestImport_WriteFragment(t *testing.T) {
+	home := setupHome(t)
+	export := filepath.Join(home, "termius.json")
+	writeFile(t, export, termiusExport)
+	fragment := filepath.Join(home, ".ssh", "config.d", "imported.conf")
+	writeFile(t, fragment, "Host api\n    HostName old.example.com\n")
+
+	var stdout, stderr bytes.Buffer
+	if err := Import([]string{export, "--write", fragment}, &stdout, &stderr); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "api: already defined") || !strings.Contains(stdout.String(), "Nothing to write") {
+		t.Fatalf("expected the existing host to be skipped, got %q / %q", stdout.String(), stderr.String())
+	}
+
+	writeFile(t, fragment, "")
+	writeFile(t, filepath.Join(home, ".ssh", "config"), "Include config.d/*.conf\n")
+	stdout.Reset()
+	if err := Import([]string{"--write", fragment, export}, &stdout, &stderr); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	content, err := os.ReadFile(fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# @desc API\n# @tags backend\nHost api\n    HostName api.example.com\n    User svc\n    Port 2200\n"
+	if string(content) != want {
+		t.Errorf("fragment =\n%s\nwant\n%s", content, want)
+	}
+	if strings.Contains(stdout.String(), "Include") {
+		t.Errorf("did not expect an Include hint when the fragment is included, got %q", stdout.String())
+	}
+}
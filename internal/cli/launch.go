@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/ssh"
+)
+
+// Launch describes how to start the host selector from ssh-tui's own command line
+type Launch struct {
+	// Query prefills the selector's search
+	Query string
+	// SSHOptions are ssh options given without a destination; they are carried into the options screen
+	SSHOptions []string
+}
+
+// ParseLaunch decides whether args (without the program name or a subcommand) should open the
+// host selector. It returns false when args name a destination (or are -V/-Q), in which case they
+// are meant for ssh as they are. --query QUERY (or --query=QUERY) may appear among the options.
+func ParseLaunch(args []string) (Launch, bool, error) {
+	var launch Launch
+	var query bool
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--query":
+			if i+1 == len(args) {
+				return Launch{}, false, fmt.Errorf("--query needs a value")
+			}
+			i++
+			launch.Query, query = args[i], true
+			continue
+		case strings.HasPrefix(arg, "--query="):
+			launch.Query, query = strings.TrimPrefix(arg, "--query="), true
+			continue
+		}
+
+		rest = append(rest, arg)
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			// The destination, remote command and anything after "--" belong to ssh
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if ssh.OptionTakesNextArg(arg) && i+1 < len(args) {
+			i++
+			rest = append(rest, args[i])
+		}
+	}
+
+	sshArgs := ssh.ParseArgs(rest)
+	if !sshArgs.NeedsDestination() {
+		if query {
+			return Launch{}, false, fmt.Errorf("--query opens the host selector and cannot be combined with a destination")
+		}
+		return Launch{}, false, nil
+	}
+	launch.SSHOptions = sshArgs.Options
+	return launch, true, nil
+}
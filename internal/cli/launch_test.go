@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLaunch(t *testing.T) {
+	tests := []struct {
+		args        []string
+		interactive bool
+		launch      Launch
+	}{
+		{[]string{"user@host", "-p", "2222"}, false, Launch{}},
+		{[]string{"-L", "8080:localhost:80"}, true, Launch{SSHOptions: []string{"-L", "8080:localhost:80"}}},
+		{[]string{"--query", "web"}, true, Launch{Query: "web"}},
+		{[]string{"-A", "--query=tag:prod", "-p", "2222"}, true, Launch{Query: "tag:prod", SSHOptions: []string{"-A", "-p", "2222"}}},
+		// A value that looks like --query belongs to the option before it
+		{[]string{"-o", "--query"}, true, Launch{SSHOptions: []string{"-o", "--query"}}},
+		// After the destination, --query is part of the remote command
+		{[]string{"db", "mysql", "--query", "x"}, false, Launch{}},
+		{[]string{"-V"}, false, Launch{}},
+		// Values with spaces stay single arguments
+		{[]string{"-o", "ProxyCommand ssh -W %h:%p bastion", "-i", "/path/with space/key"}, true,
+			Launch{SSHOptions: []string{"-o", "ProxyCommand ssh -W %h:%p bastion", "-i", "/path/with space/key"}}},
+	}
+
+	for _, tt := range tests {
+		launch, interactive, err := ParseLaunch(tt.args)
+		if err != nil {
+			t.Fatalf("ParseLaunch(%q): %v", tt.args, err)
+		}
+		if interactive != tt.interactive || !reflect.DeepEqual(launch, tt.launch) {
+			t.Errorf("ParseLaunch(%q) = %+v, %v; want %+v, %v", tt.args, launch, interactive, tt.launch, tt.interactive)
+		}
+	}
+}
+
+func TestParseLaunch_Errors(t *testing.T) {
+	for _, args := range [][]string{{"--query"}, {"--query", "web", "host"}} {
+		if _, _, err := ParseLaunch(args); err == nil {
+			t.Errorf("ParseLaunch(%q): expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+)
+
+// List implements "ssh-tui list": it discovers hosts, applies the same search as the host
+// selector and prints the matches as a table or, with --json, as JSON
+func List(args []string, sources []parser.HostSource, stdout, stderr io.Writer) error {
+	fs := newFlagSet("list", "list [--filter QUERY] [--json]", stderr)
+	filter := fs.String("filter", "", "only list hosts matching QUERY, as typed in the host selector (e.g. \"tag:prod web\")")
+	asJSON := fs.Bool("json", false, "print the hosts as a JSON array")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", rest[0])
+	}
+
+	hosts := parser.FilterHosts(discover(sources, stderr), *filter)
+
+	if *asJSON {
+		return writeJSON(stdout, hosts)
+	}
+	return writeTable(stdout, hosts)
+}
+
+// writeTable prints one host per line in aligned columns
+func writeTable(w io.Writer, hosts []types.SSHHost) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTARGET\tSOURCE\tTAGS")
+	for _, h := range hosts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", h.Name, hostTarget(h), h.Source, strings.Join(h.Tags, ","))
+	}
+	return tw.Flush()
+}
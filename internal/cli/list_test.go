@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestList_Table(t *testing.T) {
+	setupHome(t)
+	var stdout, stderr bytes.Buffer
+	if err := List([]string{"--filter", "tag:prod"}, testSources(), &stdout, &stderr); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and one host, got:\n%s", stdout.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 4 || fields[0] != "web" || fields[1] != "web.example.com" || fields[3] != "prod" {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
+
+func TestList_JSON(t *testing.T) {
+	setupHome(t)
+	var stdout, stderr bytes.Buffer
+	if err := List([]string{"--json", "--filter=git"}, testSources(), &stdout, &stderr); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	var records []HostRecord
+	if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(records) != 1 || records[0].Name != "git.example.com" || records[0].Port != "2222" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"pick", "list", "export", "import", "help"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("expected %q to be a command", name)
+		}
+	}
+	if _, ok := Lookup("user@host"); ok {
+		t.Errorf("ssh arguments must not be taken for commands")
+	}
+}
+
+func TestParseFlags_Interspersed(t *testing.T) {
+	fs := newFlagSet("test", "test", &bytes.Buffer{})
+	json := fs.Bool("json", false, "")
+	rest, err := parseFlags(fs, []string{"a", "--json", "b", "--", "--c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !*json || strings.Join(rest, " ") != "a b --c" {
+		t.Fatalf("got json=%v rest=%v", *json, rest)
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/ssh"
+	"ssh-tui/internal/tui/hostselector"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Pick output formats
+const (
+	PrintName = "name"
+	PrintArgv = "argv"
+	PrintJSON = "json"
+)
+
+// ErrCancelled is returned when the user leaves an interactive command without choosing
+var ErrCancelled = errors.New("cancelled")
+
+// Pick implements "ssh-tui pick": it shows the host selector on the terminal and prints the
+// chosen host on stdout instead of connecting, for use in shell functions like
+// host=$(ssh-tui pick)
+func Pick(args []string, sources []parser.HostSource, stdout, stderr io.Writer) error {
+	fs := newFlagSet("pick", "pick [--print name|argv|json]", stderr)
+	format := fs.String("print", PrintName, "what to print: name (the host name), argv (the ssh command line) or json")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", rest[0])
+	}
+	if *format != PrintName && *format != PrintArgv && *format != PrintJSON {
+		return fmt.Errorf("unknown output %q (supported: name, argv, json)", *format)
+	}
+
+	host, err := pickHost(sources)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case PrintArgv:
+		_, err = fmt.Fprintln(stdout, ssh.JoinArgs(strings.Fields(ssh.BuildSSHCommand(host, ""))))
+	case PrintJSON:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(NewHostRecord(*host))
+	default:
+		_, err = fmt.Fprintln(stdout, host.Name)
+	}
+	return err
+}
+
+// pickHost runs the selector in pick mode on the terminal and returns the chosen host
+func pickHost(sources []parser.HostSource) (*types.SSHHost, error) {
+	in, out, err := OpenTTY()
+	if err != nil {
+		return nil, fmt.Errorf("pick needs a terminal: %w", err)
+	}
+	defer in.Close()
+	defer out.Close()
+	useTTYColors(out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	model := hostselector.NewStreamingHostSelectorModel(parser.SourceNames(sources), StreamHosts(ctx, sources))
+	model.SetPickMode(true)
+
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithInput(in), tea.WithOutput(out))
+	finalModel, err := program.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run host selector: %w", err)
+	}
+
+	hostModel, ok := finalModel.(*hostselector.HostSelectorModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type from host selector")
+	}
+	host := hostModel.GetSelectedHost()
+	if host == nil || !hostModel.IsSelected() {
+		return nil, ErrCancelled
+	}
+	return host, nil
+}
+
+// StreamHosts starts host discovery and forwards each batch annotated with connection history
+func StreamHosts(ctx context.Context, sources []parser.HostSource) <-chan parser.Batch {
+	h := loadHistory()
+	batches := parser.StreamHosts(ctx, sources)
+
+	out := make(chan parser.Batch, len(sources))
+	go func() {
+		defer close(out)
+		for batch := range batches {
+			if h != nil {
+				h.Annotate(batch.Hosts)
+			}
+			out <- batch
+		}
+	}()
+	return out
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// OpenTTY opens the controlling terminal for reading and writing, so an interactive screen
+// works even when standard input or output are redirected. The caller closes both files.
+func OpenTTY() (in, out *os.File, err error) {
+	if runtime.GOOS == "windows" {
+		if in, err = os.OpenFile("CONIN$", os.O_RDWR, 0); err != nil {
+			return nil, nil, err
+		}
+		if out, err = os.OpenFile("CONOUT$", os.O_RDWR, 0); err != nil {
+			in.Close()
+			return nil, nil, err
+		}
+		return in, out, nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Separate handles so callers can close both
+	out, err = os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		tty.Close()
+		return nil, nil, err
+	}
+	return tty, out, nil
+}
+
+// useTTYColors makes lipgloss detect colors from the terminal rather than from standard
+// output, which may be a pipe. It must be called before anything is rendered.
+func useTTYColors(out *os.File) {
+	lipgloss.DefaultRenderer().SetOutput(termenv.NewOutput(out))
+}
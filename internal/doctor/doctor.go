@@ -0,0 +1,214 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/parser"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// StatusOK means nothing was found to fix
+	StatusOK Status = "ok"
+	// StatusWarn means something probably does not work as intended
+	StatusWarn Status = "warn"
+	// StatusFail means something is broken, e.g. ssh refuses a file
+	StatusFail Status = "fail"
+	// StatusSkip means the check does not apply, e.g. on this platform
+	StatusSkip Status = "skip"
+)
+
+// commandTimeout bounds the external commands run by the checks (ssh -V, ssh-add -l)
+const commandTimeout = 5 * time.Second
+
+// Finding is a single problem reported by a check, located at a file and line when known
+type Finding struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Severity is set for config diagnostics ("error" or "warning")
+	Severity parser.Severity `json:"severity,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// Check is the result of one diagnosis
+type Check struct {
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Summary  string    `json:"summary"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Report holds the checks in the order they were run
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Failed returns the number of checks that failed
+func (r Report) Failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+// Options locates what the checks inspect
+type Options struct {
+	// Home is the user's home directory, used to expand ~ in IdentityFile
+	Home string
+	// SSHDir is the user's SSH directory (~/.ssh)
+	SSHDir string
+	// ConfigPath is the user's SSH config file
+	ConfigPath string
+	// AgentSocket is the agent socket from SSH_AUTH_SOCK ("" if unset)
+	AgentSocket string
+}
+
+// DefaultOptions returns the options for the current user
+func DefaultOptions() (Options, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Options{}, err
+	}
+	configPath, err := parser.UserConfigPath()
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		Home:        home,
+		SSHDir:      filepath.Join(home, ".ssh"),
+		ConfigPath:  configPath,
+		AgentSocket: os.Getenv("SSH_AUTH_SOCK"),
+	}, nil
+}
+
+// Run runs every check
+func Run(ctx context.Context, opts Options) Report {
+	tree, includes, err := parser.LoadConfigTree(opts.ConfigPath)
+	identities := identityFiles(tree, opts.Home)
+
+	// Duplicate host names get a check of their own
+	var lint, duplicates []parser.Diagnostic
+	for _, d := range parser.LintConfig(tree) {
+		if d.Rule == parser.RuleDuplicateHost {
+			duplicates = append(duplicates, d)
+		} else {
+			lint = append(lint, d)
+		}
+	}
+
+	return Report{Checks: []Check{
+		checkSSH(ctx),
+		checkConfig(opts.ConfigPath, tree, err, lint),
+		diagnosticsCheck("Include directives", "all patterns match readable files", "include", "skipped", includes),
+		checkPermissions(opts.SSHDir, tree, identities),
+		checkAgent(ctx, opts.AgentSocket),
+		diagnosticsCheck("Duplicate hosts", "every host name has a single Host block", "host", "listed more than once", duplicates),
+		checkIdentityFiles(identities),
+	}}
+}
+
+// checkConfig reports the problems the linter found in the config files
+func checkConfig(path string, tree []parser.LoadedConfig, err error, diagnostics []parser.Diagnostic) Check {
+	switch {
+	case err != nil:
+		return Check{Name: "SSH config", Status: StatusFail, Summary: fmt.Sprintf("cannot read %s: %v", path, err)}
+	case !exists(path):
+		return Check{Name: "SSH config", Status: StatusOK, Summary: fmt.Sprintf("%s does not exist", path)}
+	}
+
+	blocks := 0
+	for _, f := range tree {
+		blocks += len(f.File.HostBlocks())
+	}
+	return diagnosticsCheck("SSH config", plural(len(tree), "file")+", "+plural(blocks, "Host block"), "problem", "found", diagnostics)
+}
+
+// diagnosticsCheck turns config diagnostics into a check named name. Without diagnostics it
+// passes with the summary ok; otherwise the summary counts them as nouns, e.g. "2 problems found".
+// Errors fail the check, warnings only flag it.
+func diagnosticsCheck(name, ok, noun, verb string, diagnostics []parser.Diagnostic) Check {
+	c := Check{Name: name, Status: StatusOK, Summary: ok}
+	for _, d := range diagnostics {
+		c.Findings = append(c.Findings, Finding{File: d.File, Line: d.Line, Severity: d.Severity, Message: d.Message})
+		if d.Severity == parser.SeverityError {
+			c.Status = StatusFail
+		} else if c.Status == StatusOK {
+			c.Status = StatusWarn
+		}
+	}
+	if len(diagnostics) > 0 {
+		c.Summary = plural(len(diagnostics), noun) + " " + verb
+	}
+	return c
+}
+
+// identityFile is an IdentityFile directive resolved to a path
+type identityFile struct {
+	path string
+	file string
+	line int
+}
+
+// identityFiles collects the IdentityFile directives whose path can be resolved without
+// knowing the connection: ~ and %d are expanded, paths with other tokens are left out
+func identityFiles(tree []parser.LoadedConfig, home string) []identityFile {
+	var files []identityFile
+	for _, f := range tree {
+		for _, line := range f.File.Lines() {
+			if line.Kind != parser.LineDirective || !strings.EqualFold(line.Key, "identityfile") {
+				continue
+			}
+			path := strings.Trim(line.Value, "\"")
+			if strings.EqualFold(path, "none") {
+				continue
+			}
+			if path == "~" || strings.HasPrefix(path, "~/") {
+				path = home + path[1:]
+			}
+			path = strings.ReplaceAll(path, "%d", home)
+			if strings.Contains(path, "%") || strings.Contains(path, "${") || !filepath.IsAbs(path) {
+				continue
+			}
+			files = append(files, identityFile{path: filepath.Clean(path), file: f.Path, line: line.Num})
+		}
+	}
+	return files
+}
+
+// checkIdentityFiles reports IdentityFile directives naming files that do not exist
+func checkIdentityFiles(identities []identityFile) Check {
+	c := Check{Name: "Identity files", Status: StatusOK, Summary: plural(len(identities), "IdentityFile") + " found"}
+	for _, id := range identities {
+		if !exists(id.path) {
+			c.Findings = append(c.Findings, Finding{File: id.file, Line: id.line, Message: fmt.Sprintf("%s does not exist", id.path)})
+		}
+	}
+	if len(c.Findings) > 0 {
+		c.Status, c.Summary = StatusWarn, plural(len(c.Findings), "IdentityFile")+" not found (ssh skips missing files)"
+	}
+	return c
+}
+
+// exists reports whether path can be stat'ed
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// plural formats a count with a noun, adding "s" unless the count is one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,132 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setup creates a home directory with the given files under .ssh and returns options for it
+func setup(t *testing.T, files map[string]string) Options {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Options{Home: home, SSHDir: sshDir, ConfigPath: filepath.Join(sshDir, "config")}
+}
+
+// findCheck returns the check with the given name from the report
+func findCheck(t *testing.T, report Report, name string) Check {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, report)
+	return Check{}
+}
+
+func TestRun_Config(t *testing.T) {
+	opts := setup(t, map[string]string{
+		"config": "Include conf.d/*\n" +
+			"Host web w\n  HostName web.example.com\n  IdentityFile ~/.ssh/id_web\n  IdentityFile %d/.ssh/gone\n  IdentityFile ~/.ssh/%h\n" +
+			"Host db W\n  Port\n  Usr admin\n",
+		"id_web": "key",
+	})
+	report := Run(context.Background(), opts)
+
+	config := findCheck(t, report, "SSH config")
+	if config.Status != StatusFail || len(config.Findings) != 2 || config.Findings[0].Line != 8 || config.Findings[1].Severity != "error" {
+		t.Errorf("unexpected config check: %+v", config)
+	}
+	includes := findCheck(t, report, "Include directives")
+	if includes.Status != StatusWarn || len(includes.Findings) != 1 || includes.Findings[0].Line != 1 {
+		t.Errorf("unexpected include check: %+v", includes)
+	}
+	duplicates := findCheck(t, report, "Duplicate hosts")
+	if duplicates.Status != StatusWarn || len(duplicates.Findings) != 1 || !strings.Contains(duplicates.Findings[0].Message, `"W"`) {
+		t.Errorf("unexpected duplicates check: %+v", duplicates)
+	}
+	identities := findCheck(t, report, "Identity files")
+	if identities.Status != StatusWarn || len(identities.Findings) != 1 || !strings.HasSuffix(identities.Findings[0].Message, filepath.Join(".ssh", "gone")+" does not exist") {
+		t.Errorf("unexpected identity check: %+v", identities)
+	}
+}
+
+func TestRun_Clean(t *testing.T) {
+	opts := setup(t, map[string]string{"config": "Host web\n  HostName web.example.com\n"})
+	report := Run(context.Background(), opts)
+	for _, name := range []string{"SSH config", "Include directives", "Duplicate hosts", "Identity files"} {
+		if c := findCheck(t, report, name); c.Status != StatusOK {
+			t.Errorf("expected %s to pass, got %+v", name, c)
+		}
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not used on Windows")
+	}
+	opts := setup(t, map[string]string{"config": "Host web\n", "id_ed25519": "key", "id_ed25519.pub": "pub", "deploy": "key", "deploy.pub": "pub"})
+	if c := checkPermissions(opts.SSHDir, nil, nil); c.Status != StatusOK {
+		t.Fatalf("expected private files to pass, got %+v", c)
+	}
+
+	for _, name := range []string{"config", "deploy"} {
+		if err := os.Chmod(filepath.Join(opts.SSHDir, name), 0o664); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := Run(context.Background(), opts)
+	c := findCheck(t, report, "Permissions")
+	if c.Status != StatusFail || len(c.Findings) != 2 {
+		t.Fatalf("expected the config and the key to fail, got %+v", c)
+	}
+	if report.Failed() == 0 {
+		t.Errorf("expected the report to count the failure")
+	}
+}
+
+func TestCheckAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the agent is found without SSH_AUTH_SOCK on Windows")
+	}
+	if c := checkAgent(context.Background(), ""); c.Status != StatusWarn {
+		t.Errorf("expected a warning without SSH_AUTH_SOCK, got %+v", c)
+	}
+	if c := checkAgent(context.Background(), filepath.Join(t.TempDir(), "agent.sock")); c.Status != StatusFail {
+		t.Errorf("expected a failure for a missing socket, got %+v", c)
+	}
+}
+
+func TestCheckSSH(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ssh")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'OpenSSH_9.9p1, OpenSSL 3.0' >&2\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	if c := checkSSH(context.Background()); c.Status != StatusOK || !strings.HasPrefix(c.Summary, "OpenSSH_9.9p1, OpenSSL 3.0 (") {
+		t.Errorf("unexpected ssh check: %+v", c)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if c := checkSSH(context.Background()); c.Status != StatusFail {
+		t.Errorf("expected a failure without ssh, got %+v", c)
+	}
+}
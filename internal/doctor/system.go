@@ -0,0 +1,151 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"ssh-tui/internal/parser"
+)
+
+// checkSSH looks for the ssh client and reports its version
+func checkSSH(ctx context.Context) Check {
+	c := Check{Name: "ssh client"}
+	path, err := exec.LookPath("ssh")
+	if err != nil {
+		c.Status, c.Summary = StatusFail, "ssh was not found in PATH; install OpenSSH"
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	// ssh -V prints its version on standard error
+	out, err := exec.CommandContext(ctx, path, "-V").CombinedOutput()
+	version, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if err != nil || version == "" {
+		c.Status, c.Summary = StatusWarn, fmt.Sprintf("%s does not report a version: %v", path, err)
+		return c
+	}
+	c.Status, c.Summary = StatusOK, fmt.Sprintf("%s (%s)", version, path)
+	return c
+}
+
+// checkAgent reports whether an ssh-agent is reachable and which keys it holds
+func checkAgent(ctx context.Context, socket string) Check {
+	c := Check{Name: "ssh-agent"}
+	// On Windows, OpenSSH's agent is a service reached without SSH_AUTH_SOCK
+	if runtime.GOOS != "windows" {
+		if socket == "" {
+			c.Status, c.Summary = StatusWarn, "SSH_AUTH_SOCK is not set; no agent is available to hold unlocked keys"
+			return c
+		}
+		if !exists(socket) {
+			c.Status, c.Summary = StatusFail, fmt.Sprintf("SSH_AUTH_SOCK points to %s, which does not exist", socket)
+			return c
+		}
+	}
+
+	path, err := exec.LookPath("ssh-add")
+	if err != nil {
+		c.Status, c.Summary = StatusSkip, "ssh-add was not found in PATH"
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, "-l")
+	if socket != "" {
+		cmd.Env = append(os.Environ(), "SSH_AUTH_SOCK="+socket)
+	}
+	out, err := cmd.Output()
+
+	// ssh-add -l exits with 1 when the agent has no keys and 2 when it cannot be reached
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		c.Status, c.Summary = StatusWarn, "the agent is running but holds no keys; add one with ssh-add"
+	case err != nil:
+		c.Status, c.Summary = StatusFail, fmt.Sprintf("cannot connect to the agent: %v", err)
+	default:
+		keys := strings.Split(strings.TrimSpace(string(out)), "\n")
+		for _, key := range keys {
+			c.Findings = append(c.Findings, Finding{Message: key})
+		}
+		c.Status, c.Summary = StatusOK, plural(len(keys), "key")+" loaded"
+	}
+	return c
+}
+
+// checkPermissions reports files that ssh refuses or that others could tamper with: config
+// files writable by others, private keys readable by others and a writable ~/.ssh
+func checkPermissions(sshDir string, tree []parser.LoadedConfig, identities []identityFile) Check {
+	c := Check{Name: "Permissions"}
+	if runtime.GOOS == "windows" {
+		c.Status, c.Summary = StatusSkip, "file modes are not used on Windows"
+		return c
+	}
+
+	status := StatusOK
+	report := func(s Status, path, format string, args ...any) {
+		c.Findings = append(c.Findings, Finding{File: path, Message: fmt.Sprintf(format, args...)})
+		if s == StatusFail || status == StatusOK {
+			status = s
+		}
+	}
+
+	if info, err := os.Stat(sshDir); err == nil && info.Mode().Perm()&0o022 != 0 {
+		report(StatusWarn, sshDir, "writable by group or others (%04o); run chmod 700 %s", info.Mode().Perm(), sshDir)
+	}
+	if info, err := os.Stat(filepath.Join(sshDir, "authorized_keys")); err == nil && info.Mode().Perm()&0o022 != 0 {
+		path := filepath.Join(sshDir, "authorized_keys")
+		report(StatusWarn, path, "writable by group or others (%04o); sshd may ignore it; run chmod 600 %s", info.Mode().Perm(), path)
+	}
+
+	for _, f := range tree {
+		if info, err := os.Stat(f.Path); err == nil && info.Mode().Perm()&0o022 != 0 {
+			report(StatusFail, f.Path, "writable by group or others (%04o); ssh refuses to read it; run chmod 600 %s", info.Mode().Perm(), f.Path)
+		}
+	}
+
+	checked := make(map[string]bool)
+	for _, key := range privateKeys(sshDir, identities) {
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+		if info, err := os.Stat(key); err == nil && info.Mode().Perm()&0o077 != 0 {
+			report(StatusFail, key, "private key accessible by group or others (%04o); ssh refuses to use it; run chmod 600 %s", info.Mode().Perm(), key)
+		}
+	}
+
+	c.Status, c.Summary = status, "~/.ssh, config files and private keys are protected"
+	if len(c.Findings) > 0 {
+		c.Summary = plural(len(c.Findings), "file") + " with unsafe permissions"
+	}
+	return c
+}
+
+// privateKeys lists the private keys to check: files in sshDir with a matching .pub file or
+// named like ssh-keygen's defaults (id_*), and the files named by IdentityFile
+func privateKeys(sshDir string, identities []identityFile) []string {
+	var keys []string
+	entries, _ := os.ReadDir(sshDir)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, ".pub") {
+			continue
+		}
+		if strings.HasPrefix(name, "id_") || exists(filepath.Join(sshDir, name+".pub")) {
+			keys = append(keys, filepath.Join(sshDir, name))
+		}
+	}
+	for _, id := range identities {
+		keys = append(keys, id.path)
+	}
+	return keys
+}
@@ -0,0 +1,87 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+// Entry records connection statistics for a single host
+type Entry struct {
+	LastConnected time.Time `json:"last_connected"`
+	Count         int       `json:"count"`
+}
+
+// History holds per-host connection records persisted as JSON
+type History struct {
+	path    string
+	Entries map[string]Entry `json:"entries"`
+}
+
+// DefaultPath returns the location of the history file in the user's config directory
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ssh-tui", "history.json"), nil
+}
+
+// Load reads the history file at path; a missing file yields an empty history
+func Load(path string) (*History, error) {
+	h := &History{path: path, Entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		return h, err
+	}
+	if h.Entries == nil {
+		h.Entries = make(map[string]Entry)
+	}
+	return h, nil
+}
+
+// Record notes a connection to the named host at time t
+func (h *History) Record(name string, t time.Time) {
+	key := strings.ToLower(name)
+	entry := h.Entries[key]
+	entry.LastConnected = t
+	entry.Count++
+	h.Entries[key] = entry
+}
+
+// LastConnected returns the last connection time for the named host (zero if never)
+func (h *History) LastConnected(name string) time.Time {
+	return h.Entries[strings.ToLower(name)].LastConnected
+}
+
+// Annotate fills in LastConnected on each host from the history
+func (h *History) Annotate(hosts []types.SSHHost) {
+	for i := range hosts {
+		hosts[i].LastConnected = h.LastConnected(hosts[i].Name)
+	}
+}
+
+// Save writes the history back to its file, creating the parent directory if needed
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0o600)
+}
@@ -0,0 +1,43 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+func TestHistory_RecordSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.json")
+
+	h, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load on missing file returned error: %v", err)
+	}
+	if !h.LastConnected("web").IsZero() {
+		t.Fatalf("expected zero time for unknown host")
+	}
+
+	when := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	h.Record("Web", when)
+	h.Record("web", when.Add(time.Hour))
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	entry := loaded.Entries["web"]
+	if entry.Count != 2 || !entry.LastConnected.Equal(when.Add(time.Hour)) {
+		t.Fatalf("unexpected entry after reload: %+v", entry)
+	}
+
+	hosts := []types.SSHHost{{Name: "WEB"}, {Name: "db"}}
+	loaded.Annotate(hosts)
+	if !hosts[0].LastConnected.Equal(when.Add(time.Hour)) || !hosts[1].LastConnected.IsZero() {
+		t.Fatalf("unexpected annotation: %+v", hosts)
+	}
+}
@@ -0,0 +1,211 @@
+package hostkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultTimeout bounds fetching a host key, from dialing to the end of the key exchange
+const DefaultTimeout = 5 * time.Second
+
+// KnownState says how a host key relates to the keys recorded in known_hosts
+type KnownState int
+
+const (
+	// KnownNew means known_hosts has no key for the host; ssh will ask to add it
+	KnownNew KnownState = iota
+	// KnownMatch means the key is recorded for the host
+	KnownMatch
+	// KnownChanged means known_hosts records different keys for the host
+	KnownChanged
+)
+
+// errKeyReceived aborts the handshake once the host key has been seen
+var errKeyReceived = errors.New("host key received")
+
+// Fetch connects to address and performs the SSH key exchange to learn the server's host key.
+// The connection is closed before authenticating. algorithms restricts the host key algorithms
+// offered (nil offers the defaults). It also returns the server's address as dialed, which
+// known_hosts checks need.
+func Fetch(ctx context.Context, address string, algorithms []string) (ssh.PublicKey, net.Addr, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var key ssh.PublicKey
+	var remote net.Addr
+	config := &ssh.ClientConfig{
+		HostKeyCallback: func(_ string, r net.Addr, k ssh.PublicKey) error {
+			key, remote = k, r
+			return errKeyReceived
+		},
+		HostKeyAlgorithms: algorithms,
+	}
+	_, _, _, err = ssh.NewClientConn(conn, address, config)
+	if key == nil {
+		return nil, nil, fmt.Errorf("key exchange with %s failed: %w", address, err)
+	}
+	return key, remote, nil
+}
+
+// algorithmsFor returns the host key algorithms that make a server present keys of the given
+// types; RSA keys are signed with SHA-2 by current servers
+func algorithmsFor(keys []knownhosts.KnownKey) []string {
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		names := []string{k.Key.Type()}
+		if k.Key.Type() == ssh.KeyAlgoRSA {
+			names = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				algorithms = append(algorithms, name)
+			}
+		}
+	}
+	return algorithms
+}
+
+// Report is what is known about a host's key before connecting to it
+type Report struct {
+	// Address is the host:port the key was fetched from
+	Address string
+	// Key is the server's host key; nil if it could not be fetched
+	Key ssh.PublicKey
+	// Err is set when the key could not be fetched or compared with known_hosts
+	Err error
+	// KnownHostsName is the name known_hosts records the host under (see Normalize)
+	KnownHostsName string
+	// Known compares the key with known_hosts; Recorded holds the keys recorded for the host
+	// when they differ (KnownChanged)
+	Known    KnownState
+	Recorded []knownhosts.KnownKey
+	// TrustedChecked is set when published fingerprints are configured; Trusted compares the key
+	// with them and Entry is the matching one
+	TrustedChecked bool
+	Trusted        Verdict
+	Entry          *Entry
+	// TrustedErr is set when the published fingerprints could not be read
+	TrustedErr error
+}
+
+// Checker fetches host keys and compares them with known_hosts and published fingerprints
+type Checker struct {
+	// KnownHostsFiles are the known_hosts files to compare with; missing files are skipped
+	KnownHostsFiles []string
+	// TrustedFiles list the fingerprints published by the team (see ParseTrusted)
+	TrustedFiles []string
+	Timeout      time.Duration
+	// Fetch retrieves the host key; tests replace it
+	Fetch func(ctx context.Context, address string, algorithms []string) (ssh.PublicKey, net.Addr, error)
+}
+
+// NewChecker returns a checker comparing with the given files
+func NewChecker(knownHostsFiles, trustedFiles []string) *Checker {
+	return &Checker{KnownHostsFiles: knownHostsFiles, TrustedFiles: trustedFiles, Timeout: DefaultTimeout, Fetch: Fetch}
+}
+
+// Check fetches host's key and compares it with known_hosts and the published fingerprints.
+// Like ssh, it looks the host up under its HostKeyAlias when one is set, and when known_hosts
+// only records keys of other types, it asks the server for a key of a recorded type.
+func (c *Checker) Check(ctx context.Context, host types.SSHHost) Report {
+	r := Report{Address: Address(host), KnownHostsName: Normalize(Address(host))}
+	lookup := r.Address
+	for _, d := range host.Directives {
+		if strings.EqualFold(d.Key, "hostkeyalias") && d.Value != "" {
+			lookup = net.JoinHostPort(d.Value, types.DefaultSSHPort)
+			r.KnownHostsName = Normalize(lookup)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	key, remote, err := c.Fetch(ctx, r.Address, nil)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.Known, r.Recorded, err = c.known(lookup, remote, key)
+	if err == nil && r.Known == KnownChanged && !hasType(r.Recorded, key.Type()) {
+		if k, rm, fetchErr := c.Fetch(ctx, r.Address, algorithmsFor(r.Recorded)); fetchErr == nil {
+			key, remote = k, rm
+			r.Known, r.Recorded, err = c.known(lookup, remote, key)
+		}
+	}
+	r.Key = key
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	if len(c.TrustedFiles) == 0 {
+		return r
+	}
+	trusted, err := LoadTrusted(c.TrustedFiles)
+	if err != nil {
+		r.TrustedErr = err
+		return r
+	}
+	r.TrustedChecked = true
+	r.Trusted, r.Entry = trusted.Lookup(r.Address, key)
+	return r
+}
+
+// known compares key with the keys recorded for address in the known_hosts files, returning the
+// recorded keys when they differ
+func (c *Checker) known(address string, remote net.Addr, key ssh.PublicKey) (KnownState, []knownhosts.KnownKey, error) {
+	var files []string
+	for _, f := range c.KnownHostsFiles {
+		if _, err := os.Stat(f); err == nil {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return KnownNew, nil, nil
+	}
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return KnownNew, nil, err
+	}
+
+	var keyErr *knownhosts.KeyError
+	err = callback(address, remote, key)
+	switch {
+	case err == nil:
+		return KnownMatch, nil, nil
+	case errors.As(err, &keyErr) && len(keyErr.Want) == 0:
+		return KnownNew, nil, nil
+	case errors.As(err, &keyErr):
+		return KnownChanged, keyErr.Want, nil
+	default:
+		return KnownNew, nil, err
+	}
+}
+
+// hasType reports whether one of keys is of the given type
+func hasType(keys []knownhosts.KnownKey, keyType string) bool {
+	for _, k := range keys {
+		if k.Key.Type() == keyType {
+			return true
+		}
+	}
+	return false
+}
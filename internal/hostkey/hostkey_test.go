@@ -0,0 +1,449 @@
+package hostkey
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/history"
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// newSigner generates an ed25519 host key
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// newRSASigner generates an RSA host key
+func newRSASigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// serve runs an SSH server presenting the signers' keys on a local port and returns its address
+func serve(t *testing.T, signers ...ssh.Signer) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	for _, signer := range signers {
+		config.AddHostKey(signer)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				ssh.NewServerConn(conn, config)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// hostFor returns a custom host for a server address
+func hostFor(address string) types.SSHHost {
+	host, port, _ := net.SplitHostPort(address)
+	return types.SSHHost{Name: host, HostName: host, Port: port, Source: types.SourceCustom}
+}
+
+// writeFile writes content to a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFetch(t *testing.T) {
+	signer := newSigner(t)
+	address := serve(t, signer)
+
+	key, remote, err := Fetch(context.Background(), address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssh.FingerprintSHA256(key) != ssh.FingerprintSHA256(signer.PublicKey()) {
+		t.Errorf("expected the server's key, got %s", ssh.FingerprintSHA256(key))
+	}
+	if remote == nil || remote.String() != address {
+		t.Errorf("expected the remote address %s, got %v", address, remote)
+	}
+
+	// A port that does not speak SSH fails the key exchange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+	if _, _, err := Fetch(context.Background(), listener.Addr().String(), nil); err == nil {
+		t.Errorf("expected an error from a server that does not speak SSH")
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	signer := newSigner(t)
+	address := serve(t, signer)
+	host := hostFor(address)
+	fingerprint := ssh.FingerprintSHA256(signer.PublicKey())
+	normalized := Normalize(address)
+
+	// Not in known_hosts, no published fingerprints
+	r := NewChecker([]string{filepath.Join(t.TempDir(), "missing")}, nil).Check(context.Background(), host)
+	if r.Err != nil || r.Key == nil || r.Known != KnownNew || r.TrustedChecked {
+		t.Fatalf("unexpected report for a new host: %+v", r)
+	}
+
+	// Recorded in known_hosts under a hashed name, and published by the team
+	hashed := knownhosts.Line([]string{knownhosts.HashHostname(normalized)}, signer.PublicKey())
+	knownHosts := writeFile(t, "known_hosts", hashed+"\n")
+	trusted := writeFile(t, "fingerprints", "# team keys\n"+normalized+" "+fingerprint+"\n")
+	r = NewChecker([]string{knownHosts}, []string{trusted}).Check(context.Background(), host)
+	if r.Known != KnownMatch || !r.TrustedChecked || r.Trusted != VerdictMatch || r.Entry == nil || r.Entry.Line != 2 {
+		t.Fatalf("expected a known and trusted key, got %+v", r)
+	}
+
+	// known_hosts and the team list record a different key
+	other := newSigner(t).PublicKey()
+	knownHosts = writeFile(t, "known_hosts", knownhosts.Line([]string{normalized}, other)+"\n")
+	trusted = writeFile(t, "fingerprints", "*  "+ssh.FingerprintSHA256(other)+"\n")
+	r = NewChecker([]string{knownHosts}, []string{trusted}).Check(context.Background(), host)
+	if r.Known != KnownChanged || r.Trusted != VerdictMismatch {
+		t.Fatalf("expected a changed and mismatching key, got %+v", r)
+	}
+	if len(r.Recorded) != 1 || r.Recorded[0].Line != 1 || ssh.FingerprintSHA256(r.Recorded[0].Key) != ssh.FingerprintSHA256(other) {
+		t.Fatalf("expected the recorded key, got %+v", r.Recorded)
+	}
+
+	// An unreadable fingerprint list is reported without hiding the key
+	r = NewChecker(nil, []string{filepath.Join(t.TempDir(), "missing")}).Check(context.Background(), host)
+	if r.Key == nil || r.TrustedErr == nil {
+		t.Fatalf("expected the key and a fingerprint list error, got %+v", r)
+	}
+}
+
+func TestParseTrusted(t *testing.T) {
+	signer := newSigner(t)
+	key := signer.PublicKey()
+	fingerprint := ssh.FingerprintSHA256(key)
+	content := strings.Join([]string{
+		"# published host keys",
+		"@cert-authority *.example.com " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		"web1.example.com,web2.example.com " + fingerprint,
+		"[db.example.com]:2222 ssh-rsa SHA256:rsaonly",
+		"*.example.com,!bad.example.com " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))) + " ops@example.com",
+		"",
+	}, "\n")
+
+	trusted, err := ParseTrusted(strings.NewReader(content), "fingerprints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trusted) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", trusted)
+	}
+	if trusted[2].Type != "ssh-ed25519" || trusted[2].Fingerprint != fingerprint || trusted[2].Line != 5 {
+		t.Errorf("unexpected known_hosts style entry: %+v", trusted[2])
+	}
+
+	tests := []struct {
+		address string
+		want    Verdict
+		line    int
+	}{
+		{"web2.example.com:22", VerdictMatch, 3},
+		{"app.example.com:22", VerdictMatch, 5},
+		// Only an RSA key is published for db on port 2222
+		{"db.example.com:2222", VerdictUnlisted, 0},
+		{"bad.example.com:22", VerdictUnlisted, 0},
+		{"other.org:22", VerdictUnlisted, 0},
+	}
+	for _, tt := range tests {
+		verdict, entry := trusted.Lookup(tt.address, key)
+		if verdict != tt.want || (entry != nil) != (tt.line > 0) || (entry != nil && entry.Line != tt.line) {
+			t.Errorf("Lookup(%s) = %v, %+v; want %v at line %d", tt.address, verdict, entry, tt.want, tt.line)
+		}
+	}
+
+	verdict, _ := trusted.Lookup("web1.example.com:22", newSigner(t).PublicKey())
+	if verdict != VerdictMismatch {
+		t.Errorf("expected a different key for web1 to mismatch, got %v", verdict)
+	}
+
+	if _, err := ParseTrusted(strings.NewReader("lonely.example.com\n"), "bad"); err == nil {
+		t.Errorf("expected an error for a line without a fingerprint")
+	}
+}
+
+func TestMatchHosts(t *testing.T) {
+	tests := []struct {
+		field, address string
+		want           bool
+	}{
+		{"web", "web", true},
+		{"Web.Example.com", "web.example.com", true},
+		{"web,db", "db", true},
+		{"*.example.com", "a.example.com", true},
+		{"*.example.com,!b.example.com", "b.example.com", false},
+		{"[web]:2222", "[web]:2222", true},
+		{"web", "[web]:2222", false},
+		{knownhosts.HashHostname("web"), "web", true},
+		{knownhosts.HashHostname("web"), "db", false},
+		{"|1|bad", "web", false},
+	}
+	for _, tt := range tests {
+		if got := MatchHosts(tt.field, tt.address); got != tt.want {
+			t.Errorf("MatchHosts(%q, %q) = %v, want %v", tt.field, tt.address, got, tt.want)
+		}
+	}
+	if got := Normalize("Web:22"); got != "web" {
+		t.Errorf("Normalize(Web:22) = %q", got)
+	}
+	if got := Normalize("web:2222"); got != "[web]:2222" {
+		t.Errorf("Normalize(web:2222) = %q", got)
+	}
+}
+
+func TestChecker_RecordedKeyType(t *testing.T) {
+	ed, rsaKey := newSigner(t), newRSASigner(t)
+	address := serve(t, ed, rsaKey)
+	host := hostFor(address)
+
+	// known_hosts only records the RSA key, so the server is asked for it instead of its
+	// preferred ed25519 key
+	knownHosts := writeFile(t, "known_hosts", knownhosts.Line([]string{Normalize(address)}, rsaKey.PublicKey())+"\n")
+	r := NewChecker([]string{knownHosts}, nil).Check(context.Background(), host)
+	if r.Err != nil || r.Known != KnownMatch || r.Key.Type() != ssh.KeyAlgoRSA {
+		t.Fatalf("expected the recorded RSA key to match, got %+v", r)
+	}
+}
+
+func TestChecker_HostKeyAlias(t *testing.T) {
+	signer := newSigner(t)
+	address := serve(t, signer)
+	host := hostFor(address)
+	host.Directives = []types.Directive{{Key: "HostKeyAlias", Value: "build-box"}}
+
+	knownHosts := writeFile(t, "known_hosts", knownhosts.Line([]string{"build-box"}, signer.PublicKey())+"\n")
+	r := NewChecker([]string{knownHosts}, nil).Check(context.Background(), host)
+	if r.Known != KnownMatch || r.KnownHostsName != "build-box" {
+		t.Fatalf("expected the key recorded under the alias to match, got %+v", r)
+	}
+}
+
+func TestKnownHostsFile_RemoveHost(t *testing.T) {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSigner(t).PublicKey())))
+	lines := []string{
+		"# managed by hand",
+		"web.example.com " + key,
+		"db.example.com,web.example.com,10.0.0.5 " + key + " shared key",
+		knownhosts.HashHostname("web.example.com") + " " + key,
+		"*.example.com,other.example.org " + key,
+		"!web.example.com,*.example.com " + key,
+		"@cert-authority web.example.com " + key,
+		"[web.example.com]:2222 " + key,
+		"other.org " + key,
+	}
+	path := writeFile(t, "known_hosts", strings.Join(lines, "\n")+"\n")
+
+	f, err := LoadKnownHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := f.Entries()
+	if len(entries) != 8 || entries[1].Comment != "shared key" || !entries[2].Hashed() || entries[5].Marker != "@cert-authority" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if entries[0].Line != 2 || entries[0].Type != "ssh-ed25519" || entries[0].Fingerprint() == "" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+
+	removed := f.RemoveHost("web.example.com")
+	var removedLines []int
+	for _, e := range removed {
+		removedLines = append(removedLines, e.Line)
+	}
+	// Lines matching web.example.com only through a wildcard apply to other hosts and are kept
+	if len(removedLines) != 3 || removedLines[0] != 2 || removedLines[1] != 3 || removedLines[2] != 4 {
+		t.Fatalf("expected lines 2-4 to be changed, got %v", removedLines)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"# managed by hand",
+		"db.example.com,10.0.0.5 " + key + " shared key",
+		"*.example.com,other.example.org " + key,
+		"!web.example.com,*.example.com " + key,
+		"@cert-authority web.example.com " + key,
+		"[web.example.com]:2222 " + key,
+		"other.org " + key,
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("unexpected file after removal:\n%s\nwant:\n%s", data, want)
+	}
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || !strings.Contains(string(backup), lines[1]) {
+		t.Errorf("expected the original file as a backup: %v", err)
+	}
+}
+
+func TestVerifiable(t *testing.T) {
+	tests := []struct {
+		options map[string]string
+		want    bool
+	}{
+		{nil, true},
+		{map[string]string{"proxyjump": "bastion"}, false},
+		{map[string]string{"proxycommand": "none"}, true},
+		{map[string]string{"userknownhostsfile": "/dev/null"}, false},
+		{map[string]string{"stricthostkeychecking": "no"}, false},
+		{map[string]string{"stricthostkeychecking": "yes"}, true},
+	}
+	for _, tt := range tests {
+		if got := Verifiable(tt.options); got != tt.want {
+			t.Errorf("Verifiable(%v) = %v, want %v", tt.options, got, tt.want)
+		}
+	}
+
+	// A ProxyJump inherited from a pattern block counts like the host's own
+	config := writeFile(t, "config", "Host app1.internal\n  User deploy\n\nHost *.internal\n  ProxyJump bastion\n")
+	host := types.SSHHost{Name: "app1.internal", Source: types.SourceConfig, Directives: []types.Directive{{Key: "User", Value: "deploy"}}}
+	if Verifiable(parser.LoadConfigOptions(config).For(host)) {
+		t.Errorf("expected a host behind an inherited ProxyJump not to be verifiable")
+	}
+}
+
+func TestKnownHostsFile_HashAll(t *testing.T) {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSigner(t).PublicKey())))
+	hashedWeb := knownhosts.HashHostname("web")
+	lines := []string{
+		"web,[db]:2222 " + key + " ops",
+		hashedWeb + " " + key,
+		"*.example.com " + key,
+		"@revoked old " + key,
+	}
+	path := writeFile(t, "known_hosts", strings.Join(lines, "\n")+"\n")
+	f, err := LoadKnownHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := f.HashAll(); n != 2 {
+		t.Fatalf("expected 2 names to be hashed, got %d", n)
+	}
+	entries := f.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("expected the shared line to be split, got %+v", entries)
+	}
+	if !entries[0].Hashed() || !MatchHosts(entries[0].Hosts, "web") || entries[0].Comment != "ops" || entries[0].Key != entries[2].Key {
+		t.Errorf("unexpected first hashed entry: %+v", entries[0])
+	}
+	if !entries[1].Hashed() || !MatchHosts(entries[1].Hosts, "[db]:2222") {
+		t.Errorf("unexpected second hashed entry: %+v", entries[1])
+	}
+	if entries[2].Hosts != hashedWeb || entries[3].Hosts != "*.example.com" || entries[4].Marker != "@revoked" || entries[4].Hosts != "old" {
+		t.Errorf("expected hashed, wildcard and marker lines to be kept: %+v", entries[2:])
+	}
+}
+
+func TestDuplicatesAndStale(t *testing.T) {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSigner(t).PublicKey())))
+	otherKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSigner(t).PublicKey())))
+	lines := []string{
+		"web,db " + key,
+		"web " + key,
+		"db,cache " + key,
+		"web " + otherKey,
+		knownhosts.HashHostname("[build]:2222") + " " + key,
+		"*.example.com " + key,
+		"@cert-authority web " + key,
+		"old.example.com " + key,
+	}
+	f, err := LoadKnownHosts(writeFile(t, "known_hosts", strings.Join(lines, "\n")+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := f.Entries()
+
+	if got := Duplicates(entries); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected line 2 to be the only duplicate, got %v", got)
+	}
+
+	now := time.Now()
+	h, err := history.Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record("web-alias", now.AddDate(0, -1, 0))
+	h.Record("deploy@build", now.AddDate(0, 0, -3))
+	h.Record("cache", now.AddDate(-1, 0, 0))
+	hosts := []types.SSHHost{
+		{Name: "web-alias", HostName: "web"},
+		{Name: "build-box", Aliases: []string{"deploy@build"}, HostName: "build", Port: "2222"},
+	}
+	contacts := Contacts(hosts, h)
+	if len(contacts) != 3 || contacts["[build]:2222"].IsZero() {
+		t.Fatalf("unexpected contacts: %v", contacts)
+	}
+
+	// web and the hashed build entry were used recently; cache and old.example.com were not
+	got := Stale(entries, contacts, now.AddDate(0, -6, 0))
+	want := []int{3, 8}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Stale = %v, want %v", got, want)
+	}
+
+	f.RemoveLines([]int{2, 8})
+	if entries := f.Entries(); len(entries) != 6 || entries[1].Hosts != "db,cache" {
+		t.Errorf("unexpected entries after RemoveLines: %+v", entries)
+	}
+}
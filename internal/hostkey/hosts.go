@@ -0,0 +1,102 @@
+package hostkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"strings"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+)
+
+// hashPrefix starts a hashed host name field (HashKnownHosts yes)
+const hashPrefix = "|1|"
+
+// Address returns the host:port dialed to reach host: its HostName (or name) and port
+func Address(host types.SSHHost) string {
+	name := host.HostName
+	if name == "" {
+		name = host.Name
+	}
+	port := host.Port
+	if port == "" {
+		port = types.DefaultSSHPort
+	}
+	return net.JoinHostPort(name, port)
+}
+
+// Verifiable reports whether a host's key can be checked before connecting the way ssh would
+// check it, given the options ssh applies to the host (see parser.ConfigOptions): hosts reached
+// through ProxyJump or ProxyCommand cannot be dialed directly, and hosts with their own
+// UserKnownHostsFile or StrictHostKeyChecking off are left to ssh
+func Verifiable(options map[string]string) bool {
+	for _, key := range []string{"proxyjump", "proxycommand"} {
+		if value, ok := options[key]; ok && !strings.EqualFold(value, "none") {
+			return false
+		}
+	}
+	if _, ok := options["userknownhostsfile"]; ok {
+		return false
+	}
+	switch strings.ToLower(options["stricthostkeychecking"]) {
+	case "no", "off":
+		return false
+	}
+	return true
+}
+
+// Normalize returns the form in which known_hosts records address: the bare host for port 22
+// and [host]:port otherwise
+func Normalize(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, types.DefaultSSHPort
+	}
+	host = strings.ToLower(host)
+	if port == types.DefaultSSHPort {
+		return host
+	}
+	return "[" + host + "]:" + port
+}
+
+// MatchHosts reports whether the host field of a known_hosts line matches the normalized
+// address (see Normalize). The field is either a hashed name or a comma-separated list of
+// patterns, where a matching pattern starting with ! excludes the address.
+func MatchHosts(field, normalized string) bool {
+	if strings.HasPrefix(field, hashPrefix) {
+		return matchHashed(field, normalized)
+	}
+	matched := false
+	for _, pattern := range strings.Split(field, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		if parser.MatchPattern(strings.ToLower(strings.TrimPrefix(pattern, "!")), normalized) {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchHashed checks a hashed host field, |1|salt|hash, where hash is the HMAC-SHA1 of the
+// normalized address keyed with salt
+func matchHashed(field, normalized string) bool {
+	salt64, hash64, ok := strings.Cut(strings.TrimPrefix(field, hashPrefix), "|")
+	if !ok {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return false
+	}
+	want, err := base64.StdEncoding.DecodeString(hash64)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(normalized))
+	return hmac.Equal(mac.Sum(nil), want)
+}
@@ -0,0 +1,261 @@
+package hostkey
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/history"
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// KnownHostsEntry is a key line of a known_hosts file
+type KnownHostsEntry struct {
+	Line int
+	// Marker is "@cert-authority", "@revoked" or "" for a plain host key
+	Marker string
+	// Hosts is the host field: comma-separated patterns or a hashed name
+	Hosts string
+	Type  string
+	// Key is the base64-encoded public key
+	Key     string
+	Comment string
+}
+
+// Hashed reports whether the entry's host name is hashed
+func (e KnownHostsEntry) Hashed() bool {
+	return strings.HasPrefix(e.Hosts, hashPrefix)
+}
+
+// Fingerprint returns the SHA256 fingerprint of the entry's key
+func (e KnownHostsEntry) Fingerprint() string {
+	return parser.KeyFingerprint(e.Key)
+}
+
+// KnownHostsFile is a known_hosts file kept line by line, so that editing it leaves the other
+// lines, comments included, exactly as they were
+type KnownHostsFile struct {
+	Path  string
+	lines []string
+}
+
+// LoadKnownHosts reads the known_hosts file at path; a missing file is empty
+func LoadKnownHosts(path string) (*KnownHostsFile, error) {
+	f := &KnownHostsFile{Path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if content != "" {
+		f.lines = strings.Split(content, "\n")
+	}
+	return f, nil
+}
+
+// Entries returns the file's key lines; comments, blank lines and lines that cannot be parsed
+// are left out
+func (f *KnownHostsFile) Entries() []KnownHostsEntry {
+	var entries []KnownHostsEntry
+	for i, line := range f.lines {
+		if e, _, ok := parseEntry(line); ok {
+			e.Line = i + 1
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// RemoveHost removes the keys recorded for the normalized address (see Normalize) and returns
+// the entries it changed. As with ssh-keygen -R, marker lines are kept, and lines naming the
+// address (or hashed from it) are deleted, except that a line also naming other hosts only loses
+// the address. Lines covering the address only through wildcard or negated patterns are left
+// alone, since they apply to other hosts too.
+func (f *KnownHostsFile) RemoveHost(normalized string) []KnownHostsEntry {
+	var removed []KnownHostsEntry
+	var kept []string
+	for i, line := range f.lines {
+		e, hostsAt, ok := parseEntry(line)
+		if !ok || e.Marker != "" || !MatchHosts(e.Hosts, normalized) {
+			kept = append(kept, line)
+			continue
+		}
+		var others []string
+		if !e.Hashed() {
+			for _, pattern := range strings.Split(e.Hosts, ",") {
+				if !strings.EqualFold(pattern, normalized) {
+					others = append(others, pattern)
+				}
+			}
+			if len(others) == strings.Count(e.Hosts, ",")+1 {
+				kept = append(kept, line)
+				continue
+			}
+		}
+		e.Line = i + 1
+		removed = append(removed, e)
+		if len(others) > 0 {
+			kept = append(kept, line[:hostsAt]+strings.Join(others, ",")+line[hostsAt+len(e.Hosts):])
+		}
+	}
+	f.lines = kept
+	return removed
+}
+
+// RemoveLines removes the given lines (numbered from 1)
+func (f *KnownHostsFile) RemoveLines(lines []int) {
+	remove := make(map[int]bool, len(lines))
+	for _, n := range lines {
+		remove[n] = true
+	}
+	var kept []string
+	for i, line := range f.lines {
+		if !remove[i+1] {
+			kept = append(kept, line)
+		}
+	}
+	f.lines = kept
+}
+
+// HashAll replaces plain host names with hashed ones, as ssh-keygen -H does: a line naming several
+// hosts becomes one line per host. Marker lines, hashed lines and lines with wildcard or negated
+// patterns are left alone. It returns the number of names hashed.
+func (f *KnownHostsFile) HashAll() int {
+	hashed := 0
+	var lines []string
+	for _, line := range f.lines {
+		e, _, ok := parseEntry(line)
+		if !ok || e.Marker != "" || e.Hashed() || strings.ContainsAny(e.Hosts, "*?!") {
+			lines = append(lines, line)
+			continue
+		}
+		rest := e.Type + " " + e.Key
+		if e.Comment != "" {
+			rest += " " + e.Comment
+		}
+		for _, name := range strings.Split(e.Hosts, ",") {
+			lines = append(lines, knownhosts.HashHostname(name)+" "+rest)
+			hashed++
+		}
+	}
+	f.lines = lines
+	return hashed
+}
+
+// Duplicates returns the lines of entries that record nothing new: every host name they list
+// already has the same key on an earlier line. Hashed names are salted, so they are never found
+// to be duplicates.
+func Duplicates(entries []KnownHostsEntry) []int {
+	var lines []int
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.Marker != "" || e.Hashed() {
+			continue
+		}
+		duplicate := true
+		for _, name := range strings.Split(strings.ToLower(e.Hosts), ",") {
+			id := name + " " + e.Type + " " + e.Key
+			if !seen[id] {
+				duplicate = false
+				seen[id] = true
+			}
+		}
+		if duplicate {
+			lines = append(lines, e.Line)
+		}
+	}
+	return lines
+}
+
+// Stale returns the lines of entries for hosts not connected to since cutoff. contacts maps the
+// names known_hosts records hosts under to their last connection (see Contacts). Marker lines and
+// lines with wildcard or negated patterns are never stale.
+func Stale(entries []KnownHostsEntry, contacts map[string]time.Time, cutoff time.Time) []int {
+	var lines []int
+	for _, e := range entries {
+		if e.Marker != "" || (!e.Hashed() && strings.ContainsAny(e.Hosts, "*?!")) {
+			continue
+		}
+		stale := true
+		for name, last := range contacts {
+			if !last.Before(cutoff) && MatchHosts(e.Hosts, name) {
+				stale = false
+				break
+			}
+		}
+		if stale {
+			lines = append(lines, e.Line)
+		}
+	}
+	return lines
+}
+
+// Contacts maps the names known_hosts records hosts under (see Normalize) to the last time
+// ssh-tui connected to them. History names are resolved through hosts; names not among them are
+// read as custom hosts (user@host).
+func Contacts(hosts []types.SSHHost, h *history.History) map[string]time.Time {
+	contacts := make(map[string]time.Time)
+	if h == nil {
+		return contacts
+	}
+	byName := make(map[string]types.SSHHost, len(hosts))
+	for _, host := range hosts {
+		byName[strings.ToLower(host.Name)] = host
+		for _, alias := range host.Aliases {
+			byName[strings.ToLower(alias)] = host
+		}
+	}
+	for name, entry := range h.Entries {
+		host, ok := byName[name]
+		if !ok {
+			_, hostName := parser.ParseUserHost(name)
+			host = types.SSHHost{Name: name, HostName: hostName}
+		}
+		key := Normalize(Address(host))
+		if entry.LastConnected.After(contacts[key]) {
+			contacts[key] = entry.LastConnected
+		}
+	}
+	return contacts
+}
+
+// Save writes the file, keeping the previous version as a .bak backup
+func (f *KnownHostsFile) Save() error {
+	content := strings.Join(f.lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return parser.ReplaceFile(f.Path, content)
+}
+
+// parseEntry parses a known_hosts key line: an optional marker, the host field, the key type,
+// the key and an optional comment. It also returns the offset of the host field in line.
+func parseEntry(line string) (KnownHostsEntry, int, bool) {
+	var e KnownHostsEntry
+	rest := strings.TrimLeft(line, " \t")
+	if rest == "" || strings.HasPrefix(rest, "#") {
+		return e, 0, false
+	}
+	if strings.HasPrefix(rest, "@") {
+		fields := strings.Fields(rest)
+		e.Marker = fields[0]
+		rest = strings.TrimLeft(rest[len(e.Marker):], " \t")
+	}
+	hostsAt := len(line) - len(rest)
+
+	fields := strings.Fields(rest)
+	if len(fields) < 3 {
+		return e, 0, false
+	}
+	e.Hosts, e.Type, e.Key = fields[0], fields[1], fields[2]
+	if len(fields) > 3 {
+		e.Comment = strings.Join(fields[3:], " ")
+	}
+	return e, hostsAt, true
+}
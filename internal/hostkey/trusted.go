@@ -0,0 +1,119 @@
+package hostkey
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ssh-tui/internal/parser"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Verdict is the outcome of comparing a host key with the published fingerprints
+type Verdict int
+
+const (
+	// VerdictUnlisted means no fingerprint is published for the host (and key type)
+	VerdictUnlisted Verdict = iota
+	// VerdictMatch means the key's fingerprint is published for the host
+	VerdictMatch
+	// VerdictMismatch means fingerprints are published for the host, but not the key's
+	VerdictMismatch
+)
+
+// Entry is a published fingerprint for the hosts matching Hosts
+type Entry struct {
+	// Hosts is the host field, in known_hosts syntax
+	Hosts string
+	// Type is the key type, or "" if the line does not say
+	Type        string
+	Fingerprint string
+	File        string
+	Line        int
+}
+
+// Trusted is a list of host key fingerprints published by a team
+type Trusted []Entry
+
+// LoadTrusted reads the fingerprint files at paths; a leading ~ is expanded
+func LoadTrusted(paths []string) (Trusted, error) {
+	var trusted Trusted
+	for _, path := range paths {
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(home, path[1:])
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read fingerprints: %w", err)
+		}
+		entries, err := ParseTrusted(file, path)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		trusted = append(trusted, entries...)
+	}
+	return trusted, nil
+}
+
+// ParseTrusted parses a fingerprint list read from r. Each line holds a host field followed by
+// either a fingerprint ("web1,web2 SHA256:..."), a key type and fingerprint
+// ("web1 ssh-ed25519 SHA256:...") or a key as in known_hosts ("web1 ssh-ed25519 AAAA...").
+// Comments, blank lines and marker lines such as @cert-authority are skipped.
+func ParseTrusted(r io.Reader, path string) (Trusted, error) {
+	var trusted Trusted
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		entry := Entry{Hosts: fields[0], File: path, Line: lineNum}
+		switch {
+		case len(fields) >= 2 && strings.HasPrefix(fields[1], "SHA256:"):
+			entry.Fingerprint = fields[1]
+		case len(fields) >= 3 && strings.HasPrefix(fields[2], "SHA256:"):
+			entry.Type, entry.Fingerprint = fields[1], fields[2]
+		case len(fields) >= 3:
+			entry.Type, entry.Fingerprint = fields[1], parser.KeyFingerprint(fields[2])
+			if entry.Fingerprint == "" {
+				return nil, fmt.Errorf("%s:%d: invalid key", path, lineNum)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: expected a host and a fingerprint or key", path, lineNum)
+		}
+		trusted = append(trusted, entry)
+	}
+	return trusted, scanner.Err()
+}
+
+// Lookup compares key with the fingerprints published for address. Entries for other key
+// types are ignored, so a host publishing only its RSA key is unlisted for an ed25519 key.
+// The matching entry is returned with VerdictMatch.
+func (t Trusted) Lookup(address string, key ssh.PublicKey) (Verdict, *Entry) {
+	normalized := Normalize(address)
+	fingerprint := ssh.FingerprintSHA256(key)
+	verdict := VerdictUnlisted
+	for i, e := range t {
+		if (e.Type != "" && e.Type != key.Type()) || !MatchHosts(e.Hosts, normalized) {
+			continue
+		}
+		if e.Fingerprint == fingerprint {
+			return VerdictMatch, &t[i]
+		}
+		verdict = VerdictMismatch
+	}
+	return verdict, nil
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"ssh-tui/internal/types"
+)
+
+// Supported import formats
+const (
+	FormatPuTTY     = "putty"
+	FormatTermius   = "termius"
+	FormatMobaXterm = "mobaxterm"
+)
+
+// Formats lists the supported import formats
+var Formats = []string{FormatPuTTY, FormatTermius, FormatMobaXterm}
+
+// ImportFile reads sessions from the export file at path. An empty format is detected from the
+// file's content. The returned hosts have valid ssh-tui names; the original session name is kept
+// as the description when it had to be changed.
+func ImportFile(path, format string) ([]types.SSHHost, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data := decodeText(raw)
+
+	if format == "" {
+		format = DetectFormat(path, data)
+		if format == "" {
+			return nil, fmt.Errorf("cannot tell the format of %s; use --format %s", path, strings.Join(Formats, "|"))
+		}
+	}
+
+	var hosts []types.SSHHost
+	switch format {
+	case FormatPuTTY:
+		hosts, err = parsePuTTYReg(data)
+	case FormatTermius:
+		hosts, err = parseTermius(data)
+	case FormatMobaXterm:
+		hosts, err = parseMobaXterm(data)
+	default:
+		return nil, fmt.Errorf("unknown import format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return uniqueNames(hosts), nil
+}
+
+// DetectFormat guesses the export format from the file name and content; "" if unknown
+func DetectFormat(path, data string) string {
+	trimmed := strings.TrimSpace(data)
+	switch {
+	case strings.HasPrefix(trimmed, "Windows Registry Editor") || strings.HasPrefix(trimmed, "REGEDIT4"):
+		return FormatPuTTY
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		if strings.HasPrefix(trimmed, "[Bookmarks") {
+			return FormatMobaXterm
+		}
+		return FormatTermius
+	case strings.EqualFold(filepath.Ext(path), ".mxtsessions") || strings.Contains(data, "[Bookmarks"):
+		return FormatMobaXterm
+	}
+	return ""
+}
+
+// decodeText converts raw file content to a string, handling the UTF-16 that regedit writes
+// and stripping byte order marks
+func decodeText(raw []byte) string {
+	if len(raw) >= 2 && (raw[0] == 0xFF && raw[1] == 0xFE || raw[0] == 0xFE && raw[1] == 0xFF) {
+		bigEndian := raw[0] == 0xFE
+		raw = raw[2:]
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 0; i+1 < len(raw); i += 2 {
+			if bigEndian {
+				units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+			} else {
+				units = append(units, uint16(raw[i+1])<<8|uint16(raw[i]))
+			}
+		}
+		return string(utf16.Decode(units))
+	}
+	raw = bytes.TrimPrefix(raw, []byte("\xEF\xBB\xBF"))
+	if !utf8.Valid(raw) {
+		return string(bytes.ToValidUTF8(raw, []byte("?")))
+	}
+	return string(raw)
+}
+
+// invalidNameChars matches runs of characters not allowed in generated host names
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// hostName turns a session label into a name usable as a Host alias, e.g. "Web Server #1" -> "web-server-1"
+func hostName(label string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(label)), "-")
+	return strings.Trim(name, "-.")
+}
+
+// newHost builds a host from a session, deriving its name from label (or the address when the
+// label is unusable) and keeping a changed label as the description
+func newHost(label, address, user, port string, tags []string) types.SSHHost {
+	name := hostName(label)
+	if name == "" {
+		name = hostName(address)
+	}
+	host := types.SSHHost{
+		Name:     name,
+		HostName: address,
+		User:     user,
+		Port:     port,
+		Tags:     tags,
+	}
+	if label != "" && label != name {
+		host.Description = label
+	}
+	if host.HostName == host.Name {
+		host.HostName = ""
+	}
+	if host.Port == types.DefaultSSHPort {
+		host.Port = ""
+	}
+	return host
+}
+
+// splitUserHost splits "user@host" as PuTTY and others allow in the host field
+func splitUserHost(address, user string) (string, string) {
+	if at := strings.LastIndex(address, "@"); at != -1 {
+		if user == "" {
+			user = address[:at]
+		}
+		address = address[at+1:]
+	}
+	return address, user
+}
+
+// uniqueNames drops sessions without a usable name and suffixes repeated names with -2, -3, ...,
+// skipping suffixed names that are already taken
+func uniqueNames(hosts []types.SSHHost) []types.SSHHost {
+	// Names taken by sessions, so that a session named "web-2" keeps its name
+	taken := make(map[string]bool)
+	for _, h := range hosts {
+		taken[h.Name] = true
+	}
+	kept := make(map[string]bool)
+	next := make(map[string]int)
+	result := hosts[:0]
+	for _, h := range hosts {
+		if h.Name == "" {
+			continue
+		}
+		if !kept[h.Name] {
+			kept[h.Name] = true
+			result = append(result, h)
+			continue
+		}
+		base := h.Name
+		n := max(next[base], 2)
+		for taken[fmt.Sprintf("%s-%d", base, n)] {
+			n++
+		}
+		h.Name = fmt.Sprintf("%s-%d", base, n)
+		taken[h.Name], kept[h.Name] = true, true
+		next[base] = n + 1
+		result = append(result, h)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"ssh-tui/internal/types"
+)
+
+func TestImportFile(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+		want   []types.SSHHost
+	}{
+		{"putty.reg", FormatPuTTY, []types.SSHHost{
+			{Name: "web-server-1", HostName: "web1.example.com", User: "deploy", Description: "Web Server #1"},
+			{Name: "router", HostName: "10.0.0.1", User: "admin", Port: "2222"},
+		}},
+		{"termius.json", FormatTermius, []types.SSHHost{
+			{Name: "api", HostName: "api.example.com", User: "svc", Port: "2200", Tags: []string{"production", "backend"}, Description: "API"},
+			{Name: "db-primary", HostName: "db.example.com", User: "root", Tags: []string{"databases"}, Description: "db primary"},
+		}},
+		{"sessions.mxtsessions", FormatMobaXterm, []types.SSHHost{
+			{Name: "jump", HostName: "bastion.example.com", User: "ops"},
+			{Name: "web-1", HostName: "10.1.0.1", User: "deploy", Port: "2222", Tags: []string{"prod", "web"}, Description: "web 1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.file)
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectFormat(path, decodeText(raw)); got != tt.format {
+				t.Errorf("DetectFormat = %q, want %q", got, tt.format)
+			}
+
+			hosts, err := ImportFile(path, "")
+			if err != nil {
+				t.Fatalf("ImportFile failed: %v", err)
+			}
+			if !reflect.DeepEqual(hosts, tt.want) {
+				t.Errorf("ImportFile =\n%+v\nwant\n%+v", hosts, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportFile_UTF16(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "putty.reg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// regedit writes UTF-16LE with a byte order mark
+	encoded := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(string(raw))) {
+		encoded = append(encoded, byte(u), byte(u>>8))
+	}
+	path := filepath.Join(t.TempDir(), "sessions.reg")
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ImportFile(path, "")
+	if err != nil {
+		t.Fatalf("ImportFile failed: %v", err)
+	}
+	if len(hosts) != 2 || hosts[1].Port != "2222" {
+		t.Fatalf("unexpected hosts: %+v", hosts)
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"web", "", "web", "web"}, []string{"web", "web-2", "web-3"}},
+		// Suffixes already taken by other sessions are skipped
+		{[]string{"web", "web", "web-2"}, []string{"web", "web-3", "web-2"}},
+		{[]string{"web-2", "web", "web", "web-2"}, []string{"web-2", "web", "web-3", "web-2-2"}},
+	}
+	for _, tt := range tests {
+		var hosts []types.SSHHost
+		for _, name := range tt.names {
+			hosts = append(hosts, types.SSHHost{Name: name})
+		}
+		var names []string
+		for _, h := range uniqueNames(hosts) {
+			names = append(names, h.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("uniqueNames(%v) = %v, want %v", tt.names, names, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// mobaSSHType is the session type MobaXterm uses for SSH sessions
+const mobaSSHType = "109"
+
+// parseMobaXterm parses a MobaXterm sessions export (.mxtsessions or MobaXterm.ini). Sessions
+// live in [Bookmarks...] sections as "name=#type#icon%host%port%user%...". Only SSH sessions are
+// imported, and the folder path in the section's SubRep becomes tags.
+func parseMobaXterm(data string) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	var inBookmarks bool
+	var tags []string
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inBookmarks = strings.HasPrefix(strings.ToLower(line), "[bookmarks")
+			found = found || inBookmarks
+			tags = nil
+			continue
+		}
+		if !inBookmarks {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		switch name {
+		case "SubRep":
+			tags = nil
+			for _, folder := range strings.Split(value, `\`) {
+				if tag := hostName(folder); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+			continue
+		case "ImgNum":
+			continue
+		}
+
+		if host, ok := mobaHost(name, strings.TrimSpace(value), tags); ok {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no MobaXterm bookmarks found")
+	}
+	return hosts, nil
+}
+
+// mobaHost converts a session value to a host
+func mobaHost(name, value string, tags []string) (types.SSHHost, bool) {
+	// "#109#0%host%22%user%..." - the session type sits between the first two '#'
+	if !strings.HasPrefix(value, "#") {
+		return types.SSHHost{}, false
+	}
+	parts := strings.SplitN(value[1:], "#", 2)
+	if len(parts) != 2 || parts[0] != mobaSSHType {
+		return types.SSHHost{}, false
+	}
+	fields := strings.Split(parts[1], "%")
+	if len(fields) < 4 || fields[1] == "" {
+		return types.SSHHost{}, false
+	}
+	address, user := splitUserHost(fields[1], fields[3])
+	return newHost(name, address, user, fields[2], append([]string(nil), tags...)), true
+}
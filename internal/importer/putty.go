@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// puttySessionsKey is the registry key under which PuTTY stores its saved sessions
+const puttySessionsKey = `\software\simontatham\putty\sessions\`
+
+// parsePuTTYReg parses a registry export of PuTTY's saved sessions. Only SSH sessions are
+// imported; "Default Settings" and sessions without a host name are skipped.
+func parsePuTTYReg(data string) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	var session string
+	values := make(map[string]string)
+
+	flush := func() {
+		if session == "" {
+			return
+		}
+		if host, ok := puttyHost(session, values); ok {
+			hosts = append(hosts, host)
+		}
+		session = ""
+		values = make(map[string]string)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	found := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			key := line[1 : len(line)-1]
+			if i := strings.Index(strings.ToLower(key), puttySessionsKey); i != -1 {
+				name, err := url.PathUnescape(key[i+len(puttySessionsKey):])
+				if err != nil {
+					name = key[i+len(puttySessionsKey):]
+				}
+				session = name
+				found = true
+			}
+			continue
+		}
+		if session == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.Trim(name, `"`)] = regValue(value)
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no PuTTY sessions found")
+	}
+	return hosts, nil
+}
+
+// puttyHost converts a session's registry values to a host
+func puttyHost(session string, values map[string]string) (types.SSHHost, bool) {
+	if session == "Default Settings" {
+		return types.SSHHost{}, false
+	}
+	if proto := values["Protocol"]; proto != "" && proto != "ssh" {
+		return types.SSHHost{}, false
+	}
+	address, user := splitUserHost(values["HostName"], values["UserName"])
+	if address == "" {
+		return types.SSHHost{}, false
+	}
+	return newHost(session, address, user, values["PortNumber"], nil), true
+}
+
+// regValue decodes a .reg value: a quoted string or a hexadecimal dword
+func regValue(value string) string {
+	if strings.HasPrefix(value, "dword:") {
+		n, err := strconv.ParseUint(strings.TrimPrefix(value, "dword:"), 16, 32)
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatUint(n, 10)
+	}
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = value[1 : len(value)-1]
+		value = strings.ReplaceAll(value, `\\`, `\`)
+		value = strings.ReplaceAll(value, `\"`, `"`)
+		return value
+	}
+	return ""
+}
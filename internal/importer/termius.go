@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"ssh-tui/internal/types"
+)
+
+// parseTermius parses a Termius JSON export. Termius has changed its export layout over time,
+// so hosts are read from a top-level array or a "hosts" list, and each field is looked up under
+// the names used by the different versions. Group and tag labels become tags.
+func parseTermius(data string) ([]types.SSHHost, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+
+	var entries []any
+	groups := make(map[string]string)
+	switch v := doc.(type) {
+	case []any:
+		entries = v
+	case map[string]any:
+		root := v
+		if inner, ok := v["data"].(map[string]any); ok {
+			root = inner
+		}
+		list, ok := root["hosts"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("no hosts found in Termius export")
+		}
+		entries = list
+		if groupList, ok := root["groups"].([]any); ok {
+			for _, g := range groupList {
+				if gm, ok := g.(map[string]any); ok {
+					groups[scalar(gm["id"])] = firstString(gm, "label", "name")
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("no hosts found in Termius export")
+	}
+
+	var hosts []types.SSHHost
+	for _, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		sshConfig, _ := entry["ssh_config"].(map[string]any)
+
+		address := firstString(entry, "address", "hostname", "host")
+		if address == "" {
+			continue
+		}
+		user := firstString(entry, "username", "user")
+		port := scalar(entry["port"])
+		if sshConfig != nil {
+			if user == "" {
+				user = firstString(sshConfig, "username", "user")
+				if identity, ok := sshConfig["identity"].(map[string]any); ok && user == "" {
+					user = firstString(identity, "username")
+				}
+			}
+			if port == "" {
+				port = scalar(sshConfig["port"])
+			}
+		}
+		address, user = splitUserHost(address, user)
+
+		var tags []string
+		if group := termiusLabel(entry["group"], groups); group != "" {
+			tags = append(tags, hostName(group))
+		}
+		if tagList, ok := entry["tags"].([]any); ok {
+			for _, t := range tagList {
+				if label := termiusLabel(t, nil); label != "" {
+					tags = append(tags, hostName(label))
+				}
+			}
+		}
+
+		hosts = append(hosts, newHost(firstString(entry, "label", "name", "title"), address, user, port, tags))
+	}
+	return hosts, nil
+}
+
+// termiusLabel returns the label of a group or tag given as a string, an object with a label,
+// or an id resolved through groups
+func termiusLabel(v any, groups map[string]string) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]any:
+		return firstString(t, "label", "name")
+	case float64:
+		return groups[scalar(t)]
+	}
+	return ""
+}
+
+// firstString returns the first non-empty string among the given keys of m
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// scalar formats a JSON string or number as a string; anything else yields ""
+func scalar(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package keys
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/ssh"
+	"ssh-tui/internal/sshagent"
+	"ssh-tui/internal/types"
+)
+
+// DefaultInstallTimeout bounds installing a key on one host
+const DefaultInstallTimeout = 30 * time.Second
+
+// Result says what installing a key on a host did
+type Result int
+
+const (
+	// ResultAdded means the key was appended to authorized_keys
+	ResultAdded Result = iota
+	// ResultPresent means authorized_keys already held the key
+	ResultPresent
+)
+
+// Installer appends public keys to the authorized_keys file of hosts, like ssh-copy-id. It runs
+// the ssh client, so the hosts' config applies, in batch mode: hosts that would ask for a
+// password or to accept their host key fail instead, and are installed on with Interactive.
+type Installer struct {
+	Timeout time.Duration
+	// Run runs ssh with args and returns its standard output; tests replace it
+	Run func(ctx context.Context, args []string) ([]byte, error)
+}
+
+// NewInstaller returns an installer running the ssh client
+func NewInstaller() *Installer {
+	return &Installer{Timeout: DefaultInstallTimeout, Run: runSSH}
+}
+
+// Install adds key to host's ~/.ssh/authorized_keys unless it is already there
+func (in *Installer) Install(ctx context.Context, host types.SSHHost, key PublicKey) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, in.Timeout)
+	defer cancel()
+
+	out, err := in.Run(ctx, InstallArgs(host, key))
+	if err != nil {
+		return 0, err
+	}
+	return parseResult(out)
+}
+
+// Interactive returns the ssh command installing key on host with the terminal attached, so that
+// ssh can ask for a password or to accept the host key, and a function turning the command's
+// exit error into the result. ssh's errors are shown on the terminal and also kept for the result.
+func Interactive(host types.SSHHost, key PublicKey) (*exec.Cmd, func(error) (Result, error)) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("ssh", installArgs(host, key, "-o", "ConnectTimeout=10")...)
+	cmd.Stdout, cmd.Stderr = &stdout, io.MultiWriter(os.Stderr, &stderr)
+	return cmd, func(err error) (Result, error) {
+		if err != nil {
+			return 0, sshError(err, stderr.String())
+		}
+		return parseResult(stdout.Bytes())
+	}
+}
+
+// NeedsTerminal reports whether a batch mode install failed because ssh wanted to ask for a
+// password, a passphrase or to accept the host key, so that Interactive may succeed
+func NeedsTerminal(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Permission denied") || strings.Contains(msg, "Host key verification failed")
+}
+
+// parseResult reads the output of the remote script
+func parseResult(out []byte) (Result, error) {
+	switch strings.TrimSpace(string(out)) {
+	case "added":
+		return ResultAdded, nil
+	case "present":
+		return ResultPresent, nil
+	default:
+		return 0, fmt.Errorf("unexpected output from the host: %q", strings.TrimSpace(string(out)))
+	}
+}
+
+// InstallArgs returns the ssh arguments installing key on host. The remote script runs under sh,
+// whatever the login shell; it creates ~/.ssh and authorized_keys with private permissions,
+// looks for the key by type and data so that a different comment does not add it twice, and
+// ends a last line lacking a newline before appending.
+func InstallArgs(host types.SSHHost, key PublicKey) []string {
+	return installArgs(host, key, "-o", "BatchMode=yes", "-o", "ConnectTimeout=10")
+}
+
+// installArgs returns the ssh arguments running the install script on host after options
+func installArgs(host types.SSHHost, key PublicKey, options ...string) []string {
+	fields := strings.Fields(key.Line)
+	match := strings.Join(fields[:min(2, len(fields))], " ")
+	script := `umask 077; mkdir -p .ssh && touch .ssh/authorized_keys || exit 1; ` +
+		`if grep -qF ` + quote(match) + ` .ssh/authorized_keys; then echo present; exit 0; fi; ` +
+		`if [ -s .ssh/authorized_keys ] && [ -n "$(tail -c 1 .ssh/authorized_keys)" ]; then echo >> .ssh/authorized_keys; fi; ` +
+		`echo ` + quote(key.Line) + ` >> .ssh/authorized_keys && echo added`
+
+	args := options
+	// The destination as it is connected to: the config name, or user@host with its port
+	args = append(args, strings.Fields(ssh.BuildSSHCommand(&host, ""))[1:]...)
+	return append(args, "exec sh -c "+quote(script))
+}
+
+// AddIdentityFile adds an IdentityFile line for the key at path to the config Host block of host.
+// It returns false when the block already uses the key or host is not from the SSH config.
+func AddIdentityFile(host types.SSHHost, path string) (bool, error) {
+	if host.Source != types.SourceConfig || host.SourceFile == "" {
+		return false, nil
+	}
+	if slices.Contains(sshagent.IdentityFiles(host), path) {
+		return false, nil
+	}
+	return parser.AddDirective(host.SourceFile, host.SourceLine, types.Directive{Key: "IdentityFile", Value: ConfigPath(path)})
+}
+
+// quote quotes s for a POSIX shell
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runSSH runs the ssh client without a terminal, reporting its last error line on failure
+func runSSH(ctx context.Context, args []string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("timed out: %w", ctx.Err())
+	}
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+	return nil, sshError(err, stderr.String())
+}
+
+// sshError returns the last line ssh wrote to stderr as the error when it exited with a failure
+func sshError(err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if lines := strings.Split(strings.TrimSpace(stderr), "\n"); errors.As(err, &exitErr) && lines[len(lines)-1] != "" {
+		return errors.New(strings.TrimSpace(lines[len(lines)-1]))
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// PublicKey is a public key file, such as ~/.ssh/id_ed25519.pub
+type PublicKey struct {
+	// Path is the private key's path; the public key is read from Path+".pub"
+	Path        string
+	Type        string
+	Fingerprint string
+	Comment     string
+	// Line is the key as written to authorized_keys
+	Line string
+}
+
+// LoadPublicKey reads the public key of the private key at path from its .pub file
+func LoadPublicKey(path string) (PublicKey, error) {
+	data, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return PublicKey{}, err
+	}
+	key, comment, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key %s.pub: %w", path, err)
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	if comment != "" {
+		line += " " + comment
+	}
+	return PublicKey{Path: path, Type: key.Type(), Fingerprint: ssh.FingerprintSHA256(key), Comment: comment, Line: line}, nil
+}
+
+// ListPublicKeys returns the keys in dir that have a .pub file next to the private key, sorted
+// by path; unreadable files are skipped
+func ListPublicKeys(dir string) ([]PublicKey, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	var keys []PublicKey
+	for _, match := range matches {
+		path := strings.TrimSuffix(match, ".pub")
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if key, err := LoadPublicKey(path); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// NextKeyPath returns the first of id_ed25519, id_ed25519_2, id_ed25519_3… in dir for which
+// neither the key nor its .pub file exists
+func NextKeyPath(dir string) string {
+	for n := 1; ; n++ {
+		name := "id_ed25519"
+		if n > 1 {
+			name = fmt.Sprintf("id_ed25519_%d", n)
+		}
+		path := filepath.Join(dir, name)
+		if !exists(path) && !exists(path+".pub") {
+			return path
+		}
+	}
+}
+
+// DefaultComment returns user@hostname, the comment ssh-keygen gives new keys
+func DefaultComment() string {
+	name := "user"
+	if u, err := user.Current(); err == nil {
+		// Windows user names include the domain
+		name = u.Username[strings.LastIndex(u.Username, `\`)+1:]
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return name
+	}
+	return name + "@" + host
+}
+
+// Generate creates an ed25519 key pair at path and path+".pub", encrypting the private key with
+// passphrase unless it is empty. Existing files are never overwritten.
+func Generate(path, comment string, passphrase []byte) (PublicKey, error) {
+	if exists(path) || exists(path+".pub") {
+		return PublicKey{}, fmt.Errorf("%s already exists", path)
+	}
+	if strings.ContainsAny(comment, "\r\n") {
+		return PublicKey{}, fmt.Errorf("the comment must be a single line")
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	var block *pem.Block
+	if len(passphrase) == 0 {
+		block, err = ssh.MarshalPrivateKey(priv, comment)
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, comment, passphrase)
+	}
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("cannot encode the private key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return PublicKey{}, err
+	}
+	if err := writeNew(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return PublicKey{}, err
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+	if comment != "" {
+		line += " " + comment
+	}
+	if err := writeNew(path+".pub", []byte(line+"\n"), 0o644); err != nil {
+		os.Remove(path)
+		return PublicKey{}, err
+	}
+	return PublicKey{Path: path, Type: sshPub.Type(), Fingerprint: ssh.FingerprintSHA256(sshPub), Comment: comment, Line: line}, nil
+}
+
+// ConfigPath returns path as written in an SSH config: with ~ for the home directory
+func ConfigPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// writeNew writes data to a file that must not exist yet
+func writeNew(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// exists reports whether path can be stat'ed
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,206 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".ssh")
+	path := NextKeyPath(dir)
+	if filepath.Base(path) != "id_ed25519" {
+		t.Fatalf("unexpected first key path %s", path)
+	}
+
+	key, err := Generate(path, "me@laptop", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Type != ssh.KeyAlgoED25519 || !strings.HasSuffix(key.Line, " me@laptop") {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	// The private key needs the passphrase and matches the .pub file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing *ssh.PassphraseMissingError
+	if _, err := ssh.ParsePrivateKey(data); !errors.As(err, &missing) {
+		t.Fatalf("expected the key to be encrypted, got %v", err)
+	}
+	signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssh.FingerprintSHA256(signer.PublicKey()) != key.Fingerprint {
+		t.Errorf("the private key does not match the public key")
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+			t.Errorf("expected the private key to be private, got %04o", info.Mode().Perm())
+		}
+	}
+
+	loaded, err := LoadPublicKey(path)
+	if err != nil || loaded != key {
+		t.Errorf("LoadPublicKey returned %+v, %v; want %+v", loaded, err, key)
+	}
+
+	// Existing keys are never overwritten
+	if _, err := Generate(path, "", nil); err == nil {
+		t.Errorf("expected an error for an existing key")
+	}
+	next := NextKeyPath(dir)
+	if filepath.Base(next) != "id_ed25519_2" {
+		t.Fatalf("unexpected next key path %s", next)
+	}
+	if _, err := Generate(next, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(next)
+	if _, err := ssh.ParsePrivateKey(data); err != nil {
+		t.Errorf("expected a key without passphrase to be readable: %v", err)
+	}
+
+	// A .pub file without its private key is not listed
+	if err := os.WriteFile(filepath.Join(dir, "orphan.pub"), []byte(key.Line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := ListPublicKeys(dir)
+	if err != nil || len(listed) != 2 || listed[0].Path != path || listed[1].Path != next {
+		t.Errorf("unexpected keys %+v, %v", listed, err)
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got := ConfigPath(filepath.Join(home, ".ssh", "id_work")); got != "~/.ssh/id_work" {
+		t.Errorf("got %q", got)
+	}
+	outside := filepath.Join(filepath.Dir(home), "elsewhere", "id")
+	if got := ConfigPath(outside); got != outside {
+		t.Errorf("got %q, want %q", got, outside)
+	}
+}
+
+// runLocally runs the remote script of InstallArgs with sh in dir, standing in for the host
+func runLocally(dir string) func(context.Context, []string) ([]byte, error) {
+	return func(ctx context.Context, args []string) ([]byte, error) {
+		remote := args[len(args)-1]
+		cmd := exec.CommandContext(ctx, "sh", "-c", remote)
+		cmd.Dir = dir
+		return cmd.Output()
+	}
+}
+
+func TestInstall(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil || runtime.GOOS == "windows" {
+		t.Skip("sh is not available")
+	}
+	remoteHome := t.TempDir()
+	key := PublicKey{Line: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey it's me"}
+	installer := &Installer{Timeout: DefaultInstallTimeout, Run: runLocally(remoteHome)}
+	host := types.SSHHost{Name: "web", Source: types.SourceConfig}
+
+	result, err := installer.Install(context.Background(), host, key)
+	if err != nil || result != ResultAdded {
+		t.Fatalf("got %v, %v; want the key to be added", result, err)
+	}
+	authorized := filepath.Join(remoteHome, ".ssh", "authorized_keys")
+	if info, err := os.Stat(authorized); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a private authorized_keys: %v", err)
+	}
+
+	// The key is found again even under another comment
+	key.Line = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey other comment"
+	if result, err := installer.Install(context.Background(), host, key); err != nil || result != ResultPresent {
+		t.Fatalf("got %v, %v; want the key to be present", result, err)
+	}
+
+	// A last line without a newline is ended before appending
+	if err := os.WriteFile(authorized, []byte("ssh-rsa AAAAB3 old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installer.Install(context.Background(), host, key); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(authorized)
+	if string(data) != "ssh-rsa AAAAB3 old\n"+key.Line+"\n" {
+		t.Errorf("unexpected authorized_keys:\n%s", data)
+	}
+}
+
+func TestInstallArgs(t *testing.T) {
+	key := PublicKey{Line: "ssh-ed25519 AAAA me"}
+	args := InstallArgs(types.SSHHost{Name: "web", Source: types.SourceConfig}, key)
+	if got := strings.Join(args[:len(args)-1], " "); got != "-o BatchMode=yes -o ConnectTimeout=10 web" {
+		t.Errorf("unexpected arguments %q", got)
+	}
+	args = InstallArgs(types.SSHHost{Name: "db", HostName: "10.0.0.5", User: "root", Port: "2222", Source: types.SourceCustom}, key)
+	if got := strings.Join(args[:len(args)-1], " "); got != "-o BatchMode=yes -o ConnectTimeout=10 -p 2222 root@10.0.0.5" {
+		t.Errorf("unexpected arguments %q", got)
+	}
+	if !strings.HasPrefix(args[len(args)-1], "exec sh -c '") {
+		t.Errorf("expected the script to run under sh: %q", args[len(args)-1])
+	}
+
+	failing := &Installer{Timeout: DefaultInstallTimeout, Run: func(context.Context, []string) ([]byte, error) {
+		return nil, errors.New("Permission denied (publickey,password).")
+	}}
+	_, err := failing.Install(context.Background(), types.SSHHost{Name: "web"}, key)
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("expected the ssh error, got %v", err)
+	}
+	if !NeedsTerminal(err) || NeedsTerminal(errors.New("Connection refused")) {
+		t.Errorf("expected only the password failure to need a terminal")
+	}
+
+	// The interactive command may prompt, so it does not use batch mode
+	cmd, result := Interactive(types.SSHHost{Name: "web", Source: types.SourceConfig}, key)
+	if got := strings.Join(cmd.Args[1:len(cmd.Args)-1], " "); got != "-o ConnectTimeout=10 web" {
+		t.Errorf("unexpected interactive arguments %q", got)
+	}
+	if _, err := result(nil); err == nil {
+		t.Errorf("expected an error without output from the host")
+	}
+}
+
+func TestAddIdentityFile(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(config, []byte("Host web\n  HostName web.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_web")
+	host := types.SSHHost{Name: "web", Source: types.SourceConfig, SourceFile: config, SourceLine: 1}
+
+	if added, err := AddIdentityFile(host, keyPath); err != nil || !added {
+		t.Fatalf("got %v, %v; want the IdentityFile to be added", added, err)
+	}
+	data, _ := os.ReadFile(config)
+	if want := "Host web\n  HostName web.example.com\n  IdentityFile " + ConfigPath(keyPath) + "\n"; string(data) != want {
+		t.Errorf("unexpected config:\n%s", data)
+	}
+
+	// Hosts already using the key and hosts outside the config are left alone
+	host.Directives = []types.Directive{{Key: "IdentityFile", Value: keyPath}}
+	if added, err := AddIdentityFile(host, keyPath); err != nil || added {
+		t.Errorf("expected the key to be found, got %v, %v", added, err)
+	}
+	if added, err := AddIdentityFile(types.SSHHost{Name: "x", Source: types.SourceCustom}, keyPath); err != nil || added {
+		t.Errorf("expected custom hosts to be skipped, got %v, %v", added, err)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Annotation keywords recognised in comments above a Host block, e.g.
+//
+//	# @tags prod,db  @desc Primary Postgres
+const (
+	annotationTags = "@tags"
+	annotationDesc = "@desc"
+)
+
+// parseAnnotations extracts the description and tags from a host's comment lines.
+// Lines starting with an annotation keyword are consumed; all other lines are returned as notes.
+func parseAnnotations(comments []string) (description string, tags []string, notes []string) {
+	for _, comment := range comments {
+		if !strings.HasPrefix(comment, annotationTags) && !strings.HasPrefix(comment, annotationDesc) {
+			notes = append(notes, comment)
+			continue
+		}
+
+		for _, field := range splitAnnotations(comment) {
+			keyword, value, _ := strings.Cut(field, " ")
+			value = strings.TrimSpace(value)
+			switch keyword {
+			case annotationTags:
+				for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+					if !containsFold(tags, tag) {
+						tags = append(tags, tag)
+					}
+				}
+			case annotationDesc:
+				if description != "" && value != "" {
+					description += " "
+				}
+				description += value
+			}
+		}
+	}
+	return description, tags, notes
+}
+
+// splitAnnotations splits a comment into "@keyword value" fields
+func splitAnnotations(comment string) []string {
+	var fields []string
+	for _, word := range strings.Fields(comment) {
+		if word == annotationTags || word == annotationDesc || len(fields) == 0 {
+			fields = append(fields, word)
+			continue
+		}
+		fields[len(fields)-1] += " " + word
+	}
+	return fields
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
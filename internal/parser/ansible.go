@@ -0,0 +1,660 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ssh-tui/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceAnsible is the name of the Ansible inventory source
+const SourceAnsible = "ansible"
+
+// Ansible's implicit groups, which are not turned into tags
+const (
+	ansibleAll       = "all"
+	ansibleUngrouped = "ungrouped"
+)
+
+// ansibleSource is a HostSource reading Ansible inventory files
+type ansibleSource struct {
+	paths []string
+}
+
+// NewAnsibleSource returns a source reading the given INI or YAML inventory files. A directory
+// is read like Ansible does, as every inventory file inside it.
+func NewAnsibleSource(paths []string) HostSource {
+	return &ansibleSource{paths: paths}
+}
+
+func (s *ansibleSource) Name() string {
+	return SourceAnsible
+}
+
+func (s *ansibleSource) Discover(ctx context.Context) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	var errs []error
+	for _, path := range s.paths {
+		if err := ctx.Err(); err != nil {
+			return hosts, err
+		}
+		files, err := inventoryFiles(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, file := range files {
+			fileHosts, err := ParseAnsibleInventory(file)
+			hosts = append(hosts, fileHosts...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return hosts, errors.Join(errs...)
+}
+
+// inventoryFiles expands path into inventory files, skipping the non-inventory files Ansible ignores
+func inventoryFiles(path string) ([]string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || ignoredInventoryFile(name) {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	return files, nil
+}
+
+// inventoryIgnoreSuffixes are the file name endings Ansible skips in inventory directories by
+// default (INVENTORY_IGNORE_EXTS)
+var inventoryIgnoreSuffixes = []string{"~", ".orig", ".bak", ".cfg", ".retry", ".pyc", ".pyo", ".swp", ".rpm", ".md", ".txt", ".rst"}
+
+// ignoredInventoryFile reports whether Ansible skips the file name in an inventory directory
+func ignoredInventoryFile(name string) bool {
+	for _, suffix := range inventoryIgnoreSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
+
+// ParseAnsibleInventory reads an INI or YAML (.yml/.yaml) inventory file. Hosts that cannot be
+// used with ssh are skipped and reported in the returned error alongside the other hosts.
+func ParseAnsibleInventory(path string) ([]types.SSHHost, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var inv *inventory
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		inv, err = parseYAMLInventory(file)
+	default:
+		inv, err = parseINIInventory(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	hosts, err := inv.resolve(path)
+	if err != nil {
+		return hosts, fmt.Errorf("%s: %w", path, err)
+	}
+	return hosts, nil
+}
+
+// inventory is the format-independent form of an Ansible inventory
+type inventory struct {
+	groups map[string]*inventoryGroup
+	hosts  map[string]*inventoryHost
+	// order lists host names in the order they first appear
+	order []string
+}
+
+// inventoryGroup holds a group's own hosts, child groups and variables
+type inventoryGroup struct {
+	name     string
+	hosts    []string
+	children []string
+	vars     map[string]string
+}
+
+// inventoryHost holds a host's own variables and where it was first defined
+type inventoryHost struct {
+	name string
+	line int
+	vars map[string]string
+}
+
+func newInventory() *inventory {
+	return &inventory{groups: make(map[string]*inventoryGroup), hosts: make(map[string]*inventoryHost)}
+}
+
+// group returns the named group, creating it if needed
+func (inv *inventory) group(name string) *inventoryGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &inventoryGroup{name: name, vars: make(map[string]string)}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+// addHost adds a host (or host range) to a group, merging variables into any earlier definition
+func (inv *inventory) addHost(group, pattern string, line int, vars map[string]string) error {
+	names, err := expandHostRange(pattern)
+	if err != nil {
+		return err
+	}
+	g := inv.group(group)
+	for _, name := range names {
+		h, ok := inv.hosts[name]
+		if !ok {
+			h = &inventoryHost{name: name, line: line, vars: make(map[string]string)}
+			inv.hosts[name] = h
+			inv.order = append(inv.order, name)
+		}
+		for k, v := range vars {
+			h.vars[k] = v
+		}
+		if !containsFold(g.hosts, name) {
+			g.hosts = append(g.hosts, name)
+		}
+	}
+	return nil
+}
+
+// addChild makes child a child group of parent
+func (inv *inventory) addChild(parent, child string) {
+	p := inv.group(parent)
+	inv.group(child)
+	if !containsFold(p.children, child) {
+		p.children = append(p.children, child)
+	}
+}
+
+// hostGroups returns, for every host, the set of groups it belongs to directly or
+// through child groups
+func (inv *inventory) hostGroups() map[string]map[string]bool {
+	result := make(map[string]map[string]bool)
+	for name := range inv.groups {
+		for _, host := range inv.groupHosts(name, make(map[string]bool)) {
+			if result[host] == nil {
+				result[host] = make(map[string]bool)
+			}
+			result[host][name] = true
+		}
+	}
+	return result
+}
+
+// groupHosts returns the hosts of a group and of its descendants; seen guards against cycles
+func (inv *inventory) groupHosts(group string, seen map[string]bool) []string {
+	if seen[group] {
+		return nil
+	}
+	seen[group] = true
+
+	g := inv.groups[group]
+	hosts := append([]string(nil), g.hosts...)
+	for _, child := range g.children {
+		hosts = append(hosts, inv.groupHosts(child, seen)...)
+	}
+	return hosts
+}
+
+// groupDepth returns the length of the longest chain of parent groups above group;
+// seen guards against cycles
+func (inv *inventory) groupDepth(group string, seen map[string]bool) int {
+	if seen[group] {
+		return 0
+	}
+	seen[group] = true
+	defer delete(seen, group)
+
+	depth := 0
+	for name, g := range inv.groups {
+		if containsFold(g.children, group) {
+			if d := inv.groupDepth(name, seen) + 1; d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth
+}
+
+// resolve turns the inventory into hosts. Variables apply in Ansible's order: "all", then
+// groups from least to most specific (alphabetically within a level), then the host's own.
+// Group memberships become tags.
+func (inv *inventory) resolve(path string) ([]types.SSHHost, error) {
+	memberships := inv.hostGroups()
+
+	var hosts []types.SSHHost
+	var problems []string
+	for _, name := range inv.order {
+		h := inv.hosts[name]
+
+		groups := make([]string, 0, len(memberships[name]))
+		for g := range memberships[name] {
+			groups = append(groups, g)
+		}
+		depths := make(map[string]int, len(groups))
+		for _, g := range groups {
+			depths[g] = inv.groupDepth(g, make(map[string]bool))
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if depths[groups[i]] != depths[groups[j]] {
+				return depths[groups[i]] < depths[groups[j]]
+			}
+			return groups[i] < groups[j]
+		})
+
+		vars := make(map[string]string)
+		if all, ok := inv.groups[ansibleAll]; ok {
+			mergeVars(vars, all.vars)
+		}
+		var tags []string
+		for _, g := range groups {
+			if g == ansibleAll {
+				continue
+			}
+			mergeVars(vars, inv.groups[g].vars)
+			if g != ansibleUngrouped {
+				tags = append(tags, g)
+			}
+		}
+		mergeVars(vars, h.vars)
+
+		host := types.SSHHost{
+			Name:       name,
+			HostName:   firstVar(vars, "ansible_host", "ansible_ssh_host"),
+			User:       firstVar(vars, "ansible_user", "ansible_ssh_user"),
+			Port:       firstVar(vars, "ansible_port", "ansible_ssh_port"),
+			Source:     SourceAnsible,
+			SourceFile: path,
+			SourceLine: h.line,
+			Tags:       appendMissing(nil, tags),
+		}
+		if host.HostName == name {
+			host.HostName = ""
+		}
+		if err := ValidateHostEntry(host); err != nil {
+			if len(problems) < maxReportedErrors {
+				problems = append(problems, fmt.Sprintf("host %s: %v", name, err))
+			}
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+
+	if len(hosts) < len(inv.order) {
+		return hosts, fmt.Errorf("%d host(s) skipped: %s", len(inv.order)-len(hosts), strings.Join(problems, "; "))
+	}
+	return hosts, nil
+}
+
+// mergeVars copies src into dst, overriding existing keys
+func mergeVars(dst, src map[string]string) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// firstVar returns the value of the first of keys that is set
+func firstVar(vars map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := vars[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseINIInventory parses an INI inventory: [group], [group:vars] and [group:children]
+// sections, with hosts before the first section belonging to "ungrouped"
+func parseINIInventory(r io.Reader) (*inventory, error) {
+	inv := newInventory()
+	section, kind := ansibleUngrouped, "hosts"
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind = line[1:len(line)-1], "hosts"
+			if i := strings.LastIndex(section, ":"); i != -1 {
+				section, kind = section[:i], section[i+1:]
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return nil, fmt.Errorf("line %d: unknown section type %q", lineNum, kind)
+			}
+			inv.group(section)
+			continue
+		}
+
+		fields, err := splitINIFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		switch kind {
+		case "vars":
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value", lineNum)
+			}
+			inv.group(section).vars[strings.TrimSpace(k)] = unquote(strings.TrimSpace(v))
+		case "children":
+			inv.addChild(section, fields[0])
+		default:
+			vars := make(map[string]string)
+			for _, f := range fields[1:] {
+				k, v, ok := strings.Cut(f, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNum, f)
+				}
+				vars[k] = unquote(v)
+			}
+			if err := inv.addHost(section, fields[0], lineNum, vars); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+		}
+	}
+	return inv, scanner.Err()
+}
+
+// splitINIFields splits a host line on whitespace, keeping quoted values together and
+// dropping a trailing comment
+func splitINIFields(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == '#' && current.Len() == 0:
+			return fields, nil
+		case r == ' ' || r == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+// unquote strips matching single or double quotes around s
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseYAMLInventory parses a YAML inventory, whose top level maps group names to
+// groups holding hosts, vars and children
+func parseYAMLInventory(r io.Reader) (*inventory, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return newInventory(), nil
+		}
+		return nil, err
+	}
+
+	inv := newInventory()
+	if len(doc.Content) == 0 {
+		return inv, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of groups", root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if err := inv.addYAMLGroup(root.Content[i].Value, root.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+// addYAMLGroup adds a group and, recursively, its children from a YAML node
+func (inv *inventory) addYAMLGroup(name string, node *yaml.Node) error {
+	g := inv.group(name)
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: group %s must be a mapping", node.Line, name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "hosts":
+			if err := inv.addYAMLHosts(name, value); err != nil {
+				return err
+			}
+		case "vars":
+			vars, err := yamlVars(value)
+			if err != nil {
+				return err
+			}
+			mergeVars(g.vars, vars)
+		case "children":
+			if value.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				child := value.Content[j].Value
+				inv.addChild(name, child)
+				if err := inv.addYAMLGroup(child, value.Content[j+1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addYAMLHosts adds the hosts mapping of a group
+func (inv *inventory) addYAMLHosts(group string, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		vars, err := yamlVars(value)
+		if err != nil {
+			return err
+		}
+		if err := inv.addHost(group, key.Value, key.Line, vars); err != nil {
+			return fmt.Errorf("line %d: %w", key.Line, err)
+		}
+	}
+	return nil
+}
+
+// yamlVars reads a mapping of variables, keeping scalar values only
+func yamlVars(node *yaml.Node) (map[string]string, error) {
+	vars := make(map[string]string)
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return vars, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of variables", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+			vars[node.Content[i].Value] = value.Value
+		}
+	}
+	return vars, nil
+}
+
+// expandHostRange expands Ansible host patterns such as "web[01:20].example.com" or "db-[a:c]",
+// with an optional step ("[1:10:2]"). Patterns without a range are returned as is.
+func expandHostRange(pattern string) ([]string, error) {
+	start := strings.Index(pattern, "[")
+	if start == -1 {
+		return []string{pattern}, nil
+	}
+	end := strings.Index(pattern[start:], "]")
+	if end == -1 {
+		return nil, fmt.Errorf("invalid host range %q", pattern)
+	}
+	end += start
+
+	prefix, spec, suffix := pattern[:start], pattern[start+1:end], pattern[end+1:]
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid host range %q", pattern)
+	}
+	step := 1
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid step in host range %q", pattern)
+		}
+		step = n
+	}
+
+	items, err := rangeItems(parts[0], parts[1], step)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host range %q: %w", pattern, err)
+	}
+
+	// The suffix may hold further ranges
+	rest, err := expandHostRange(suffix)
+	if err != nil {
+		return nil, err
+	}
+	if len(items)*len(rest) > maxRangeSize {
+		return nil, fmt.Errorf("host range %q expands to more than %d hosts", pattern, maxRangeSize)
+	}
+	names := make([]string, 0, len(items)*len(rest))
+	for _, item := range items {
+		for _, r := range rest {
+			names = append(names, prefix+item+r)
+		}
+	}
+	return names, nil
+}
+
+// maxRangeSize guards against typos like web[1:1000000] or web[000:999][000:999] producing
+// absurd host lists; it bounds each range and the hosts a pattern expands to
+const maxRangeSize = 10000
+
+// rangeItems returns the values from begin to end, numeric (zero-padded to begin's width when
+// begin has a leading zero) or single letters
+func rangeItems(begin, end string, step int) ([]string, error) {
+	if b, err := strconv.Atoi(begin); err == nil {
+		e, err := strconv.Atoi(end)
+		if err != nil || e < b {
+			return nil, fmt.Errorf("bad bounds")
+		}
+		if (e-b)/step >= maxRangeSize {
+			return nil, fmt.Errorf("range too large")
+		}
+		width := 0
+		if len(begin) > 1 && begin[0] == '0' {
+			width = len(begin)
+		}
+		var items []string
+		for i := b; i <= e; i += step {
+			items = append(items, fmt.Sprintf("%0*d", width, i))
+		}
+		return items, nil
+	}
+
+	if len(begin) != 1 || len(end) != 1 || begin[0] > end[0] || !isLetter(begin[0]) || !isLetter(end[0]) {
+		return nil, fmt.Errorf("bad bounds")
+	}
+	var items []string
+	for c := begin[0]; c <= end[0]; c += byte(step) {
+		items = append(items, string(c))
+		if int(c)+step > 255 {
+			break
+		}
+	}
+	return items, nil
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/types"
+)
+
+// hostsByName indexes hosts by name for easier assertions
+func hostsByName(hosts []types.SSHHost) map[string]types.SSHHost {
+	m := make(map[string]types.SSHHost, len(hosts))
+	for _, h := range hosts {
+		m[h.Name] = h
+	}
+	return m
+}
+
+func TestParseAnsibleInventory_INI(t *testing.T) {
+	path := filepath.Join("testdata", "ansible", "inventory.ini")
+	hosts, err := ParseAnsibleInventory(path)
+	if err != nil {
+		t.Fatalf("ParseAnsibleInventory failed: %v", err)
+	}
+
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.Name)
+	}
+	want := []string{"bastion", "web01.example.com", "web02.example.com", "web03.example.com", "web-legacy", "db-a.example.com", "db-b.example.com"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("hosts = %v, want %v", names, want)
+	}
+
+	byName := hostsByName(hosts)
+	tests := []struct {
+		name, hostName, user, port string
+		tags                       []string
+	}{
+		{"bastion", "203.0.113.10", "jump", "22", nil},
+		{"web02.example.com", "", "deploy", "2222", []string{"production", "webservers"}},
+		{"web-legacy", "10.0.0.99", "deploy", "22", []string{"production", "webservers"}},
+		{"db-a.example.com", "", "postgres", "22", []string{"production", "dbservers"}},
+	}
+	for _, tt := range tests {
+		h := byName[tt.name]
+		if h.HostName != tt.hostName || h.User != tt.user || h.Port != tt.port || !reflect.DeepEqual(h.Tags, tt.tags) {
+			t.Errorf("%s: got HostName=%q User=%q Port=%q Tags=%v", tt.name, h.HostName, h.User, h.Port, h.Tags)
+		}
+		if h.Source != SourceAnsible || h.SourceFile != path || h.SourceLine == 0 {
+			t.Errorf("%s: unexpected source info %q %q %d", tt.name, h.Source, h.SourceFile, h.SourceLine)
+		}
+	}
+}
+
+func TestParseAnsibleInventory_YAML(t *testing.T) {
+	hosts, err := ParseAnsibleInventory(filepath.Join("testdata", "ansible", "inventory.yml"))
+	if err == nil || !strings.Contains(err.Error(), "templated") {
+		t.Fatalf("expected the templated host to be reported, got %v", err)
+	}
+
+	byName := hostsByName(hosts)
+	if len(hosts) != 4 {
+		t.Fatalf("expected 4 hosts, got %v", hosts)
+	}
+	if h := byName["bastion"]; h.HostName != "203.0.113.10" || h.User != "admin" || len(h.Tags) != 0 {
+		t.Errorf("unexpected bastion: %+v", h)
+	}
+	for _, name := range []string{"web1", "web3", "web5"} {
+		h := byName[name]
+		if h.Port != "2200" || h.User != "stage" || !reflect.DeepEqual(h.Tags, []string{"staging", "webservers"}) {
+			t.Errorf("unexpected %s: %+v", name, h)
+		}
+	}
+}
+
+func TestExpandHostRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{"web", []string{"web"}, false},
+		{"web[1:3]", []string{"web1", "web2", "web3"}, false},
+		{"web[08:10].lan", []string{"web08.lan", "web09.lan", "web10.lan"}, false},
+		{"db-[a:c]", []string{"db-a", "db-b", "db-c"}, false},
+		{"n[0:4:2]", []string{"n0", "n2", "n4"}, false},
+		{"r[1:2]-[a:b]", []string{"r1-a", "r1-b", "r2-a", "r2-b"}, false},
+		{"web[3:1]", nil, true},
+		{"web[1:", nil, true},
+		{"web[1:1000000]", nil, true},
+		{"web[000:999][000:999]", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := expandHostRange(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("expandHostRange(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandHostRange(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestAnsibleSource_Directory(t *testing.T) {
+	hosts, err := NewAnsibleSource([]string{filepath.Join("testdata", "ansible")}).Discover(context.Background())
+	if err == nil {
+		t.Fatalf("expected the YAML inventory's bad host to be reported")
+	}
+	if len(hosts) != 11 {
+		t.Fatalf("expected hosts from both inventories, got %d", len(hosts))
+	}
+}
+
+func TestInventoryFiles_Ignored(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"hosts", "prod.yml", "README.md", "hosts.ini.bak", "hosts~", "notes.txt", "hosts.swp", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[web]\nweb1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := inventoryFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "hosts"), filepath.Join(dir, "prod.yml")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("inventoryFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestParseAnsibleInventory_RangeTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("[web]\nweb[000:999][000:999].example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseAnsibleInventory(path); err == nil || !strings.Contains(err.Error(), "line 2: host range") {
+		t.Fatalf("expected the oversized range to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+// DefaultCommandTimeout bounds a command source's run when no timeout is configured
+const DefaultCommandTimeout = 10 * time.Second
+
+// maxReportedErrors limits how many bad output lines are listed in a command source error
+const maxReportedErrors = 3
+
+// CommandSourceConfig describes an external executable that prints hosts as JSON lines, e.g.
+//
+//	{"name": "web1", "hostname": "10.0.0.5", "user": "deploy", "port": 2222, "tags": ["prod"]}
+type CommandSourceConfig struct {
+	Name    string
+	Command string
+	Args    []string
+	Timeout time.Duration
+	// CachePath is where the last good result is kept; empty disables caching
+	CachePath string
+}
+
+// commandSource is a HostSource that runs an external command
+type commandSource struct {
+	cfg CommandSourceConfig
+}
+
+// NewCommandSource returns a source that runs an external command and reads hosts from its
+// output. If the command fails, the last good result is returned along with the error.
+func NewCommandSource(cfg CommandSourceConfig) HostSource {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultCommandTimeout
+	}
+	return &commandSource{cfg: cfg}
+}
+
+func (s *commandSource) Name() string {
+	return s.cfg.Name
+}
+
+func (s *commandSource) Discover(ctx context.Context) ([]types.SSHHost, error) {
+	hosts, err := s.run(ctx)
+	if err == nil {
+		s.saveCache(hosts)
+		return hosts, nil
+	}
+
+	// Partial output is still worth showing, but only a clean run replaces the cache
+	if len(hosts) > 0 {
+		return hosts, err
+	}
+	if cached, when, ok := s.loadCache(); ok {
+		return cached, fmt.Errorf("%w (showing cached result from %s)", err, when.Format("2006-01-02 15:04"))
+	}
+	return nil, err
+}
+
+// run executes the command and parses its output
+func (s *commandSource) run(ctx context.Context) ([]types.SSHHost, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.cfg.Command, s.cfg.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children of a killed command may hold its output open; don't wait for them
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", s.cfg.Timeout)
+		}
+		if msg := lastLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return parseCommandOutput(&stdout, s.cfg.Name)
+}
+
+// commandHost is one JSON line of command source output
+type commandHost struct {
+	Name        string          `json:"name"`
+	HostName    string          `json:"hostname,omitempty"`
+	User        string          `json:"user,omitempty"`
+	Port        json.RawMessage `json:"port,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// parseCommandOutput reads JSON lines of hosts. Blank lines are ignored; bad lines are skipped
+// and reported in the returned error alongside the hosts that did parse.
+func parseCommandOutput(r io.Reader, source string) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	var problems []string
+	badLines := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		host, err := parseCommandHost(line, source)
+		if err != nil {
+			badLines++
+			if len(problems) < maxReportedErrors {
+				problems = append(problems, fmt.Sprintf("line %d: %v", lineNum, err))
+			}
+			continue
+		}
+		host.SourceLine = lineNum
+		hosts = append(hosts, host)
+	}
+	if err := scanner.Err(); err != nil {
+		return hosts, err
+	}
+
+	if badLines > 0 {
+		return hosts, fmt.Errorf("%d invalid line(s): %s", badLines, strings.Join(problems, "; "))
+	}
+	return hosts, nil
+}
+
+// parseCommandHost decodes and validates a single output line
+func parseCommandHost(line, source string) (types.SSHHost, error) {
+	var ch commandHost
+	if err := json.Unmarshal([]byte(line), &ch); err != nil {
+		return types.SSHHost{}, err
+	}
+
+	port, err := parsePortValue(ch.Port)
+	if err != nil {
+		return types.SSHHost{}, err
+	}
+
+	host := types.SSHHost{
+		Name:        strings.TrimSpace(ch.Name),
+		HostName:    strings.TrimSpace(ch.HostName),
+		User:        strings.TrimSpace(ch.User),
+		Port:        port,
+		Source:      source,
+		Description: ch.Description,
+		Tags:        appendMissing(nil, ch.Tags),
+	}
+	if err := ValidateHostEntry(host); err != nil {
+		return types.SSHHost{}, err
+	}
+	return host, nil
+}
+
+// parsePortValue accepts a port given as a JSON number or string; absent or null means unset
+func parsePortValue(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var port string
+	if err := json.Unmarshal(raw, &port); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return "", fmt.Errorf("invalid port %s", raw)
+		}
+		port = n.String()
+	}
+	if port == "" {
+		return "", nil
+	}
+	return port, validatePort(port)
+}
+
+// validatePort checks that port is a number between 1 and 65535
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// ValidateHostEntry checks the fields of a host from an external source, which end up on
+// the ssh command line, so that they are well-formed host names, users and ports
+func ValidateHostEntry(host types.SSHHost) error {
+	if host.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if strings.ContainsAny(host.Name, " \t@") || strings.HasPrefix(host.Name, "-") {
+		return fmt.Errorf("invalid name %q", host.Name)
+	}
+	target := host.HostName
+	if target == "" {
+		target = host.Name
+	}
+	if strings.Contains(target, "@") || !IsValidHost(target) {
+		return fmt.Errorf("invalid hostname %q", target)
+	}
+	if host.User != "" && (strings.ContainsAny(host.User, " \t@") || strings.HasPrefix(host.User, "-")) {
+		return fmt.Errorf("invalid user %q", host.User)
+	}
+	if host.Port != "" {
+		return validatePort(host.Port)
+	}
+	return nil
+}
+
+// WriteHostLines writes hosts in the JSON lines format read by command sources
+func WriteHostLines(w io.Writer, hosts []types.SSHHost) error {
+	enc := json.NewEncoder(w)
+	for _, h := range hosts {
+		line := commandHost{
+			Name:        h.Name,
+			HostName:    h.HostName,
+			User:        h.User,
+			Tags:        h.Tags,
+			Description: h.Description,
+		}
+		if h.Port != "" {
+			line.Port = json.RawMessage(strconv.Quote(h.Port))
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line of s, which is usually the most useful error message
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// commandCache is the on-disk form of a command source's last good result
+type commandCache struct {
+	Updated time.Time       `json:"updated"`
+	Hosts   []types.SSHHost `json:"hosts"`
+}
+
+// saveCache stores hosts as the last good result; failures are ignored since the cache is best-effort
+func (s *commandSource) saveCache(hosts []types.SSHHost) {
+	if s.cfg.CachePath == "" {
+		return
+	}
+	data, err := json.Marshal(commandCache{Updated: time.Now(), Hosts: hosts})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.cfg.CachePath), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(s.cfg.CachePath, data, 0o600)
+}
+
+// loadCache returns the last good result, if there is one
+func (s *commandSource) loadCache() ([]types.SSHHost, time.Time, bool) {
+	if s.cfg.CachePath == "" {
+		return nil, time.Time{}, false
+	}
+	data, err := os.ReadFile(s.cfg.CachePath)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var cache commandCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, time.Time{}, false
+	}
+	return cache.Hosts, cache.Updated, true
+}
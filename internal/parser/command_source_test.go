@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shellSource returns a command source running script with sh
+func shellSource(t *testing.T, script string, cachePath string) HostSource {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command source tests need sh")
+	}
+	return NewCommandSource(CommandSourceConfig{
+		Name:      "cmdb",
+		Command:   "sh",
+		Args:      []string{"-c", script},
+		Timeout:   2 * time.Second,
+		CachePath: cachePath,
+	})
+}
+
+func TestCommandSource_Discover(t *testing.T) {
+	script := `printf '%s\n' '{"name": "web1", "hostname": "10.0.0.5", "user": "deploy", "port": 2222, "tags": ["prod", "web"]}' '' '{"name": "db1", "port": "5432"}'`
+	hosts, err := shellSource(t, script, "").Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+	web := hosts[0]
+	if web.Name != "web1" || web.HostName != "10.0.0.5" || web.User != "deploy" || web.Port != "2222" || web.Source != "cmdb" {
+		t.Errorf("unexpected host: %+v", web)
+	}
+	if len(web.Tags) != 2 || web.Tags[0] != "prod" {
+		t.Errorf("unexpected tags: %v", web.Tags)
+	}
+	if hosts[1].Port != "5432" {
+		t.Errorf("expected string port to be accepted, got %q", hosts[1].Port)
+	}
+}
+
+func TestCommandSource_InvalidLines(t *testing.T) {
+	script := `printf '%s\n' '{"name": "web1"}' 'not json' '{"name": "bad", "hostname": "a b"}' '{"name": "p", "port": 70000}'`
+	hosts, err := shellSource(t, script, "").Discover(context.Background())
+	if len(hosts) != 1 || hosts[0].Name != "web1" {
+		t.Fatalf("expected the valid host to be kept, got %+v", hosts)
+	}
+	if err == nil || !strings.Contains(err.Error(), "3 invalid line(s)") || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected parse errors to be reported, got %v", err)
+	}
+}
+
+func TestCommandSource_FailureUsesCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cmdb.json")
+
+	good := shellSource(t, `echo '{"name": "web1"}'`, cachePath)
+	if _, err := good.Discover(context.Background()); err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	failing := shellSource(t, `echo 'cmdb: connection refused' >&2; exit 3`, cachePath)
+	hosts, err := failing.Discover(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "connection refused") || !strings.Contains(err.Error(), "cached result") {
+		t.Fatalf("expected stderr and cache notice in error, got %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "web1" {
+		t.Fatalf("expected cached hosts, got %+v", hosts)
+	}
+}
+
+func TestCommandSource_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command source tests need sh")
+	}
+	src := NewCommandSource(CommandSourceConfig{Name: "slow", Command: "sh", Args: []string{"-c", "sleep 5"}, Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := src.Discover(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("timeout was not enforced")
+	}
+}
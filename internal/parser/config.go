@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"bufio"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,97 +9,170 @@ import (
 	"ssh-tui/internal/types"
 )
 
+// maxIncludeDepth limits nested Include directives, matching ssh's own limit
+const maxIncludeDepth = 16
+
 // ParseSSHConfig parses the SSH config file and returns a list of hosts
 func ParseSSHConfig() ([]types.SSHHost, error) {
 	var hosts []types.SSHHost
 
-	homeDir, err := os.UserHomeDir()
+	configPath, err := UserConfigPath()
 	if err != nil {
 		return hosts, err
 	}
-	configPath := filepath.Join(homeDir, ".ssh", "config")
 
 	// Check if config file exists; return empty if not (config is optional)
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return hosts, nil
 	}
-	file, err := os.Open(configPath)
+
+	return parseSSHConfigFile(configPath, 0)
+}
+
+// UserConfigPath returns the path of the user's SSH config file (~/.ssh/config)
+func UserConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return hosts, err
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "config"), nil
+}
+
+// ResolveInclude expands an Include pattern into the list of matching files.
+// Like ssh, a leading ~ refers to the home directory and relative paths are relative to ~/.ssh.
+func ResolveInclude(pattern string) ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern == "~" || strings.HasPrefix(pattern, "~/") {
+		pattern = filepath.Join(homeDir, pattern[1:])
+	} else if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(homeDir, ".ssh", pattern)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// parseSSHConfigFile opens and parses the config file at path, following Include directives
+func parseSSHConfigFile(path string, depth int) ([]types.SSHHost, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var currentHost types.SSHHost
-	var inHostSection bool
+	return parseSSHConfigDepth(file, path, depth)
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// parseSSHConfig parses SSH config content read from r; path is recorded as the hosts' source file
+func parseSSHConfig(r io.Reader, path string) ([]types.SSHHost, error) {
+	return parseSSHConfigDepth(r, path, 0)
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// parseSSHConfigDepth parses SSH config content, tracking the Include nesting depth
+func parseSSHConfigDepth(r io.Reader, path string, depth int) ([]types.SSHHost, error) {
+	cfg, err := ParseConfig(r)
+	if err != nil {
+		return nil, err
+	}
 
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
+	hosts := includedHosts(cfg.Global, depth)
+	for _, block := range cfg.Blocks {
+		if host, ok := hostFromBlock(block, path); ok {
+			hosts = append(hosts, host)
 		}
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
-
-		switch key {
-		case "host":
-			if inHostSection && currentHost.Name != "" {
-				currentHost.Source = types.SourceConfig
-				hosts = append(hosts, currentHost)
-			}
+		// Hosts from Include directives inside a block follow the block itself
+		hosts = append(hosts, includedHosts(block, depth)...)
+	}
 
-			// Parse multiple hostnames (space-delimited)
-			hostNames := strings.Fields(value)
-			if len(hostNames) == 0 {
-				inHostSection = false
-				continue
-			}
+	return hosts, nil
+}
 
-			// Use the first hostname as the primary name
-			primaryHostName := hostNames[0]
+// hostFromBlock converts a Host block into an SSHHost; Match blocks and blocks whose
+// primary name is a wildcard pattern are skipped
+func hostFromBlock(block *ConfigBlock, path string) (types.SSHHost, bool) {
+	if !block.IsHost() {
+		return types.SSHHost{}, false
+	}
 
-			// Skip wildcard entries
-			if strings.Contains(primaryHostName, "*") || strings.Contains(primaryHostName, "?") {
-				inHostSection = false
-				continue
-			}
+	// Parse multiple hostnames (space-delimited)
+	hostNames := block.Patterns()
+	if len(hostNames) == 0 {
+		return types.SSHHost{}, false
+	}
 
-			// Collect aliases (all hostnames except the first one)
-			var aliases []string
-			if len(hostNames) > 1 {
-				aliases = hostNames[1:]
-			}
+	// Use the first hostname as the primary name
+	primaryHostName := hostNames[0]
 
-			currentHost = types.SSHHost{Name: primaryHostName, Aliases: aliases}
-			inHostSection = true
+	// Skip wildcard entries
+	if strings.Contains(primaryHostName, "*") || strings.Contains(primaryHostName, "?") {
+		return types.SSHHost{}, false
+	}
+
+	// Collect aliases (all hostnames except the first one)
+	var aliases []string
+	if len(hostNames) > 1 {
+		aliases = hostNames[1:]
+	}
+
+	host := types.SSHHost{
+		Name:       primaryHostName,
+		Aliases:    aliases,
+		Source:     types.SourceConfig,
+		SourceFile: path,
+		SourceLine: block.Header.Num,
+	}
+	host.Description, host.Tags, host.Notes = parseAnnotations(block.Notes())
+
+	for _, line := range block.Directives() {
+		if line.Value == "" {
+			continue
+		}
 
+		switch strings.ToLower(line.Key) {
 		case "hostname":
-			if inHostSection && IsValidHost(value) {
-				currentHost.HostName = value
+			if IsValidHost(line.Value) {
+				host.HostName = line.Value
 			}
 		case "user":
-			if inHostSection {
-				currentHost.User = value
-			}
+			host.User = line.Value
 		case "port":
-			if inHostSection {
-				currentHost.Port = value
-			}
+			host.Port = line.Value
 		}
+
+		host.Directives = append(host.Directives, types.Directive{Key: line.Key, Value: line.Value, Line: line.Num})
 	}
 
-	// Add the last host if it exists
-	if inHostSection && currentHost.Name != "" {
-		currentHost.Source = types.SourceConfig
-		hosts = append(hosts, currentHost)
+	return host, true
+}
+
+// includedHosts parses the files referenced by a block's Include directives.
+// Unreadable or unmatched includes are skipped, as ssh does.
+func includedHosts(block *ConfigBlock, depth int) []types.SSHHost {
+	if depth >= maxIncludeDepth {
+		return nil
 	}
 
-	return hosts, scanner.Err()
+	var hosts []types.SSHHost
+	for _, line := range block.Directives() {
+		if !strings.EqualFold(line.Key, "include") {
+			continue
+		}
+		for _, pattern := range strings.Fields(line.Value) {
+			files, err := ResolveInclude(pattern)
+			if err != nil {
+				continue
+			}
+			for _, f := range files {
+				fileHosts, err := parseSSHConfigFile(f, depth+1)
+				if err != nil {
+					continue
+				}
+				hosts = append(hosts, fileHosts...)
+			}
+		}
+	}
+	return hosts
 }
@@ -0,0 +1,474 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// LineKind classifies a line of an SSH config file
+type LineKind int
+
+const (
+	// LineBlank is an empty or whitespace-only line
+	LineBlank LineKind = iota
+	// LineComment is a line starting with '#'
+	LineComment
+	// LineDirective is a "Keyword value" or "Keyword=value" line
+	LineDirective
+)
+
+// ConfigLine is a single line of an SSH config file. Unmodified lines are written back
+// exactly as read; modified lines are rendered from their parts.
+type ConfigLine struct {
+	Kind LineKind
+	// Num is the 1-based line number at parse time (0 for lines added later)
+	Num    int
+	Indent string
+	Key    string
+	// Sep is the text between keyword and value, e.g. " " or " = "
+	Sep   string
+	Value string
+
+	raw      string
+	eol      string
+	modified bool
+}
+
+// ConfigBlock is a Host or Match block: its header line, the comment lines directly above it,
+// and the lines that follow up to the next block. The block holding directives that precede
+// any Host or Match line has no header.
+type ConfigBlock struct {
+	Leading []*ConfigLine
+	Header  *ConfigLine
+	Lines   []*ConfigLine
+
+	file *ConfigFile
+}
+
+// ConfigFile is a lossless representation of an SSH config file that can be edited at the
+// directive and block level and written back without disturbing untouched content
+type ConfigFile struct {
+	// Global holds the lines before the first Host or Match block
+	Global *ConfigBlock
+	Blocks []*ConfigBlock
+
+	newline string
+}
+
+// ParseConfig parses SSH config content into a ConfigFile
+func ParseConfig(r io.Reader) (*ConfigFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigString(string(data)), nil
+}
+
+// LoadConfigFile parses the SSH config file at path; a missing file yields an empty config
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return parseConfigString(""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigString(string(data)), nil
+}
+
+// parseConfigString splits content into lines and groups them into blocks
+func parseConfigString(content string) *ConfigFile {
+	c := &ConfigFile{newline: "\n"}
+	if strings.Contains(content, "\r\n") {
+		c.newline = "\r\n"
+	}
+	c.Global = &ConfigBlock{file: c}
+	current := c.Global
+
+	num := 0
+	for len(content) > 0 {
+		num++
+		text, eol := content, ""
+		if i := strings.IndexByte(content, '\n'); i != -1 {
+			text, eol = content[:i], "\n"
+			content = content[i+1:]
+		} else {
+			content = ""
+		}
+		if strings.HasSuffix(text, "\r") && eol != "" {
+			text, eol = text[:len(text)-1], "\r\n"
+		}
+
+		line := parseConfigLine(text)
+		line.Num = num
+		line.eol = eol
+
+		if line.Kind == LineDirective && isHeaderKey(line.Key) {
+			block := &ConfigBlock{Header: line, file: c}
+			// Comments directly above the header describe the new block
+			split := len(current.Lines)
+			for split > 0 && current.Lines[split-1].Kind == LineComment {
+				split--
+			}
+			block.Leading = append(block.Leading, current.Lines[split:]...)
+			current.Lines = current.Lines[:split]
+
+			c.Blocks = append(c.Blocks, block)
+			current = block
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	return c
+}
+
+// parseConfigLine classifies a single line of text
+func parseConfigLine(text string) *ConfigLine {
+	line := &ConfigLine{raw: text}
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "":
+		line.Kind = LineBlank
+	case strings.HasPrefix(trimmed, "#"):
+		line.Kind = LineComment
+	default:
+		line.Kind = LineDirective
+		line.Indent, line.Key, line.Sep, line.Value = splitDirectiveLine(text)
+	}
+	return line
+}
+
+// isHeaderKey reports whether a keyword starts a Host or Match block
+func isHeaderKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "host" || key == "match"
+}
+
+// splitDirectiveLine splits a config line into indentation, keyword, separator and value
+func splitDirectiveLine(line string) (indent, key, sep, value string) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent = line[:len(line)-len(trimmed)]
+
+	end := strings.IndexAny(trimmed, " \t=")
+	if end == -1 {
+		return indent, strings.TrimRight(trimmed, " \t"), "", ""
+	}
+	key = trimmed[:end]
+	rest := trimmed[end:]
+	value = strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(value, "=") {
+		// Only a single '=' belongs to the separator
+		value = strings.TrimLeft(value[1:], " \t")
+	}
+	sep = rest[:len(rest)-len(value)]
+	value = strings.TrimRight(value, " \t")
+	return indent, key, sep, value
+}
+
+// Text returns the line as it will be written, without its line ending
+func (l *ConfigLine) Text() string {
+	if !l.modified {
+		return l.raw
+	}
+	return l.Indent + l.Key + l.Sep + l.Value
+}
+
+// Comment returns the text of a comment line without the leading '#' characters
+func (l *ConfigLine) Comment() string {
+	if l.Kind != LineComment {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l.Text()), "#"))
+}
+
+// SetValue changes a directive's value, keeping its indentation, keyword and separator
+func (l *ConfigLine) SetValue(value string) {
+	if l.Value == value {
+		return
+	}
+	if l.Sep == "" {
+		l.Sep = " "
+	}
+	l.Value = value
+	l.modified = true
+}
+
+// String serializes the config; an unmodified file is reproduced byte for byte
+func (c *ConfigFile) String() string {
+	lines := c.Lines()
+
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(line.Text())
+		eol := line.eol
+		if eol == "" && i < len(lines)-1 {
+			eol = c.newline
+		}
+		b.WriteString(eol)
+	}
+	return b.String()
+}
+
+// Save writes the config to path, keeping the previous version as path+".bak"
+func (c *ConfigFile) Save(path string) error {
+	return ReplaceFile(path, c.String())
+}
+
+// Lines returns every line of the file in order
+func (c *ConfigFile) Lines() []*ConfigLine {
+	lines := append([]*ConfigLine(nil), c.Global.Lines...)
+	for _, block := range c.Blocks {
+		lines = append(lines, block.Leading...)
+		lines = append(lines, block.Header)
+		lines = append(lines, block.Lines...)
+	}
+	return lines
+}
+
+// indent returns the indentation used by the first indented directive, or defaultIndent
+func (c *ConfigFile) indent() string {
+	for _, line := range c.Lines() {
+		if line.Kind == LineDirective && line.Indent != "" {
+			return line.Indent
+		}
+	}
+	return defaultIndent
+}
+
+// newLine creates a line that will be rendered from its parts
+func (c *ConfigFile) newLine(kind LineKind, indent, key, value string) *ConfigLine {
+	line := &ConfigLine{Kind: kind, Indent: indent, Key: key, Value: value, eol: c.newline, modified: true}
+	if kind == LineDirective {
+		line.Sep = " "
+	}
+	return line
+}
+
+// HostBlocks returns the file's Host blocks (Match blocks are skipped)
+func (c *ConfigFile) HostBlocks() []*ConfigBlock {
+	var blocks []*ConfigBlock
+	for _, block := range c.Blocks {
+		if block.IsHost() {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// FindHost returns the first Host block listing name among its patterns, or nil
+func (c *ConfigFile) FindHost(name string) *ConfigBlock {
+	for _, block := range c.HostBlocks() {
+		for _, pattern := range block.Patterns() {
+			if pattern == name {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// BlockAtLine returns the block whose header was parsed from the given line, or nil
+func (c *ConfigFile) BlockAtLine(num int) *ConfigBlock {
+	for _, block := range c.Blocks {
+		if block.Header.Num == num {
+			return block
+		}
+	}
+	return nil
+}
+
+// AddHostBlock adds a new Host block and returns it. When the file ends with a catch-all
+// "Host *" block the new block is inserted before it, so the defaults do not take precedence.
+func (c *ConfigFile) AddHostBlock(patterns []string, directives []types.Directive) *ConfigBlock {
+	block := &ConfigBlock{file: c}
+	block.Header = c.newLine(LineDirective, "", "Host", strings.Join(patterns, " "))
+	indent := c.indent()
+	for _, d := range directives {
+		block.Lines = append(block.Lines, c.newLine(LineDirective, indent, d.Key, d.Value))
+	}
+
+	if n := len(c.Blocks); n > 0 && c.Blocks[n-1].IsHost() && c.Blocks[n-1].Header.Value == "*" {
+		block.Lines = append(block.Lines, c.newLine(LineBlank, "", "", ""))
+		c.Blocks = append(c.Blocks[:n-1], block, c.Blocks[n-1])
+		return block
+	}
+
+	// Separate the new block from existing content with a blank line
+	if lines := c.Lines(); len(lines) > 0 && lines[len(lines)-1].Kind != LineBlank {
+		prev := c.lastBlock()
+		prev.Lines = append(prev.Lines, c.newLine(LineBlank, "", "", ""))
+	}
+	c.Blocks = append(c.Blocks, block)
+	return block
+}
+
+// RemoveBlock removes a block together with the comments directly above it
+func (c *ConfigFile) RemoveBlock(block *ConfigBlock) {
+	for i, b := range c.Blocks {
+		if b != block {
+			continue
+		}
+		c.Blocks = append(c.Blocks[:i], c.Blocks[i+1:]...)
+
+		// Do not leave blank lines dangling at the end of the file
+		if i == len(c.Blocks) {
+			prev := c.Global
+			if i > 0 {
+				prev = c.Blocks[i-1]
+			}
+			for len(prev.Lines) > 0 && prev.Lines[len(prev.Lines)-1].Kind == LineBlank {
+				prev.Lines = prev.Lines[:len(prev.Lines)-1]
+			}
+		}
+		return
+	}
+}
+
+// IsHost reports whether the block is a Host block
+func (b *ConfigBlock) IsHost() bool {
+	return b.Header != nil && strings.EqualFold(b.Header.Key, "host")
+}
+
+// Patterns returns the names or patterns on the block's Host line
+func (b *ConfigBlock) Patterns() []string {
+	if b.Header == nil {
+		return nil
+	}
+	return strings.Fields(b.Header.Value)
+}
+
+// SetPatterns replaces the names or patterns on the block's Host line
+func (b *ConfigBlock) SetPatterns(patterns []string) {
+	b.Header.SetValue(strings.Join(patterns, " "))
+}
+
+// Notes returns the text of the comment lines directly above the block
+func (b *ConfigBlock) Notes() []string {
+	var notes []string
+	for _, line := range b.Leading {
+		if note := line.Comment(); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
+
+// AddNote adds a comment line directly above the block's Host line
+func (b *ConfigBlock) AddNote(text string) {
+	b.Leading = append(b.Leading, b.file.newLine(LineComment, "", "", "# "+text))
+}
+
+// Directives returns the block's directive lines in order
+func (b *ConfigBlock) Directives() []*ConfigLine {
+	var directives []*ConfigLine
+	for _, line := range b.Lines {
+		if line.Kind == LineDirective {
+			directives = append(directives, line)
+		}
+	}
+	return directives
+}
+
+// Get returns the value of the first directive with the given keyword (case-insensitive)
+func (b *ConfigBlock) Get(key string) string {
+	for _, line := range b.Directives() {
+		if strings.EqualFold(line.Key, key) {
+			return line.Value
+		}
+	}
+	return ""
+}
+
+// Set changes the first directive with the given keyword, adding it if it is missing
+func (b *ConfigBlock) Set(key, value string) {
+	for _, line := range b.Directives() {
+		if strings.EqualFold(line.Key, key) {
+			line.SetValue(value)
+			return
+		}
+	}
+	b.Add(key, value)
+}
+
+// Add appends a directive after the block's last directive, matching its indentation
+func (b *ConfigBlock) Add(key, value string) *ConfigLine {
+	indent := ""
+	insertAt := 0
+	for i, line := range b.Lines {
+		if line.Kind == LineDirective {
+			if indent == "" {
+				indent = line.Indent
+			}
+			insertAt = i + 1
+		}
+	}
+	if indent == "" && b.Header != nil {
+		indent = b.file.indent()
+	}
+
+	line := b.file.newLine(LineDirective, indent, key, value)
+	b.Lines = append(b.Lines[:insertAt], append([]*ConfigLine{line}, b.Lines[insertAt:]...)...)
+	return line
+}
+
+// Remove deletes every directive with the given keyword (case-insensitive)
+func (b *ConfigBlock) Remove(key string) {
+	kept := b.Lines[:0]
+	for _, line := range b.Lines {
+		if line.Kind == LineDirective && strings.EqualFold(line.Key, key) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	b.Lines = kept
+}
+
+// SetDirectives makes the block hold exactly the given directives. Existing lines whose
+// keyword is still wanted are updated in place (keeping order, indentation and spelling),
+// lines no longer wanted are removed, and the rest are added after the last directive.
+func (b *ConfigBlock) SetDirectives(directives []types.Directive) {
+	// Queue the wanted values per keyword so repeated directives (e.g. IdentityFile) keep their order
+	wanted := make(map[string][]string)
+	for _, d := range directives {
+		key := strings.ToLower(d.Key)
+		wanted[key] = append(wanted[key], d.Value)
+	}
+
+	kept := make([]*ConfigLine, 0, len(b.Lines))
+	for _, line := range b.Lines {
+		if line.Kind != LineDirective {
+			kept = append(kept, line)
+			continue
+		}
+		key := strings.ToLower(line.Key)
+		values := wanted[key]
+		if len(values) == 0 {
+			continue
+		}
+		wanted[key] = values[1:]
+		line.SetValue(values[0])
+		kept = append(kept, line)
+	}
+	b.Lines = kept
+
+	for _, d := range directives {
+		key := strings.ToLower(d.Key)
+		values := wanted[key]
+		if len(values) == 0 {
+			continue
+		}
+		wanted[key] = values[1:]
+		b.Add(d.Key, values[0])
+	}
+}
+
+// lastBlock returns the block that ends the file
+func (c *ConfigFile) lastBlock() *ConfigBlock {
+	if len(c.Blocks) == 0 {
+		return c.Global
+	}
+	return c.Blocks[len(c.Blocks)-1]
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestConfigRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "config", "*.conf"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no round-trip fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		original, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		cfg, err := ParseConfig(strings.NewReader(string(original)))
+		if err != nil {
+			t.Fatalf("ParseConfig(%s) failed: %v", file, err)
+		}
+		if got := cfg.String(); got != string(original) {
+			t.Fatalf("round trip of %s is not byte-identical:\ngot:  %q\nwant: %q", file, got, original)
+		}
+	}
+}
+
+func TestConfigEdits_Golden(t *testing.T) {
+	cfg, err := LoadConfigFile(filepath.Join("testdata", "config", "edit.conf"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+
+	db := cfg.FindHost("pg")
+	if db == nil {
+		t.Fatalf("expected to find host by alias")
+	}
+	db.Set("User", "admin")
+	db.Remove("IdentityFile")
+	db.Add("Port", "5432")
+	db.SetPatterns([]string{"db", "postgres"})
+
+	cfg.RemoveBlock(cfg.FindHost("old"))
+
+	web := cfg.FindHost("web")
+	web.SetDirectives([]types.Directive{
+		{Key: "HostName", Value: "www.example.com"},
+		{Key: "LocalForward", Value: "8080 localhost:80"},
+		{Key: "LocalForward", Value: "8443 localhost:443"},
+	})
+
+	cfg.AddHostBlock([]string{"cache"}, []types.Directive{{Key: "HostName", Value: "cache.example.com"}})
+
+	golden := filepath.Join("testdata", "config", "edit.golden")
+	got := cfg.String()
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("edited config does not match %s:\n%s", golden, got)
+	}
+}
+
+func TestConfigFile_Structure(t *testing.T) {
+	cfg, err := LoadConfigFile(filepath.Join("testdata", "config", "comments.conf"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+
+	if len(cfg.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(cfg.Blocks))
+	}
+	pg := cfg.Blocks[0]
+	if pg.Header.Num != 7 || !reflect.DeepEqual(pg.Patterns(), []string{"pg"}) {
+		t.Fatalf("unexpected pg header: line %d patterns %v", pg.Header.Num, pg.Patterns())
+	}
+	if !reflect.DeepEqual(pg.Notes(), []string{"indented comment before a host", "@tags prod,db  @desc Primary Postgres"}) {
+		t.Fatalf("unexpected notes: %q", pg.Notes())
+	}
+	if pg.Get("hostname") != "pg.internal" || pg.Get("Port") != "" {
+		t.Fatalf("unexpected directive lookup results")
+	}
+	if cfg.BlockAtLine(16) != cfg.Blocks[1] || cfg.BlockAtLine(8) != nil {
+		t.Fatalf("BlockAtLine did not resolve header lines")
+	}
+	if got := cfg.Global.Get("AddKeysToAgent"); got != "yes" {
+		t.Fatalf("unexpected global directive: %q", got)
+	}
+	if len(cfg.Blocks[1].Notes()) != 0 {
+		t.Fatalf("comment separated by a blank line should not attach to Host *")
+	}
+}
+
+func TestSplitDirectiveLine(t *testing.T) {
+	cases := []struct {
+		in                      string
+		indent, key, sep, value string
+	}{
+		{"Host web", "", "Host", " ", "web"},
+		{"  HostName=eq.example.com", "  ", "HostName", "=", "eq.example.com"},
+		{"\tUser = alice  ", "\t", "User", " = ", "alice"},
+		{"Port  =2222", "", "Port", "  =", "2222"},
+		{"Compression", "", "Compression", "", ""},
+		{"SetEnv A==b", "", "SetEnv", " ", "A==b"},
+	}
+
+	for _, c := range cases {
+		indent, key, sep, value := splitDirectiveLine(c.in)
+		if indent != c.indent || key != c.key || sep != c.sep || value != c.value {
+			t.Fatalf("splitDirectiveLine(%q) = (%q,%q,%q,%q)", c.in, indent, key, sep, value)
+		}
+	}
+}
+
+func TestParseSSHConfig_EqualsForm(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "config", "equals.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := parseSSHConfig(strings.NewReader(string(data)), "equals.conf")
+	if err != nil {
+		t.Fatalf("parseSSHConfig failed: %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].HostName != "eq.example.com" || hosts[0].User != "alice" || hosts[0].Port != "2222" {
+		t.Fatalf("unexpected hosts from Key=Value config: %+v", hosts)
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoadedConfig is a config file read while following Include directives
+type LoadedConfig struct {
+	Path string
+	File *ConfigFile
+	// Includes maps the line number of each Include directive to the files it read, as
+	// indices into the tree
+	Includes map[int][]int
+}
+
+// LoadConfigTree reads the config file at path and every file it includes; the file at path
+// comes first. Include patterns that match nothing, unreadable files and includes nested too
+// deeply are returned as diagnostics; ssh skips them the same way. A missing top-level file
+// yields an empty tree.
+func LoadConfigTree(path string) ([]LoadedConfig, []Diagnostic, error) {
+	file, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var t configTree
+	t.add(path, file, 0)
+	return t.files, t.diagnostics, nil
+}
+
+// configTree accumulates the files and diagnostics of LoadConfigTree
+type configTree struct {
+	files       []LoadedConfig
+	diagnostics []Diagnostic
+}
+
+// add records a file and follows its Include directives, returning the file's index
+func (t *configTree) add(path string, file *ConfigFile, depth int) int {
+	index := len(t.files)
+	t.files = append(t.files, LoadedConfig{Path: path, File: file, Includes: make(map[int][]int)})
+	for _, line := range file.Lines() {
+		if line.Kind == LineDirective && strings.EqualFold(line.Key, "include") {
+			t.files[index].Includes[line.Num] = t.include(path, line, depth)
+		}
+	}
+	return index
+}
+
+// include follows the patterns of a single Include line and returns the indices of the files read
+func (t *configTree) include(path string, line *ConfigLine, depth int) []int {
+	warn := func(format string, args ...any) {
+		t.diagnostics = append(t.diagnostics, Diagnostic{File: path, Line: line.Num, Severity: SeverityWarning,
+			Rule: RuleInclude, Message: fmt.Sprintf(format, args...)})
+	}
+	if depth+1 > maxIncludeDepth {
+		warn("Include nested more than %d levels deep is ignored", maxIncludeDepth)
+		return nil
+	}
+
+	var included []int
+	for _, pattern := range strings.Fields(line.Value) {
+		matches, err := ResolveInclude(pattern)
+		if err != nil {
+			warn("Include %q: %v", pattern, err)
+			continue
+		}
+		if len(matches) == 0 {
+			warn("Include %q matches no files", pattern)
+			continue
+		}
+		for _, match := range matches {
+			file, err := LoadConfigFile(match)
+			if err != nil {
+				warn("Include %q: %v", pattern, err)
+				continue
+			}
+			included = append(included, t.add(match, file, depth+1))
+		}
+	}
+	return included
+}
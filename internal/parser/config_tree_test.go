@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigTree(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(filepath.Join(sshDir, "config.d"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(sshDir, "config")
+	files := map[string]string{
+		configPath:                             "Include config.d/*\nInclude missing/*.conf\nHost web\n  HostName web.example.com\n  User\n",
+		filepath.Join(sshDir, "config.d", "a"): "Host \nHost db\n  HostName bad_host!\n  HostName %h.internal\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree, warnings, err := LoadConfigTree(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || tree[0].Path != configPath || tree[1].Path != filepath.Join(sshDir, "config.d", "a") {
+		t.Fatalf("unexpected files: %+v", tree)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 2 || !strings.Contains(warnings[0].Message, "matches no files") {
+		t.Fatalf("unexpected include warnings: %v", warnings)
+	}
+
+	if included := tree[0].Includes[1]; len(included) != 1 || included[0] != 1 {
+		t.Errorf("expected line 1 to include the second file, got %v", tree[0].Includes)
+	}
+	if included, ok := tree[0].Includes[2]; !ok || len(included) != 0 {
+		t.Errorf("expected line 2 to include nothing, got %v", tree[0].Includes)
+	}
+}
+
+func TestLoadConfigTree_IncludeLoop(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, "loop")
+	if err := os.WriteFile(configPath, []byte("Include "+configPath+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, warnings, err := LoadConfigTree(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != maxIncludeDepth+1 || len(warnings) != 1 || !strings.Contains(warnings[0].Message, "levels deep") {
+		t.Fatalf("expected the loop to stop at the include limit, got %d files and %v", len(tree), warnings)
+	}
+}
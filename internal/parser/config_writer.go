@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// HostBlock describes a Host block to be written to an SSH config file
+type HostBlock struct {
+	// Patterns holds the Host line's names: the primary name followed by any aliases
+	Patterns   []string
+	Directives []types.Directive
+	// Comments are written as comment lines above new blocks, e.g. "@tags prod"
+	Comments []string
+}
+
+// defaultIndent is used for new directives when the file has no indented lines to copy from
+const defaultIndent = "    "
+
+// AddHostBlock adds a new Host block to the config file at path, creating the file if needed
+func AddHostBlock(path string, block HostBlock) error {
+	return AddHostBlocks(path, []HostBlock{block})
+}
+
+// AddHostBlocks adds several new Host blocks to the config file at path in a single write
+func AddHostBlocks(path string, blocks []HostBlock) error {
+	for _, block := range blocks {
+		if err := validateHostBlock(block); err != nil {
+			return err
+		}
+	}
+
+	c, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		added := c.AddHostBlock(block.Patterns, block.Directives)
+		for _, comment := range block.Comments {
+			added.AddNote(comment)
+		}
+	}
+	return c.Save(path)
+}
+
+// UpdateHostBlock rewrites the Host block whose header is on the given line so that it holds
+// exactly the given patterns and directives, leaving the rest of the file untouched
+func UpdateHostBlock(path string, line int, block HostBlock) error {
+	if err := validateHostBlock(block); err != nil {
+		return err
+	}
+
+	c, target, err := loadHostBlock(path, line)
+	if err != nil {
+		return err
+	}
+	target.SetPatterns(block.Patterns)
+	target.SetDirectives(block.Directives)
+	return c.Save(path)
+}
+
+// AddDirective appends a directive to the Host block whose header is on the given line, after
+// its last directive. It returns false without writing when the block already has the same
+// directive with the same value.
+func AddDirective(path string, line int, d types.Directive) (bool, error) {
+	if err := validateDirective(d); err != nil {
+		return false, err
+	}
+
+	c, target, err := loadHostBlock(path, line)
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range target.Directives() {
+		if strings.EqualFold(existing.Key, d.Key) && existing.Value == d.Value {
+			return false, nil
+		}
+	}
+	target.Add(d.Key, d.Value)
+	return true, c.Save(path)
+}
+
+// DeleteHostBlock removes the Host block whose header is on the given line, together with
+// the comment lines directly above it
+func DeleteHostBlock(path string, line int) error {
+	c, target, err := loadHostBlock(path, line)
+	if err != nil {
+		return err
+	}
+	c.RemoveBlock(target)
+	return c.Save(path)
+}
+
+// loadHostBlock parses the config at path and finds the Host block whose header is on line
+func loadHostBlock(path string, line int) (*ConfigFile, *ConfigBlock, error) {
+	c, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := c.BlockAtLine(line)
+	if target == nil || !target.IsHost() {
+		return nil, nil, fmt.Errorf("no Host block at line %d; the config file may have changed", line)
+	}
+	return c, target, nil
+}
+
+// validateHostBlock rejects blocks that would produce a broken config file
+func validateHostBlock(block HostBlock) error {
+	if len(block.Patterns) == 0 {
+		return fmt.Errorf("host block needs a name")
+	}
+	for _, p := range block.Patterns {
+		if p == "" || strings.ContainsAny(p, " \t\r\n#") {
+			return fmt.Errorf("invalid host name: %q", p)
+		}
+	}
+	for _, c := range block.Comments {
+		if strings.ContainsAny(c, "\r\n") {
+			return fmt.Errorf("invalid comment: %q", c)
+		}
+	}
+	for _, d := range block.Directives {
+		if err := validateDirective(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDirective rejects directives that cannot be written inside a Host block
+func validateDirective(d types.Directive) error {
+	if d.Key == "" || strings.ContainsAny(d.Key, " \t\r\n=#") {
+		return fmt.Errorf("invalid directive keyword: %q", d.Key)
+	}
+	if isHeaderKey(d.Key) {
+		return fmt.Errorf("%s cannot be used as a directive inside a Host block", d.Key)
+	}
+	if strings.TrimSpace(d.Value) == "" || strings.ContainsAny(d.Value, "\r\n") {
+		return fmt.Errorf("invalid value for %s: %q", d.Key, d.Value)
+	}
+	return nil
+}
+
+// ReplaceFile replaces the file at path (e.g. an SSH config or known_hosts file) with content.
+// The previous version is kept as path+".bak" and the new content is written to a temporary
+// file that is renamed into place.
+func ReplaceFile(path, content string) error {
+	mode := os.FileMode(0o600)
+
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path+".bak", original, mode); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	} else if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+	} else {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ssh-tui/internal/types"
+)
+
+const writerFixture = `# Personal config
+Include config.d/*
+
+# Database
+Host db
+  HostName db.example.com
+  # keep this comment
+  User=postgres
+  IdentityFile ~/.ssh/a
+  IdentityFile ~/.ssh/b
+
+Host web
+  HostName web.example.com
+
+Host *
+  ServerAliveInterval 30
+`
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestAddHostBlock_BeforeCatchAll(t *testing.T) {
+	path := writeFixture(t, writerFixture)
+
+	block := HostBlock{
+		Patterns:   []string{"cache", "redis"},
+		Directives: []types.Directive{{Key: "HostName", Value: "cache.example.com"}, {Key: "User", Value: "ops"}},
+	}
+	if err := AddHostBlock(path, block); err != nil {
+		t.Fatalf("AddHostBlock failed: %v", err)
+	}
+
+	want := `# Personal config
+Include config.d/*
+
+# Database
+Host db
+  HostName db.example.com
+  # keep this comment
+  User=postgres
+  IdentityFile ~/.ssh/a
+  IdentityFile ~/.ssh/b
+
+Host web
+  HostName web.example.com
+
+Host cache redis
+  HostName cache.example.com
+  User ops
+
+Host *
+  ServerAliveInterval 30
+`
+	if got := readFile(t, path); got != want {
+		t.Fatalf("unexpected config after add:\n%s", got)
+	}
+	if got := readFile(t, path+".bak"); got != writerFixture {
+		t.Fatalf("backup does not match original:\n%s", got)
+	}
+}
+
+func TestAddHostBlock_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssh", "config")
+
+	block := HostBlock{Patterns: []string{"box"}, Directives: []types.Directive{{Key: "HostName", Value: "box.local"}}}
+	if err := AddHostBlock(path, block); err != nil {
+		t.Fatalf("AddHostBlock failed: %v", err)
+	}
+	if got := readFile(t, path); got != "Host box\n    HostName box.local\n" {
+		t.Fatalf("unexpected new config: %q", got)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup for a new file")
+	}
+}
+
+func TestUpdateHostBlock(t *testing.T) {
+	path := writeFixture(t, writerFixture)
+
+	block := HostBlock{
+		Patterns: []string{"db", "pg"},
+		Directives: []types.Directive{
+			{Key: "HostName", Value: "db.example.com"},
+			{Key: "User", Value: "admin"},
+			{Key: "IdentityFile", Value: "~/.ssh/b"},
+			{Key: "Port", Value: "5433"},
+		},
+	}
+	if err := UpdateHostBlock(path, 5, block); err != nil {
+		t.Fatalf("UpdateHostBlock failed: %v", err)
+	}
+
+	want := `# Personal config
+Include config.d/*
+
+# Database
+Host db pg
+  HostName db.example.com
+  # keep this comment
+  User=admin
+  IdentityFile ~/.ssh/b
+  Port 5433
+
+Host web
+  HostName web.example.com
+
+Host *
+  ServerAliveInterval 30
+`
+	if got := readFile(t, path); got != want {
+		t.Fatalf("unexpected config after update:\n%s", got)
+	}
+
+	if err := UpdateHostBlock(path, 2, block); err == nil {
+		t.Fatalf("expected error when line is not a Host header")
+	}
+}
+
+func TestAddDirective(t *testing.T) {
+	path := writeFixture(t, writerFixture)
+
+	added, err := AddDirective(path, 5, types.Directive{Key: "IdentityFile", Value: "~/.ssh/c"})
+	if err != nil || !added {
+		t.Fatalf("AddDirective failed: %v", err)
+	}
+	// The same directive is not added twice
+	if added, err := AddDirective(path, 5, types.Directive{Key: "identityfile", Value: "~/.ssh/c"}); err != nil || added {
+		t.Fatalf("expected the directive to be found, got %v, %v", added, err)
+	}
+
+	want := `# Personal config
+Include config.d/*
+
+# Database
+Host db
+  HostName db.example.com
+  # keep this comment
+  User=postgres
+  IdentityFile ~/.ssh/a
+  IdentityFile ~/.ssh/b
+  IdentityFile ~/.ssh/c
+
+Host web
+  HostName web.example.com
+
+Host *
+  ServerAliveInterval 30
+`
+	if got := readFile(t, path); got != want {
+		t.Fatalf("unexpected config after adding a directive:\n%s", got)
+	}
+
+	if _, err := AddDirective(path, 2, types.Directive{Key: "IdentityFile", Value: "~/.ssh/c"}); err == nil {
+		t.Fatalf("expected error when line is not a Host header")
+	}
+	if _, err := AddDirective(path, 5, types.Directive{Key: "Host", Value: "x"}); err == nil {
+		t.Fatalf("expected error for a header keyword")
+	}
+}
+
+func TestDeleteHostBlock(t *testing.T) {
+	path := writeFixture(t, writerFixture)
+
+	if err := DeleteHostBlock(path, 5); err != nil {
+		t.Fatalf("DeleteHostBlock failed: %v", err)
+	}
+
+	want := `# Personal config
+Include config.d/*
+
+Host web
+  HostName web.example.com
+
+Host *
+  ServerAliveInterval 30
+`
+	if got := readFile(t, path); got != want {
+		t.Fatalf("unexpected config after delete:\n%s", got)
+	}
+}
+
+func TestWriterPreservesCRLF(t *testing.T) {
+	path := writeFixture(t, "Host a\r\n\tHostName a.example.com\r\n")
+
+	block := HostBlock{Patterns: []string{"a"}, Directives: []types.Directive{{Key: "HostName", Value: "b.example.com"}}}
+	if err := UpdateHostBlock(path, 1, block); err != nil {
+		t.Fatalf("UpdateHostBlock failed: %v", err)
+	}
+	if got := readFile(t, path); got != "Host a\r\n\tHostName b.example.com\r\n" {
+		t.Fatalf("unexpected CRLF config: %q", got)
+	}
+}
+
+func TestParseSSHConfig_Include(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(filepath.Join(sshDir, "config.d"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sshDir, "config.d", "work"), []byte("Host work\n  HostName work.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sshDir, "config"), []byte("Include config.d/*\nHost home\n  HostName home.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ParseSSHConfig()
+	if err != nil {
+		t.Fatalf("ParseSSHConfig failed: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "work" || hosts[1].Name != "home" {
+		t.Fatalf("unexpected hosts: %+v", hosts)
+	}
+	if hosts[0].SourceFile != filepath.Join(sshDir, "config.d", "work") || hosts[0].SourceLine != 1 {
+		t.Fatalf("unexpected source for included host: %s:%d", hosts[0].SourceFile, hosts[0].SourceLine)
+	}
+}
@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"ssh-tui/internal/types"
 )
@@ -26,50 +29,205 @@ func matchesTerm(searchTerm string, candidates []string) int {
 	return 0 // no match
 }
 
-// DiscoverHosts discovers all SSH hosts from both config and known_hosts files
-func DiscoverHosts() ([]types.SSHHost, error) {
-	var allHosts []types.SSHHost
+// DiscoverHosts runs the given sources and returns their merged hosts. A failing source does
+// not stop discovery: the hosts found are returned together with the errors of failed sources.
+func DiscoverHosts(ctx context.Context, sources []HostSource) ([]types.SSHHost, error) {
+	results := make(map[string]Batch, len(sources))
+	for batch := range StreamHosts(ctx, sources) {
+		results[batch.Source] = batch
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	configHosts, err := ParseSSHConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse SSH config: %w", err)
+	lists := make([][]types.SSHHost, 0, len(sources))
+	var errs []error
+	for _, source := range sources {
+		batch := results[source.Name()]
+		lists = append(lists, batch.Hosts)
+		if batch.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), batch.Err))
+		}
 	}
+	return MergeHosts(lists...), errors.Join(errs...)
+}
+
+// Batch is the result of a single discovery source
+type Batch struct {
+	Source string
+	Hosts  []types.SSHHost
+	Err    error
+}
 
-	knownHosts, err := ParseKnownHosts()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse known_hosts: %w", err)
+// StreamHosts runs the given sources concurrently and sends each source's result on the
+// returned channel as soon as it is ready. The channel is closed once every source has finished.
+// Receivers should merge batches in the order of sources (see MergeHosts), not arrival order.
+func StreamHosts(ctx context.Context, sources []HostSource) <-chan Batch {
+	// Buffered so producers never block, even if the receiver has gone away
+	out := make(chan Batch, len(sources))
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source HostSource) {
+			defer wg.Done()
+			hosts, err := source.Discover(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			out <- Batch{Source: source.Name(), Hosts: hosts, Err: err}
+		}(source)
 	}
 
-	// Merge hosts with deduplication while preserving config order
-	hostMap := make(map[string]bool)
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// MergeHosts merges host lists given in priority order (highest first). The rules are:
+//
+//   - Hosts are identified by name, case-insensitively; the merged list keeps the position
+//     and Source of the first (highest priority) host with each name.
+//   - When sources disagree on HostName, User or Port, the higher priority value wins.
+//     Empty values are filled in from lower priority sources, except on SSH config hosts:
+//     ssh applies its own config, so borrowed values would only misrepresent the connection.
+//   - Aliases and Tags are combined; Description is filled in if empty.
+//   - Keys from known_hosts entries are attached to the other hosts they belong to.
+//
+// The input slices are not modified.
+func MergeHosts(sources ...[]types.SSHHost) []types.SSHHost {
+	var allHosts []types.SSHHost
+	var knownHosts []types.SSHHost
+	index := make(map[string]int)
 
-	// Add config hosts first (preserving their original order)
-	for _, host := range configHosts {
-		key := strings.ToLower(host.Name)
-		if !hostMap[key] {
+	for _, hosts := range sources {
+		for _, host := range hosts {
+			if host.Source == types.SourceKnownHosts {
+				knownHosts = append(knownHosts, host)
+			}
+
+			key := strings.ToLower(host.Name)
+			if i, ok := index[key]; ok {
+				mergeHost(&allHosts[i], host)
+				continue
+			}
+			index[key] = len(allHosts)
 			allHosts = append(allHosts, host)
-			hostMap[key] = true
 		}
 	}
 
-	// Add known_hosts entries that aren't already in config
-	for _, host := range knownHosts {
-		key := strings.ToLower(host.Name)
-		if !hostMap[key] {
-			allHosts = append(allHosts, host)
-			hostMap[key] = true
+	attachHostKeys(allHosts, knownHosts)
+
+	return allHosts
+}
+
+// mergeHost folds a lower priority duplicate into dst following the MergeHosts rules
+func mergeHost(dst *types.SSHHost, src types.SSHHost) {
+	if dst.Source != types.SourceConfig {
+		if dst.HostName == "" {
+			dst.HostName = src.HostName
+		}
+		if dst.User == "" {
+			dst.User = src.User
+		}
+		if dst.Port == "" {
+			dst.Port = src.Port
+		}
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	// Copy before appending so the merged host never shares a backing array with its source
+	dst.Aliases = appendMissing(append([]string(nil), dst.Aliases...), src.Aliases)
+	dst.Tags = appendMissing(append([]string(nil), dst.Tags...), src.Tags)
+}
+
+// appendMissing appends the values of extra not already in list (case-insensitively)
+func appendMissing(list, extra []string) []string {
+	for _, v := range extra {
+		if !containsFold(list, v) {
+			list = append(list, v)
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// attachHostKeys copies known_hosts keys onto the non-known_hosts hosts they belong to,
+// matching a known_hosts entry against the host's HostName or Name
+func attachHostKeys(hosts, knownHosts []types.SSHHost) {
+	keysByHost := make(map[string][]types.HostKey)
+	for _, kh := range knownHosts {
+		key := strings.ToLower(kh.Name)
+		keysByHost[key] = append(keysByHost[key], kh.HostKeys...)
+	}
+
+	for i := range hosts {
+		host := &hosts[i]
+		if host.Source == types.SourceKnownHosts {
+			continue
+		}
+		keys, ok := keysByHost[strings.ToLower(host.HostName)]
+		if !ok || host.HostName == "" {
+			keys, ok = keysByHost[strings.ToLower(host.Name)]
+		}
+		if ok {
+			// Copy so the merged host never shares a backing array with its source
+			host.HostKeys = append(append([]types.HostKey(nil), host.HostKeys...), keys...)
 		}
 	}
+}
 
-	return allHosts, nil
+// tagFilterPrefix marks a search token that restricts results to hosts with a tag, e.g. "tag:prod"
+const tagFilterPrefix = "tag:"
+
+// parseSearchQuery splits a search query into required tags and the remaining search term
+func parseSearchQuery(query string) (tags []string, term string) {
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(strings.ToLower(word), tagFilterPrefix) && len(word) > len(tagFilterPrefix) {
+			tags = append(tags, word[len(tagFilterPrefix):])
+			continue
+		}
+		words = append(words, word)
+	}
+	return tags, strings.Join(words, " ")
 }
 
-// FilterHosts filters hosts by a search term
+// hasTags reports whether host carries every one of the given tags (case-insensitive)
+func hasTags(host types.SSHHost, tags []string) bool {
+	for _, tag := range tags {
+		if !containsFold(host.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterHosts filters hosts by a search term. Tokens of the form "tag:NAME" restrict the
+// results to hosts carrying that tag; the rest of the query is matched against the hosts.
 func FilterHosts(hosts []types.SSHHost, searchTerm string) []types.SSHHost {
 	if searchTerm == "" {
 		return hosts
 	}
 
+	requiredTags, searchTerm := parseSearchQuery(searchTerm)
+	if len(requiredTags) > 0 {
+		var tagged []types.SSHHost
+		for _, host := range hosts {
+			if hasTags(host, requiredTags) {
+				tagged = append(tagged, host)
+			}
+		}
+		if searchTerm == "" {
+			return tagged
+		}
+		hosts = tagged
+	}
+
 	var filtered []types.SSHHost
 
 	// Prioritization strategy with aliases:
@@ -78,6 +236,7 @@ func FilterHosts(hosts []types.SSHHost, searchTerm string) []types.SSHHost {
 	// 3) Primary name/hostname substring matches
 	// 4) Alias prefix matches
 	// 5) Alias substring matches
+	// 6) Tag or description matches
 	// Within each bucket, preserve input order.
 
 	var exactAliasMatches []types.SSHHost
@@ -85,6 +244,7 @@ func FilterHosts(hosts []types.SSHHost, searchTerm string) []types.SSHHost {
 	var primaryContains []types.SSHHost
 	var aliasPrefix []types.SSHHost
 	var aliasContains []types.SSHHost
+	var annotationMatches []types.SSHHost
 
 	for _, host := range hosts {
 		// Check exact alias match first
@@ -111,6 +271,13 @@ func FilterHosts(hosts []types.SSHHost, searchTerm string) []types.SSHHost {
 			} else {
 				aliasContains = append(aliasContains, host)
 			}
+			continue
+		}
+
+		// Tags and description
+		annotationCandidates := append([]string{host.Description}, host.Tags...)
+		if match := matchesTerm(searchTerm, annotationCandidates); match > 0 {
+			annotationMatches = append(annotationMatches, host)
 		}
 	}
 
@@ -120,6 +287,7 @@ func FilterHosts(hosts []types.SSHHost, searchTerm string) []types.SSHHost {
 	filtered = append(filtered, primaryContains...)
 	filtered = append(filtered, aliasPrefix...)
 	filtered = append(filtered, aliasContains...)
+	filtered = append(filtered, annotationMatches...)
 
 	return filtered
 }
@@ -148,5 +316,9 @@ func FormatHostDisplay(host types.SSHHost) string {
 		lines = append(lines, strings.Join(details, " • "))
 	}
 
+	if host.Description != "" {
+		lines = append(lines, host.Description)
+	}
+
 	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+func TestMergeHosts(t *testing.T) {
+	config := []types.SSHHost{
+		{Name: "web", HostName: "web.example.com", Source: types.SourceConfig},
+	}
+	known := []types.SSHHost{
+		{Name: "WEB", Source: types.SourceKnownHosts},
+		{Name: "web.example.com", Source: types.SourceKnownHosts, HostKeys: []types.HostKey{{Type: "ssh-ed25519"}}},
+	}
+
+	merged := MergeHosts(config, known)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(merged))
+	}
+	if merged[0].Source != types.SourceConfig || len(merged[0].HostKeys) != 1 {
+		t.Errorf("expected config host first with its known_hosts key, got %+v", merged[0])
+	}
+	if len(config[0].HostKeys) != 0 {
+		t.Errorf("MergeHosts modified its input")
+	}
+}
+
+func TestStreamHosts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sshDir, "config"), []byte("Host web\n    HostName web.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]Batch)
+	for batch := range StreamHosts(context.Background(), EnabledSources(nil)) {
+		seen[batch.Source] = batch
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected one batch per source, got %v", seen)
+	}
+	if hosts := seen[types.SourceConfig].Hosts; len(hosts) != 1 || hosts[0].Name != "web" {
+		t.Errorf("unexpected config batch: %+v", seen[types.SourceConfig])
+	}
+}
+
+func TestMergeHosts_Conflicts(t *testing.T) {
+	inventory := []types.SSHHost{
+		{Name: "app", HostName: "10.0.0.5", User: "deploy", Source: "inventory", Tags: []string{"web"}},
+		{Name: "db", Source: "inventory", Tags: []string{"db"}},
+	}
+	other := []types.SSHHost{
+		{Name: "APP", HostName: "10.0.0.6", User: "root", Port: "2222", Source: "other", Tags: []string{"prod", "WEB"}},
+		{Name: "db", HostName: "db.internal", User: "postgres", Source: "other"},
+	}
+	config := []types.SSHHost{
+		{Name: "db", Source: types.SourceConfig},
+	}
+
+	merged := MergeHosts(inventory, other)
+	app := merged[0]
+	if app.Source != "inventory" || app.HostName != "10.0.0.5" || app.User != "deploy" {
+		t.Errorf("expected higher priority values to win, got %+v", app)
+	}
+	if app.Port != "2222" {
+		t.Errorf("expected empty port to be filled from the lower priority source, got %q", app.Port)
+	}
+	if len(app.Tags) != 2 || app.Tags[1] != "prod" {
+		t.Errorf("expected tags to be combined without duplicates, got %v", app.Tags)
+	}
+	if len(inventory[0].Tags) != 1 {
+		t.Errorf("MergeHosts modified its input")
+	}
+
+	merged = MergeHosts(config, other)
+	if db := merged[0]; db.HostName != "" || db.User != "" {
+		t.Errorf("expected config host connection fields to stay untouched, got %+v", db)
+	}
+}
+
+func TestEnabledSources(t *testing.T) {
+	if names := SourceNames(EnabledSources(nil)); len(names) != 2 || names[0] != types.SourceConfig || names[1] != types.SourceKnownHosts {
+		t.Fatalf("unexpected default sources: %v", names)
+	}
+
+	disableKnownHosts := func(name string, defaultEnabled bool) bool {
+		return defaultEnabled && name != types.SourceKnownHosts
+	}
+	if names := SourceNames(EnabledSources(disableKnownHosts)); len(names) != 1 || names[0] != types.SourceConfig {
+		t.Fatalf("expected only config to be enabled, got %v", names)
+	}
+}
+
+func TestFileSource_Watch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := ConfigSource().(Watcher).Watch(ctx)
+
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte("Include extra.conf\nHost web\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a change notification after creating the config")
+	}
+
+	// Files pulled in by Include are watched too
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "extra.conf"), []byte("Host db\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a change notification after creating the included file")
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchSources(t *testing.T) {
+	if WatchSources(context.Background(), []HostSource{staticSource{name: "static"}}) != nil {
+		t.Fatalf("expected no channel without watching sources")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := WatchSources(ctx, []HostSource{staticSource{name: "static"}, KnownHostsSource()})
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte("web ssh-ed25519 AAAA\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a change notification after creating known_hosts")
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+// staticSource is a HostSource returning fixed results
+type staticSource struct {
+	name  string
+	hosts []types.SSHHost
+	err   error
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) Discover(context.Context) ([]types.SSHHost, error) { return s.hosts, s.err }
+
+func TestDiscoverHosts(t *testing.T) {
+	sources := []HostSource{
+		staticSource{name: "first", hosts: []types.SSHHost{{Name: "web", User: "a", Source: "first"}}},
+		staticSource{name: "broken", err: errors.New("boom")},
+		staticSource{name: "second", hosts: []types.SSHHost{{Name: "web", User: "b", Source: "second"}, {Name: "db", Source: "second"}}},
+	}
+
+	hosts, err := DiscoverHosts(context.Background(), sources)
+	if err == nil || !strings.Contains(err.Error(), "broken: boom") {
+		t.Fatalf("expected the failing source to be reported, got %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].User != "a" || hosts[1].Name != "db" {
+		t.Fatalf("expected hosts merged in source order, got %+v", hosts)
+	}
+}
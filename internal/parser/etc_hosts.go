@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// SourceEtcHosts is the name of the hosts file source
+const SourceEtcHosts = "hosts"
+
+// DefaultHostsFilePath returns the system hosts file for the current platform
+func DefaultHostsFilePath() string {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// etcHostsSource is a HostSource reading hosts files
+type etcHostsSource struct {
+	paths []string
+}
+
+// NewEtcHostsSource returns a source reading the given hosts files, or the system hosts file
+// when paths is empty
+func NewEtcHostsSource(paths []string) HostSource {
+	if len(paths) == 0 {
+		paths = []string{DefaultHostsFilePath()}
+	}
+	return &etcHostsSource{paths: paths}
+}
+
+func (s *etcHostsSource) Name() string {
+	return SourceEtcHosts
+}
+
+func (s *etcHostsSource) Discover(ctx context.Context) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	var errs []error
+	for _, path := range s.paths {
+		if err := ctx.Err(); err != nil {
+			return hosts, err
+		}
+		fileHosts, err := ParseHostsFile(path)
+		hosts = append(hosts, fileHosts...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return hosts, errors.Join(errs...)
+}
+
+// ParseHostsFile parses a hosts file such as /etc/hosts
+func ParseHostsFile(path string) ([]types.SSHHost, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseHostsFile(file, path)
+}
+
+// parseHostsFile parses hosts file content read from r; path is recorded as the hosts' source file.
+// Each line's first name becomes the host and the other names its aliases; the address is shown
+// as the description, while connections go by name so ssh resolves it as usual.
+// Loopback, multicast and unspecified addresses are skipped, as are localhost names.
+func parseHostsFile(r io.Reader, path string) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+	hostIndex := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		// Strip an IPv6 zone such as fe80::1%eth0 before parsing
+		address, _, _ := strings.Cut(fields[0], "%")
+		ip := net.ParseIP(address)
+		if ip == nil || !usableHostsAddress(ip) {
+			continue
+		}
+
+		var names []string
+		for _, name := range fields[1:] {
+			if isLocalhostName(name) || ValidateHostEntry(types.SSHHost{Name: name}) != nil {
+				continue
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		key := strings.ToLower(names[0])
+		if idx, ok := hostIndex[key]; ok {
+			// The same name on another line, typically its IPv6 address
+			hosts[idx].Aliases = appendMissing(hosts[idx].Aliases, names[1:])
+			continue
+		}
+		hostIndex[key] = len(hosts)
+
+		hosts = append(hosts, types.SSHHost{
+			Name:        names[0],
+			Aliases:     appendMissing(nil, names[1:]),
+			Source:      SourceEtcHosts,
+			SourceFile:  path,
+			SourceLine:  lineNum,
+			Description: fields[0],
+		})
+	}
+
+	return hosts, scanner.Err()
+}
+
+// usableHostsAddress reports whether ip could be an ssh target worth listing
+func usableHostsAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.IsMulticast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast()
+}
+
+// isLocalhostName reports whether name is one of the conventional names for the local machine
+func isLocalhostName(name string) bool {
+	name = strings.ToLower(name)
+	return name == "localhost" || strings.HasPrefix(name, "localhost.") ||
+		strings.HasPrefix(name, "ip6-") || name == "broadcasthost"
+}
@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHostsFile(t *testing.T) {
+	content := `# Static table lookup for hostnames
+127.0.0.1	localhost
+127.0.1.1	workstation
+::1		localhost ip6-localhost ip6-loopback
+ff02::1		ip6-allnodes
+0.0.0.0		blocked.example.com
+
+10.0.0.5	lab1.example.com lab1   # rack 3
+10.0.0.6	lab2
+fd00::5		lab1.example.com lab1-v6
+192.168.1.9	bad_name!
+`
+	hosts, err := parseHostsFile(strings.NewReader(content), "/etc/hosts")
+	if err != nil {
+		t.Fatalf("parseHostsFile failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %+v", hosts)
+	}
+
+	lab1 := hosts[0]
+	if lab1.Name != "lab1.example.com" || lab1.Description != "10.0.0.5" || lab1.SourceLine != 8 {
+		t.Errorf("unexpected lab1: %+v", lab1)
+	}
+	if !reflect.DeepEqual(lab1.Aliases, []string{"lab1", "lab1-v6"}) {
+		t.Errorf("expected aliases from both lines, got %v", lab1.Aliases)
+	}
+	if hosts[1].Name != "lab2" || hosts[1].Source != SourceEtcHosts || hosts[1].SourceFile != "/etc/hosts" {
+		t.Errorf("unexpected lab2: %+v", hosts[1])
+	}
+}
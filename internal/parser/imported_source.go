@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// SourceImported is the name of the source holding hosts imported from other SSH clients
+const SourceImported = "imported"
+
+// importedFileExt is the extension of the files the imported source reads
+const importedFileExt = ".jsonl"
+
+// importedSource is a read-only HostSource reading the host files written by "ssh-tui import"
+type importedSource struct {
+	dir string
+}
+
+// NewImportedSource returns a source reading every JSON lines host file in dir
+func NewImportedSource(dir string) HostSource {
+	return &importedSource{dir: dir}
+}
+
+func (s *importedSource) Name() string {
+	return SourceImported
+}
+
+func (s *importedSource) Discover(ctx context.Context) ([]types.SSHHost, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), importedFileExt) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var hosts []types.SSHHost
+	var errs []error
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return hosts, err
+		}
+		path := filepath.Join(s.dir, name)
+		fileHosts, err := readHostLines(path)
+		hosts = append(hosts, fileHosts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return hosts, errors.Join(errs...)
+}
+
+// ImportedFilePath returns the file in dir that holds the hosts imported under name
+func ImportedFilePath(dir, name string) string {
+	return filepath.Join(dir, name+importedFileExt)
+}
+
+// readHostLines reads a JSON lines host file, recording it as the hosts' source file
+func readHostLines(path string) ([]types.SSHHost, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hosts, err := parseCommandOutput(file, SourceImported)
+	for i := range hosts {
+		hosts[i].SourceFile = path
+	}
+	return hosts, err
+}
@@ -2,6 +2,9 @@ package parser
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -14,11 +17,10 @@ import (
 func ParseKnownHosts() ([]types.SSHHost, error) {
 	var hosts []types.SSHHost
 
-	homeDir, err := os.UserHomeDir()
+	knownHostsPath, err := UserKnownHostsPath()
 	if err != nil {
 		return hosts, err
 	}
-	knownHostsPath := filepath.Join(homeDir, ".ssh", "known_hosts")
 
 	// Check if known_hosts file exists; return empty if not (known_hosts is optional)
 	if _, err := os.Stat(knownHostsPath); os.IsNotExist(err) {
@@ -31,10 +33,29 @@ func ParseKnownHosts() ([]types.SSHHost, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	hostMap := make(map[string]bool)
+	return parseKnownHosts(file, knownHostsPath)
+}
+
+// UserKnownHostsPath returns the path of the user's known_hosts file (~/.ssh/known_hosts)
+func UserKnownHostsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "known_hosts"), nil
+}
+
+// parseKnownHosts parses known_hosts content read from r; path is recorded as the hosts' source file
+func parseKnownHosts(r io.Reader, path string) ([]types.SSHHost, error) {
+	var hosts []types.SSHHost
+
+	scanner := bufio.NewScanner(r)
+	// Maps a host name to its index in hosts so repeated entries add keys instead of duplicates
+	hostIndex := make(map[string]int)
+	lineNum := 0
 
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip empty lines and comments
@@ -61,6 +82,13 @@ func ParseKnownHosts() ([]types.SSHHost, error) {
 			continue
 		}
 
+		hostKey := types.HostKey{
+			Type:        parts[1],
+			Fingerprint: KeyFingerprint(parts[2]),
+			File:        path,
+			Line:        lineNum,
+		}
+
 		// Handle multiple hosts separated by commas
 		hostNames := strings.Split(hostPart, ",")
 
@@ -73,7 +101,8 @@ func ParseKnownHosts() ([]types.SSHHost, error) {
 			// Extract hostname and port if present
 			host, port := parseHostPort(hostName)
 
-			if hostMap[host] {
+			if idx, ok := hostIndex[host]; ok {
+				hosts[idx].HostKeys = append(hosts[idx].HostKeys, hostKey)
 				continue
 			}
 
@@ -82,13 +111,16 @@ func ParseKnownHosts() ([]types.SSHHost, error) {
 				continue
 			}
 
-			hostMap[host] = true
+			hostIndex[host] = len(hosts)
 
 			sshHost := types.SSHHost{
-				Name:     host,
-				HostName: host,
-				Port:     port,
-				Source:   types.SourceKnownHosts,
+				Name:       host,
+				HostName:   host,
+				Port:       port,
+				Source:     types.SourceKnownHosts,
+				SourceFile: path,
+				SourceLine: lineNum,
+				HostKeys:   []types.HostKey{hostKey},
 			}
 
 			hosts = append(hosts, sshHost)
@@ -98,6 +130,17 @@ func ParseKnownHosts() ([]types.SSHHost, error) {
 	return hosts, scanner.Err()
 }
 
+// KeyFingerprint returns the OpenSSH SHA256 fingerprint of a base64-encoded public key
+// It returns an empty string if the key is not valid base64
+func KeyFingerprint(b64Key string) string {
+	raw, err := base64.StdEncoding.DecodeString(b64Key)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
 // parseHostPort extracts hostname and port from a known_hosts entry
 // Handles formats like: hostname, [hostname]:port, hostname:port
 func parseHostPort(hostEntry string) (string, string) {
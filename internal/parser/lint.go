@@ -0,0 +1,488 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity ranks a config diagnostic
+type Severity string
+
+const (
+	// SeverityError marks lines ssh rejects, making every connection fail
+	SeverityError Severity = "error"
+	// SeverityWarning marks lines that are accepted but probably do not do what was meant
+	SeverityWarning Severity = "warning"
+)
+
+// Rules identify the kind of problem a diagnostic reports
+const (
+	RuleUnknownKeyword  = "unknown-keyword"
+	RuleDeprecated      = "deprecated"
+	RuleMissingValue    = "missing-value"
+	RuleInvalidHostName = "invalid-hostname"
+	RuleInvalidPort     = "invalid-port"
+	RuleShadowed        = "shadowed"
+	RuleUnreachable     = "unreachable"
+	RuleDuplicateHost   = "duplicate-host"
+	RuleInclude         = "include"
+)
+
+// Diagnostic is a problem found at a line of an SSH config file
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// knownKeywords maps the lowercased ssh_config keywords of current OpenSSH releases to their
+// documented spelling
+var knownKeywords = keywordMap(
+	"Host", "Match", "AddKeysToAgent", "AddressFamily", "BatchMode", "BindAddress", "BindInterface",
+	"CanonicalDomains", "CanonicalizeFallbackLocal", "CanonicalizeHostname", "CanonicalizeMaxDots",
+	"CanonicalizePermittedCNAMEs", "CASignatureAlgorithms", "CertificateFile", "ChannelTimeout",
+	"CheckHostIP", "Ciphers", "ClearAllForwardings", "Compression", "ConnectionAttempts",
+	"ConnectTimeout", "ControlMaster", "ControlPath", "ControlPersist", "DynamicForward",
+	"EnableEscapeCommandline", "EnableSSHKeysign", "EscapeChar", "ExitOnForwardFailure",
+	"FingerprintHash", "ForkAfterAuthentication", "ForwardAgent", "ForwardX11", "ForwardX11Timeout",
+	"ForwardX11Trusted", "GatewayPorts", "GlobalKnownHostsFile", "GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials", "HashKnownHosts", "HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication", "HostKeyAlgorithms", "HostKeyAlias", "HostName", "IdentitiesOnly",
+	"IdentityAgent", "IdentityFile", "IgnoreUnknown", "Include", "IPQoS",
+	"KbdInteractiveAuthentication", "KbdInteractiveDevices", "KexAlgorithms", "KnownHostsCommand",
+	"LocalCommand", "LocalForward", "LogLevel", "LogVerbose", "MACs", "NoHostAuthenticationForLocalhost",
+	"NumberOfPasswordPrompts", "ObscureKeystrokeTiming", "PasswordAuthentication", "PermitLocalCommand",
+	"PermitRemoteOpen", "PKCS11Provider", "Port", "PreferredAuthentications", "ProxyCommand", "ProxyJump",
+	"ProxyUseFdpass", "PubkeyAcceptedAlgorithms", "PubkeyAuthentication", "RefuseConnection", "RekeyLimit",
+	"RemoteCommand", "RemoteForward", "RequestTTY", "RequiredRSASize", "RevokedHostKeys",
+	"SecurityKeyProvider", "SendEnv", "ServerAliveCountMax", "ServerAliveInterval", "SessionType",
+	"SetEnv", "StdinNull", "StreamLocalBindMask", "StreamLocalBindUnlink", "StrictHostKeyChecking",
+	"SyslogFacility", "Tag", "TCPKeepAlive", "Tunnel", "TunnelDevice", "UpdateHostKeys", "User",
+	"UserKnownHostsFile", "VerifyHostKeyDNS", "VisualHostKey", "XAuthLocation",
+	// Old names still accepted as aliases
+	"ChallengeResponseAuthentication", "HostbasedKeyTypes", "PubkeyAcceptedKeyTypes",
+	// Added by the GSSAPI key exchange patch shipped by most Linux distributions
+	"GSSAPIKeyExchange", "GSSAPIClientIdentity", "GSSAPIServerIdentity", "GSSAPIRenewalForcesRekey", "GSSAPITrustDNS",
+	// Added by Apple's OpenSSH on macOS
+	"UseKeychain",
+)
+
+// deprecatedKeywords are accepted by ssh with a warning but have no effect any more
+var deprecatedKeywords = keywordMap(
+	"Cipher", "CompressionLevel", "Protocol", "RhostsRSAAuthentication", "RSAAuthentication",
+	"UsePrivilegedPort", "UseRoaming", "UseBlacklistedKeys", "FallBackToRsh", "UseRsh",
+)
+
+// accumulatingKeywords may be given several times, each occurrence adding to the earlier ones
+// instead of being ignored after the first
+var accumulatingKeywords = map[string]bool{
+	"certificatefile": true, "dynamicforward": true, "identityfile": true, "localforward": true,
+	"remoteforward": true, "sendenv": true, "setenv": true, "include": true,
+}
+
+// keywordMap indexes keywords by their lowercased form
+func keywordMap(keywords ...string) map[string]string {
+	m := make(map[string]string, len(keywords))
+	for _, k := range keywords {
+		m[strings.ToLower(k)] = k
+	}
+	return m
+}
+
+// LintConfigFile lints the config file at path and the files it includes
+func LintConfigFile(path string) ([]Diagnostic, error) {
+	tree, diagnostics, err := LoadConfigTree(path)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics = append(diagnostics, LintConfig(tree)...)
+
+	// Report in file order, then by line
+	order := make(map[string]int, len(tree))
+	for i := len(tree) - 1; i >= 0; i-- {
+		order[tree[i].Path] = i
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if order[a.File] != order[b.File] {
+			return order[a.File] < order[b.File]
+		}
+		return a.Line < b.Line
+	})
+	return diagnostics, nil
+}
+
+// LintConfig checks the files of a config tree (see LoadConfigTree) the way ssh reads them:
+// included files take the place of their Include line, and within each Host or Match block
+// the first value given for an option wins. It reports:
+//
+//   - unknown keywords, with a suggestion when one is misspelled, and deprecated ones
+//   - directives without a value, invalid ports and HostName values ssh-tui cannot use
+//   - options set outside any Host block, which make later values for the same option dead
+//   - Host blocks that never take effect, because all their patterns are negated or every
+//     option they set was already set for the same hosts
+//   - host names and aliases listed by more than one Host block
+func LintConfig(tree []LoadedConfig) []Diagnostic {
+	if len(tree) == 0 {
+		return nil
+	}
+	l := &linter{
+		ignore:  ignoredKeywords(tree),
+		global:  make(map[string]position),
+		names:   make(map[string]position),
+		options: make(map[*ConfigBlock]map[string]bool),
+	}
+	l.walk(tree, 0, nil)
+	l.checkUnreachable()
+	return l.diagnostics
+}
+
+// position locates a line in a config file
+type position struct {
+	file string
+	line int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%s:%d", p.file, p.line)
+}
+
+// hostBlock is a Host block seen by the linter, in reading order
+type hostBlock struct {
+	block *ConfigBlock
+	pos   position
+	// accumulates is set when the block uses an option that adds to earlier values
+	accumulates bool
+}
+
+// linter holds the state of LintConfig while walking the tree
+type linter struct {
+	diagnostics []Diagnostic
+	ignore      []string
+	// global holds the options set outside any Host or Match block
+	global map[string]position
+	// names holds the first Host block listing each host name
+	names map[string]position
+	hosts []hostBlock
+	// options holds the options set by each block, including those from files it includes
+	options map[*ConfigBlock]map[string]bool
+}
+
+// report adds a diagnostic
+func (l *linter) report(pos position, severity Severity, rule, format string, args ...any) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{File: pos.file, Line: pos.line, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// walk checks the lines of tree[index] in order, descending into included files at their
+// Include line. scope is the Host or Match block the lines belong to, nil outside any block.
+func (l *linter) walk(tree []LoadedConfig, index int, scope *ConfigBlock) {
+	f := tree[index]
+	for _, block := range append([]*ConfigBlock{f.File.Global}, f.File.Blocks...) {
+		lines := block.Lines
+		if block.Header != nil {
+			// A Host or Match line in an included file starts a block that ends with the file
+			scope = block
+			lines = append([]*ConfigLine{block.Header}, block.Lines...)
+		}
+		for _, line := range lines {
+			if line.Kind != LineDirective {
+				continue
+			}
+			l.checkLine(position{f.Path, line.Num}, line, block, scope)
+			for _, child := range f.Includes[line.Num] {
+				l.walk(tree, child, scope)
+			}
+		}
+	}
+}
+
+// checkLine applies the line-level rules to a directive
+func (l *linter) checkLine(pos position, line *ConfigLine, block, scope *ConfigBlock) {
+	key := strings.ToLower(line.Key)
+	if _, ok := knownKeywords[key]; !ok {
+		if name, ok := deprecatedKeywords[key]; ok {
+			l.report(pos, SeverityWarning, RuleDeprecated, "%s is deprecated and has no effect", name)
+		} else if !l.ignored(key) {
+			l.report(pos, SeverityError, RuleUnknownKeyword, "unknown keyword %q%s", line.Key, suggestKeyword(key))
+		}
+		return
+	}
+
+	if line.Value == "" {
+		if key == "host" {
+			l.report(pos, SeverityError, RuleMissingValue, "Host has no patterns")
+		} else {
+			l.report(pos, SeverityError, RuleMissingValue, "%s has no value", knownKeywords[key])
+		}
+		return
+	}
+
+	switch key {
+	case "host":
+		l.checkHost(pos, block)
+		return
+	case "match", "include":
+		return
+	case "hostname":
+		if !strings.Contains(line.Value, "%") && !IsValidHost(line.Value) {
+			l.report(pos, SeverityWarning, RuleInvalidHostName, "HostName %q is not a valid host name or address", line.Value)
+		}
+	case "port":
+		if !validPortValue(line.Value) {
+			l.report(pos, SeverityError, RuleInvalidPort, "Port %q is not a port number between 1 and 65535", line.Value)
+		}
+	}
+
+	if accumulatingKeywords[key] {
+		if scope != nil {
+			for i := range l.hosts {
+				if l.hosts[i].block == scope {
+					l.hosts[i].accumulates = true
+				}
+			}
+		}
+		return
+	}
+	if scope == nil {
+		if _, ok := l.global[key]; !ok {
+			l.global[key] = pos
+		}
+		return
+	}
+	if first, ok := l.global[key]; ok {
+		l.report(pos, SeverityWarning, RuleShadowed, "%s is already set outside any Host block at %s, so this value is never used", knownKeywords[key], first)
+	}
+	if l.options[scope] == nil {
+		l.options[scope] = make(map[string]bool)
+	}
+	l.options[scope][key] = true
+}
+
+// checkHost checks the patterns of a Host line and records the block
+func (l *linter) checkHost(pos position, block *ConfigBlock) {
+	l.hosts = append(l.hosts, hostBlock{block: block, pos: pos})
+
+	negatedOnly := true
+	listed := make(map[string]bool)
+	for _, pattern := range block.Patterns() {
+		if !strings.HasPrefix(pattern, "!") {
+			negatedOnly = false
+		}
+		if strings.ContainsAny(pattern, "*?!") {
+			continue
+		}
+		name := strings.ToLower(pattern)
+		switch first, ok := l.names[name]; {
+		case listed[name]:
+			l.report(pos, SeverityWarning, RuleDuplicateHost, "%q is listed twice", pattern)
+		case ok:
+			l.report(pos, SeverityWarning, RuleDuplicateHost, "%q is already listed by the Host block at %s; only options not set there apply", pattern, first)
+		default:
+			l.names[name] = pos
+		}
+		listed[name] = true
+	}
+	if negatedOnly {
+		l.report(pos, SeverityWarning, RuleUnreachable, "Host block never matches: all its patterns are negated")
+	}
+}
+
+// checkUnreachable reports Host blocks whose options are all set earlier for every host they
+// match, so none of them can take effect
+func (l *linter) checkUnreachable() {
+	for i, b := range l.hosts {
+		options := l.options[b.block]
+		if len(options) == 0 || b.accumulates {
+			continue
+		}
+
+		var first *hostBlock
+		covered := make(map[string]bool)
+		for j := range l.hosts[:i] {
+			earlier := &l.hosts[j]
+			if !covers(earlier.block.Patterns(), b.block.Patterns()) {
+				continue
+			}
+			for key := range l.options[earlier.block] {
+				if options[key] && first == nil {
+					first = earlier
+				}
+				covered[key] = true
+			}
+		}
+		if first == nil {
+			continue
+		}
+
+		unused := true
+		for key := range options {
+			if _, global := l.global[key]; !covered[key] && !global {
+				unused = false
+			}
+		}
+		if unused {
+			l.report(b.pos, SeverityWarning, RuleUnreachable, "Host block has no effect: every option it sets is already set for its hosts (first by the Host block at %s)", first.pos)
+		}
+	}
+}
+
+// covers reports whether a Host line with the patterns in outer matches every host matched by
+// the patterns in inner
+func covers(outer, inner []string) bool {
+	var positive, negative []string
+	for _, p := range outer {
+		if rest, ok := strings.CutPrefix(p, "!"); ok {
+			negative = append(negative, strings.ToLower(rest))
+		} else {
+			positive = append(positive, strings.ToLower(p))
+		}
+	}
+
+	matched := false
+	for _, p := range inner {
+		if strings.HasPrefix(p, "!") {
+			continue
+		}
+		name := strings.ToLower(p)
+		if strings.ContainsAny(name, "*?") {
+			// Only an unrestricted "*" is known to cover another pattern
+			if len(negative) > 0 || !contains(positive, "*") {
+				return false
+			}
+		} else if !matchesAny(positive, name) || matchesAny(negative, name) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// matchesAny reports whether name matches one of the patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if MatchPattern(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPattern matches name against an ssh_config or known_hosts pattern, where * matches any
+// sequence of characters and ? a single one
+func MatchPattern(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(name); i >= 0; i-- {
+				if MatchPattern(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if name == "" {
+				return false
+			}
+		default:
+			if name == "" || name[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return name == ""
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredKeywords collects the patterns given to IgnoreUnknown anywhere in the tree
+func ignoredKeywords(tree []LoadedConfig) []string {
+	var patterns []string
+	for _, f := range tree {
+		for _, line := range f.File.Lines() {
+			if line.Kind == LineDirective && strings.EqualFold(line.Key, "ignoreunknown") {
+				for _, p := range strings.FieldsFunc(line.Value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+					patterns = append(patterns, strings.ToLower(p))
+				}
+			}
+		}
+	}
+	return patterns
+}
+
+// ignored reports whether IgnoreUnknown covers the lowercased keyword
+func (l *linter) ignored(key string) bool {
+	return matchesAny(l.ignore, key)
+}
+
+// validPortValue reports whether value is a port number or a service name, as ssh accepts
+func validPortValue(value string) bool {
+	if port, err := strconv.Atoi(value); err == nil {
+		return port >= 1 && port <= 65535
+	}
+	for i, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && (r == '-' || r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestKeyword returns a "did you mean" hint for an unknown lowercased keyword, or ""
+func suggestKeyword(key string) string {
+	best, bestDistance := "", 3
+	if len(key) < 6 {
+		bestDistance = 2
+	}
+	for lower, name := range knownKeywords {
+		if d := editDistance(key, lower); d < bestDistance || d == bestDistance && best != "" && name < best {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %q?", best)
+}
+
+// editDistance returns the number of single-character insertions, deletions, substitutions
+// and swaps of adjacent characters needed to turn a into b
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
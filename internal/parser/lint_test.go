@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lint writes the config files (the first is the main config, the others extraN.conf) under a
+// temporary ~/.ssh and returns the diagnostics as "file:line: severity rule: message" lines,
+// with paths relative to ~/.ssh
+func lint(t *testing.T, files ...string) []string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for i, content := range files {
+		name := "config"
+		if i > 0 {
+			name = fmt.Sprintf("extra%d.conf", i)
+		}
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diagnostics, err := LintConfigFile(filepath.Join(sshDir, "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diagnostics {
+		message := strings.ReplaceAll(d.Message, sshDir+string(filepath.Separator), "")
+		got = append(got, fmt.Sprintf("%s:%d: %s %s: %s", filepath.Base(d.File), d.Line, d.Severity, d.Rule, message))
+	}
+	return got
+}
+
+// expect compares diagnostics with the wanted lines
+func expect(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintConfig_Keywords(t *testing.T) {
+	got := lint(t, "IgnoreUnknown UseKeychain,Custom*\n"+
+		"Host web\n"+
+		"  HostNmae web.example.com\n"+
+		"  Usr deploy\n"+
+		"  UseKeychain yes\n"+
+		"  CustomOption 1\n"+
+		"  Frobnicate yes\n"+
+		"  Protocol 2\n"+
+		"  User\n"+
+		"  Port 70000\n"+
+		"  hostname bad_host!\n"+
+		"Host db\n"+
+		"  Port ssh\n"+
+		"  HostName %h.internal\n"+
+		"Host\n")
+	expect(t, got,
+		`config:3: error unknown-keyword: unknown keyword "HostNmae"; did you mean "HostName"?`,
+		`config:4: error unknown-keyword: unknown keyword "Usr"; did you mean "User"?`,
+		`config:7: error unknown-keyword: unknown keyword "Frobnicate"`,
+		`config:8: warning deprecated: Protocol is deprecated and has no effect`,
+		`config:9: error missing-value: User has no value`,
+		`config:10: error invalid-port: Port "70000" is not a port number between 1 and 65535`,
+		`config:11: warning invalid-hostname: HostName "bad_host!" is not a valid host name or address`,
+		`config:15: error missing-value: Host has no patterns`,
+	)
+}
+
+func TestLintConfig_Shadowed(t *testing.T) {
+	got := lint(t, "User root\n"+
+		"IdentityFile ~/.ssh/id_default\n"+
+		"Include extra1.conf\n"+
+		"Host web\n"+
+		"  User deploy\n"+
+		"  IdentityFile ~/.ssh/id_web\n"+
+		"  Port 2222\n",
+		"Port 22\nHost db\n  Port 5432\n")
+	expect(t, got,
+		`config:5: warning shadowed: User is already set outside any Host block at config:1, so this value is never used`,
+		`config:7: warning shadowed: Port is already set outside any Host block at extra1.conf:1, so this value is never used`,
+		`extra1.conf:3: warning shadowed: Port is already set outside any Host block at extra1.conf:1, so this value is never used`,
+	)
+}
+
+func TestLintConfig_HostBlocks(t *testing.T) {
+	got := lint(t, "Host web web1\n"+
+		"  User deploy\n"+
+		"Host *.example.com !db.example.com\n"+
+		"  Port 2222\n"+
+		"Host !legacy\n"+
+		"  User old\n"+
+		"Host web1 WEB1\n"+
+		"  User admin\n"+
+		"Host app.example.com\n"+
+		"  Port 22\n"+
+		"  IdentityFile ~/.ssh/app\n"+
+		"Host api.example.com\n"+
+		"  Port 22\n"+
+		"Host db.example.com\n"+
+		"  Port 2200\n")
+	expect(t, got,
+		`config:5: warning unreachable: Host block never matches: all its patterns are negated`,
+		`config:7: warning duplicate-host: "web1" is already listed by the Host block at config:1; only options not set there apply`,
+		`config:7: warning duplicate-host: "WEB1" is listed twice`,
+		`config:7: warning unreachable: Host block has no effect: every option it sets is already set for its hosts (first by the Host block at config:1)`,
+		`config:12: warning unreachable: Host block has no effect: every option it sets is already set for its hosts (first by the Host block at config:3)`,
+	)
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"hostname", "hostname", 0},
+		{"hostnmae", "hostname", 1},
+		{"usr", "user", 1},
+		{"port", "sort", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
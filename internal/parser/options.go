@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"strings"
+
+	"ssh-tui/internal/types"
+)
+
+// ConfigOptions works out the options ssh applies to hosts from a config tree (see LoadConfigTree)
+type ConfigOptions struct {
+	tree []LoadedConfig
+}
+
+// LoadConfigOptions reads the config file at path and the files it includes. A config that
+// cannot be read applies no options.
+func LoadConfigOptions(path string) *ConfigOptions {
+	tree, _, err := LoadConfigTree(path)
+	if err != nil {
+		return &ConfigOptions{}
+	}
+	return &ConfigOptions{tree: tree}
+}
+
+// UserConfigOptions reads ~/.ssh/config and the files it includes
+func UserConfigOptions() *ConfigOptions {
+	path, err := UserConfigPath()
+	if err != nil {
+		return &ConfigOptions{}
+	}
+	return LoadConfigOptions(path)
+}
+
+// For returns the options ssh applies when connecting to host, by lowercase keyword, the way ssh
+// reads its config: every Host and Match block that applies contributes, included files take the
+// place of their Include line, and the first value given for an option wins. Hosts are matched
+// by the name ssh is given: a config host's name, otherwise its HostName.
+//
+// Match criteria that depend on more than the host name (exec, user, localuser, localnetwork,
+// ...) are assumed to hold, so options such as ProxyJump set under them are reported rather
+// than missed. A nil ConfigOptions only knows the directives of the host's own block.
+func (c *ConfigOptions) For(host types.SSHHost) map[string]string {
+	options := make(map[string]string)
+	if c == nil {
+		for _, d := range host.Directives {
+			key := strings.ToLower(d.Key)
+			if _, ok := options[key]; !ok {
+				options[key] = d.Value
+			}
+		}
+		return options
+	}
+
+	name := host.Name
+	if host.Source != types.SourceConfig && host.HostName != "" {
+		name = host.HostName
+	}
+	if len(c.tree) > 0 {
+		r := resolver{tree: c.tree, name: strings.ToLower(name), options: options}
+		r.walk(0, true)
+	}
+	return options
+}
+
+// resolver holds the state of ConfigOptions.For while walking the tree
+type resolver struct {
+	tree    []LoadedConfig
+	name    string
+	options map[string]string
+}
+
+// walk collects the options of tree[index] in reading order, descending into included files at
+// their Include line. active tells whether the lines before the file's first block apply.
+func (r *resolver) walk(index int, active bool) {
+	f := r.tree[index]
+	for _, block := range append([]*ConfigBlock{f.File.Global}, f.File.Blocks...) {
+		if block.Header != nil {
+			if block.IsHost() {
+				active = hostPatternsMatch(block.Patterns(), r.name)
+			} else {
+				active = r.matchApplies(block.Header.Value)
+			}
+		}
+		if !active {
+			continue
+		}
+		for _, line := range block.Directives() {
+			key := strings.ToLower(line.Key)
+			if key == "include" {
+				for _, child := range f.Includes[line.Num] {
+					r.walk(child, active)
+				}
+				continue
+			}
+			if _, ok := r.options[key]; !ok && line.Value != "" {
+				r.options[key] = line.Value
+			}
+		}
+	}
+}
+
+// hostPatternsMatch reports whether name matches a Host line's patterns: one of them matches and
+// none of the negated ones does
+func hostPatternsMatch(patterns []string, name string) bool {
+	matched := false
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if negated := strings.HasPrefix(p, "!"); negated {
+			if MatchPattern(p[1:], name) {
+				return false
+			}
+		} else if MatchPattern(p, name) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchApplies evaluates a Match line's criteria for the host. host is compared with the HostName
+// set so far, originalhost with the name given to ssh; other criteria are assumed to hold.
+func (r *resolver) matchApplies(criteria string) bool {
+	fields := strings.Fields(criteria)
+	for i := 0; i < len(fields); i++ {
+		criterion := strings.ToLower(fields[i])
+		negated := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+		switch criterion {
+		case "all", "canonical", "final":
+			continue
+		}
+		if i+1 == len(fields) {
+			return true
+		}
+		i++
+		var name string
+		switch criterion {
+		case "host":
+			name = r.name
+			if hostName, ok := r.options["hostname"]; ok && !strings.Contains(hostName, "%") {
+				name = strings.ToLower(hostName)
+			}
+		case "originalhost":
+			name = r.name
+		default:
+			continue
+		}
+		if patternListMatches(fields[i], name) == negated {
+			return false
+		}
+	}
+	return true
+}
+
+// patternListMatches reports whether name matches a comma-separated pattern list, where a
+// matching negated pattern excludes it
+func patternListMatches(list, name string) bool {
+	return hostPatternsMatch(strings.Split(list, ","), name)
+}
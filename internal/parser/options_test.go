@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ssh-tui/internal/types"
+)
+
+func TestConfigOptions_For(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	main := `Host app1.internal
+  HostName 10.0.0.5
+  User deploy
+
+Host *.internal !bastion.internal
+  ProxyJump bastion.internal
+  User ops
+  Include ` + filepath.Join(dir, "internal.conf") + `
+
+Host legacy
+  HostName 192.168.1.9
+
+Match originalhost legacy host 192.168.*
+  UserKnownHostsFile ~/.ssh/legacy_hosts
+
+Match exec "test -f /tmp/vpn"
+  ProxyCommand none
+
+Host *
+  StrictHostKeyChecking ask
+`
+	if err := os.WriteFile(config, []byte(main), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "internal.conf"), []byte("ForwardAgent yes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	options := LoadConfigOptions(config)
+
+	tests := []struct {
+		host types.SSHHost
+		want map[string]string
+	}{
+		// The host's own block comes first, then the options it inherits
+		{types.SSHHost{Name: "app1.internal", Source: types.SourceConfig}, map[string]string{
+			"hostname": "10.0.0.5", "user": "deploy", "proxyjump": "bastion.internal", "forwardagent": "yes",
+			"proxycommand": "none", "stricthostkeychecking": "ask"}},
+		// Negated patterns exclude the host
+		{types.SSHHost{Name: "bastion.internal", Source: types.SourceKnownHosts}, map[string]string{
+			"proxycommand": "none", "stricthostkeychecking": "ask"}},
+		// Other sources are matched by their HostName
+		{types.SSHHost{Name: "db", HostName: "DB.internal", Source: types.SourceKnownHosts}, map[string]string{
+			"proxyjump": "bastion.internal", "user": "ops", "forwardagent": "yes",
+			"proxycommand": "none", "stricthostkeychecking": "ask"}},
+		// Match host compares the HostName set so far
+		{types.SSHHost{Name: "legacy", HostName: "192.168.1.9", Source: types.SourceConfig}, map[string]string{
+			"hostname": "192.168.1.9", "userknownhostsfile": "~/.ssh/legacy_hosts", "proxycommand": "none", "stricthostkeychecking": "ask"}},
+	}
+	for _, tt := range tests {
+		got := options.For(tt.host)
+		if len(got) != len(tt.want) {
+			t.Errorf("For(%s) = %v, want %v", tt.host.Name, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("For(%s)[%s] = %q, want %q", tt.host.Name, k, got[k], v)
+			}
+		}
+	}
+
+	// Without a config only the host's own block is known
+	var none *ConfigOptions
+	own := none.For(types.SSHHost{Name: "web", Directives: []types.Directive{{Key: "ProxyJump", Value: "a"}, {Key: "proxyjump", Value: "b"}}})
+	if len(own) != 1 || own["proxyjump"] != "a" {
+		t.Errorf("expected the first value of the host's own directives, got %v", own)
+	}
+}
@@ -139,3 +139,124 @@ func TestIsValidSSHOption(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSSHConfig_DetailsAndNotes(t *testing.T) {
+	content := `# Shared defaults
+Host *
+    ServerAliveInterval 30
+
+# Primary database
+# managed by ops
+Host db db-primary
+    HostName db.example.com
+    User postgres
+    Port 5433
+    IdentityFile ~/.ssh/db_key
+
+# detached comment
+
+Host web
+    HostName web.example.com
+`
+	hosts, err := parseSSHConfig(strings.NewReader(content), "/tmp/config")
+	if err != nil {
+		t.Fatalf("parseSSHConfig failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d: %+v", len(hosts), hosts)
+	}
+
+	db := hosts[0]
+	if db.SourceFile != "/tmp/config" || db.SourceLine != 7 {
+		t.Fatalf("unexpected source location for db: %s:%d", db.SourceFile, db.SourceLine)
+	}
+	if len(db.Notes) != 2 || db.Notes[0] != "Primary database" || db.Notes[1] != "managed by ops" {
+		t.Fatalf("unexpected notes for db: %q", db.Notes)
+	}
+	if len(db.Directives) != 4 || db.Directives[3].Key != "IdentityFile" || db.Directives[3].Value != "~/.ssh/db_key" || db.Directives[3].Line != 11 {
+		t.Fatalf("unexpected directives for db: %+v", db.Directives)
+	}
+
+	if len(hosts[1].Notes) != 0 {
+		t.Fatalf("expected comment separated by a blank line not to attach, got %q", hosts[1].Notes)
+	}
+}
+
+func TestParseKnownHosts_Keys(t *testing.T) {
+	content := `example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl
+example.com,[alt.example.com]:2222 ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDP
+`
+	hosts, err := parseKnownHosts(strings.NewReader(content), "/tmp/known_hosts")
+	if err != nil {
+		t.Fatalf("parseKnownHosts failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+	if len(hosts[0].HostKeys) != 2 || hosts[0].HostKeys[0].Type != "ssh-ed25519" || hosts[0].HostKeys[1].Line != 2 {
+		t.Fatalf("unexpected keys for example.com: %+v", hosts[0].HostKeys)
+	}
+	if hosts[1].Name != "alt.example.com" || hosts[1].Port != "2222" || hosts[1].SourceLine != 2 {
+		t.Fatalf("unexpected second host: %+v", hosts[1])
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	// Fingerprint as reported by ssh-keygen -lf for this key
+	got := KeyFingerprint("AAAAC3NzaC1lZDI1NTE5AAAAIJPpd0LvI15xl71E/TLNC1S2mn+c4QHkMcqsSEs283Sy")
+	if got != "SHA256:o6p87m+cFqMpdh1uAxdyfo5wUyMjqGMa8KOdNaQSYpg" {
+		t.Fatalf("unexpected fingerprint: %q", got)
+	}
+	if KeyFingerprint("not base64!") != "" {
+		t.Fatalf("expected empty fingerprint for invalid key")
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	desc, tags, notes := parseAnnotations([]string{
+		"Database cluster",
+		"@tags prod,db  @desc Primary Postgres",
+		"@tags db, eu-west",
+		"@desc (replica in eu-west)",
+	})
+
+	if desc != "Primary Postgres (replica in eu-west)" {
+		t.Fatalf("unexpected description: %q", desc)
+	}
+	if strings.Join(tags, ",") != "prod,db,eu-west" {
+		t.Fatalf("unexpected tags: %q", tags)
+	}
+	if len(notes) != 1 || notes[0] != "Database cluster" {
+		t.Fatalf("unexpected notes: %q", notes)
+	}
+}
+
+func TestFilterHosts_TagsAndDescription(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "pg1", Tags: []string{"prod", "db"}, Description: "Primary Postgres"},
+		{Name: "pg2", Tags: []string{"staging", "db"}, Description: "Staging Postgres"},
+		{Name: "web", Tags: []string{"prod"}},
+		{Name: "postgres-tools"},
+	}
+
+	filtered := FilterHosts(hosts, "tag:prod")
+	if len(filtered) != 2 || filtered[0].Name != "pg1" || filtered[1].Name != "web" {
+		t.Fatalf("unexpected tag filter result: %+v", filtered)
+	}
+
+	filtered = FilterHosts(hosts, "TAG:db tag:prod")
+	if len(filtered) != 1 || filtered[0].Name != "pg1" {
+		t.Fatalf("expected tags to be combined, got %+v", filtered)
+	}
+
+	// Name matches rank above description matches
+	filtered = FilterHosts(hosts, "postgres")
+	if len(filtered) != 3 || filtered[0].Name != "postgres-tools" || filtered[1].Name != "pg1" {
+		t.Fatalf("unexpected description search result: %+v", filtered)
+	}
+
+	filtered = FilterHosts(hosts, "tag:db staging")
+	if len(filtered) != 1 || filtered[0].Name != "pg2" {
+		t.Fatalf("expected tag filter combined with search term, got %+v", filtered)
+	}
+}
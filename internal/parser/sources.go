@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+// HostSource discovers hosts from one place, such as the SSH config or known_hosts
+type HostSource interface {
+	// Name identifies the source; it is stored in SSHHost.Source and used as its settings key
+	Name() string
+	// Discover returns the source's hosts. It should give up promptly once ctx is cancelled.
+	Discover(ctx context.Context) ([]types.SSHHost, error)
+}
+
+// Watcher is optionally implemented by sources that can report when their hosts may have changed
+type Watcher interface {
+	// Watch returns a channel that receives a value after each change; it is closed once ctx is done
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// registration is a registered source and whether it runs when the settings do not mention it
+type registration struct {
+	source         HostSource
+	defaultEnabled bool
+}
+
+// registry holds the registered sources in merge priority order
+var registry []registration
+
+func init() {
+	mustRegister(ConfigSource(), true)
+	mustRegister(KnownHostsSource(), true)
+}
+
+// Register adds a source to the registry. Sources registered earlier take priority when
+// merging. Source names must be unique and must not clash with the custom host source.
+func Register(source HostSource, defaultEnabled bool) error {
+	name := source.Name()
+	if name == "" || name == types.SourceCustom {
+		return fmt.Errorf("invalid host source name %q", name)
+	}
+	for _, r := range registry {
+		if r.source.Name() == name {
+			return fmt.Errorf("host source %q is already registered", name)
+		}
+	}
+	registry = append(registry, registration{source: source, defaultEnabled: defaultEnabled})
+	return nil
+}
+
+// mustRegister registers a built-in source, panicking on error
+func mustRegister(source HostSource, defaultEnabled bool) {
+	if err := Register(source, defaultEnabled); err != nil {
+		panic("parser: " + err.Error())
+	}
+}
+
+// EnabledSources returns the registered sources that are enabled, in priority order.
+// enabled decides for each source given its default; nil keeps every default.
+func EnabledSources(enabled func(name string, defaultEnabled bool) bool) []HostSource {
+	var sources []HostSource
+	for _, r := range registry {
+		on := r.defaultEnabled
+		if enabled != nil {
+			on = enabled(r.source.Name(), r.defaultEnabled)
+		}
+		if on {
+			sources = append(sources, r.source)
+		}
+	}
+	return sources
+}
+
+// SourceNames returns the names of the given sources, in order
+func SourceNames(sources []HostSource) []string {
+	names := make([]string, 0, len(sources))
+	for _, s := range sources {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// watchInterval is how often file sources check their file for changes
+var watchInterval = 2 * time.Second
+
+// fileSource is a HostSource backed by a file in ~/.ssh
+type fileSource struct {
+	name  string
+	path  func() (string, error)
+	parse func() ([]types.SSHHost, error)
+	// files lists the files read for the one at path, such as its Includes; nil means only path
+	files func(path string) []string
+}
+
+// ConfigSource returns the source reading hosts from ~/.ssh/config and its Includes
+func ConfigSource() HostSource {
+	return &fileSource{name: types.SourceConfig, path: UserConfigPath, parse: ParseSSHConfig, files: configTreeFiles}
+}
+
+// KnownHostsSource returns the source reading hosts from ~/.ssh/known_hosts
+func KnownHostsSource() HostSource {
+	return &fileSource{name: types.SourceKnownHosts, path: UserKnownHostsPath, parse: ParseKnownHosts}
+}
+
+func (s *fileSource) Name() string {
+	return s.name
+}
+
+func (s *fileSource) Discover(ctx context.Context) ([]types.SSHHost, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.parse()
+}
+
+// Watch polls the modification time and size of the source's files, reporting a change whenever
+// either differs or a file appears or goes away
+func (s *fileSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	last := s.stat()
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := s.stat()
+				if sameFiles(current, last) {
+					continue
+				}
+				last = current
+				// Never block: a pending notification already covers this change
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changes
+}
+
+// WatchSources merges the change notifications of the sources implementing Watcher. It returns
+// nil when none does; the channel is closed once ctx is done.
+func WatchSources(ctx context.Context, sources []HostSource) <-chan struct{} {
+	var watched []<-chan struct{}
+	for _, source := range sources {
+		if w, ok := source.(Watcher); ok {
+			watched = append(watched, w.Watch(ctx))
+		}
+	}
+	if len(watched) == 0 {
+		return nil
+	}
+
+	changes := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	for _, c := range watched {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range c {
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(changes)
+	}()
+	return changes
+}
+
+// fileState identifies a version of a file; a zero modTime means the file does not exist
+type fileState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the current state of the source's files
+func (s *fileSource) stat() []fileState {
+	path, err := s.path()
+	if err != nil {
+		return nil
+	}
+	paths := []string{path}
+	if s.files != nil {
+		paths = s.files(path)
+	}
+
+	states := make([]fileState, len(paths))
+	for i, p := range paths {
+		states[i].path = p
+		if info, err := os.Stat(p); err == nil {
+			states[i].modTime, states[i].size = info.ModTime(), info.Size()
+		}
+	}
+	return states
+}
+
+// sameFiles reports whether two stats of a source found the same files unchanged
+func sameFiles(a, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].path != b[i].path || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// configTreeFiles returns the config file at path followed by the files it includes
+func configTreeFiles(path string) []string {
+	tree, _, err := LoadConfigTree(path)
+	if err != nil || len(tree) == 0 {
+		return []string{path}
+	}
+	paths := make([]string, len(tree))
+	for i, f := range tree {
+		paths[i] = f.Path
+	}
+	return paths
+}
@@ -0,0 +1,141 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"ssh-tui/internal/types"
+)
+
+// State is the outcome of probing a host
+type State int
+
+const (
+	// StateUp means a TCP connection to the host's SSH port succeeded
+	StateUp State = iota
+	// StateDown means the connection failed or timed out
+	StateDown
+	// StateViaJump means the host is reached through a jump host or proxy command, so it was
+	// not dialed directly
+	StateViaJump
+)
+
+const (
+	// DefaultTimeout bounds each connection attempt
+	DefaultTimeout = 2 * time.Second
+	// DefaultConcurrency limits the connection attempts made at once
+	DefaultConcurrency = 16
+)
+
+// Target is a host to probe
+type Target struct {
+	// Key identifies the host in the results (see Key)
+	Key string
+	// Address is the host:port to dial
+	Address string
+	// ViaJump is set for hosts reached through ProxyJump or ProxyCommand
+	ViaJump bool
+}
+
+// Result is the outcome of probing one target
+type Result struct {
+	State State
+	// Latency is the time taken to connect (StateUp only)
+	Latency time.Duration
+	// Err is the connection error (StateDown only)
+	Err error
+}
+
+// TargetFor returns the probe target for host: its HostName (or name) and port. options are the
+// ssh options that apply to the host (see parser.ConfigOptions); hosts given ProxyJump or
+// ProxyCommand are marked as reached via a jump host.
+func TargetFor(host types.SSHHost, options map[string]string) Target {
+	address := host.HostName
+	if address == "" {
+		address = host.Name
+	}
+	port := host.Port
+	if port == "" {
+		port = types.DefaultSSHPort
+	}
+
+	t := Target{Key: Key(host), Address: net.JoinHostPort(address, port)}
+	for _, key := range []string{"proxyjump", "proxycommand"} {
+		if value, ok := options[key]; ok && !strings.EqualFold(value, "none") {
+			t.ViaJump = true
+		}
+	}
+	return t
+}
+
+// Key identifies host among the probed hosts by its source and name, since hosts from different
+// sources may share a name but not their address or jump settings
+func Key(host types.SSHHost) string {
+	return host.Source + "/" + host.Name
+}
+
+// Prober checks whether hosts accept TCP connections on their SSH port
+type Prober struct {
+	Timeout     time.Duration
+	Concurrency int
+	// Dial opens a connection; tests replace it
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// New returns a prober with the default timeout and concurrency
+func New() *Prober {
+	var d net.Dialer
+	return &Prober{Timeout: DefaultTimeout, Concurrency: DefaultConcurrency, Dial: d.DialContext}
+}
+
+// Probe dials the targets, at most Concurrency at a time, and returns the results by target key
+func (p *Prober) Probe(ctx context.Context, targets []Target) map[string]Result {
+	results := make(map[string]Result, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(1, p.Concurrency))
+
+	for _, t := range targets {
+		if t.ViaJump {
+			mu.Lock()
+			results[t.Key] = Result{State: StateViaJump}
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			r := p.dial(ctx, t.Address)
+			mu.Lock()
+			results[t.Key] = r
+			mu.Unlock()
+		}(t)
+	}
+	wg.Wait()
+	return results
+}
+
+// dial makes a single connection attempt and times it
+func (p *Prober) dial(ctx context.Context, address string) Result {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := p.Dial(ctx, "tcp", address)
+	if err != nil {
+		return Result{State: StateDown, Err: err}
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return Result{State: StateUp, Latency: latency}
+}
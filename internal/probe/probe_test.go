@@ -0,0 +1,127 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+)
+
+func TestTargetFor(t *testing.T) {
+	tests := []struct {
+		host    types.SSHHost
+		options map[string]string
+		want    Target
+	}{
+		{types.SSHHost{Name: "web", Source: types.SourceConfig, HostName: "10.0.0.5", Port: "2222"}, nil, Target{Key: "config/web", Address: "10.0.0.5:2222"}},
+		{types.SSHHost{Name: "db.example.com", Source: types.SourceConfig}, nil, Target{Key: "config/db.example.com", Address: "db.example.com:22"}},
+		{types.SSHHost{Name: "v6", Source: types.SourceConfig, HostName: "::1"}, nil, Target{Key: "config/v6", Address: "[::1]:22"}},
+		{types.SSHHost{Name: "inner", Source: types.SourceConfig}, map[string]string{"proxyjump": "bastion"}, Target{Key: "config/inner", Address: "inner:22", ViaJump: true}},
+		{types.SSHHost{Name: "direct", Source: types.SourceConfig}, map[string]string{"proxycommand": "none"}, Target{Key: "config/direct", Address: "direct:22"}},
+	}
+	for _, tt := range tests {
+		if got := TargetFor(tt.host, tt.options); got != tt.want {
+			t.Errorf("TargetFor(%s) = %+v, want %+v", tt.host.Name, got, tt.want)
+		}
+	}
+}
+
+func TestProbe_LocalListeners(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// A port that was just closed refuses connections
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	targets := []Target{
+		{Key: "up", Address: listener.Addr().String()},
+		{Key: "down", Address: closedAddress},
+		{Key: "jump", Address: "unused:22", ViaJump: true},
+	}
+	results := New().Probe(context.Background(), targets)
+
+	if r := results["up"]; r.State != StateUp || r.Latency <= 0 {
+		t.Errorf("expected up with a latency, got %+v", r)
+	}
+	if r := results["down"]; r.State != StateDown || r.Err == nil {
+		t.Errorf("expected down with an error, got %+v", r)
+	}
+	if r := results["jump"]; r.State != StateViaJump {
+		t.Errorf("expected via jump, got %+v", r)
+	}
+}
+
+func TestProbe_TimeoutAndConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	p := &Prober{
+		Timeout:     50 * time.Millisecond,
+		Concurrency: 2,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	var targets []Target
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		targets = append(targets, Target{Key: name, Address: name + ":22"})
+	}
+	start := time.Now()
+	results := p.Probe(context.Background(), targets)
+
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %v", results)
+	}
+	for name, r := range results {
+		if r.State != StateDown {
+			t.Errorf("%s: expected a timeout, got %+v", name, r)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent dials, got %d", peak.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected the dials to be spread over 3 rounds, took %v", elapsed)
+	}
+}
+
+func TestTargetFor_InheritedProxyJump(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	content := "Host app1.internal\n  User deploy\n\nHost *.internal\n  ProxyJump bastion\n"
+	if err := os.WriteFile(config, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	options := parser.LoadConfigOptions(config)
+
+	hosts := []types.SSHHost{
+		{Name: "app1.internal", Source: types.SourceConfig, Directives: []types.Directive{{Key: "User", Value: "deploy"}}},
+		{Name: "app2", HostName: "app2.internal", Source: types.SourceKnownHosts},
+	}
+	for _, host := range hosts {
+		if got := TargetFor(host, options.For(host)); !got.ViaJump {
+			t.Errorf("expected %s to inherit the ProxyJump of Host *.internal, got %+v", host.Name, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Settings holds the user's ssh-tui configuration, read from config.json
+type Settings struct {
+	// Sources configures host discovery sources, keyed by source name
+	Sources map[string]SourceSettings `json:"sources,omitempty"`
+	// Commands declares external commands that print hosts as JSON lines
+	Commands []CommandSettings `json:"commands,omitempty"`
+	// TrustedFingerprints lists files of host key fingerprints published by the team, compared
+	// with the keys of hosts that are not in known_hosts yet
+	TrustedFingerprints []string `json:"trustedFingerprints,omitempty"`
+}
+
+// CommandSettings declares an external command host source
+type CommandSettings struct {
+	// Name identifies the source in the UI and in Sources
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// Timeout is a Go duration such as "30s"; empty uses the default
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// TimeoutDuration returns the parsed Timeout, or 0 when it is unset
+func (c CommandSettings) TimeoutDuration() (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q for command %q", c.Timeout, c.Name)
+	}
+	return d, nil
+}
+
+// SourceSettings configures a single host discovery source
+type SourceSettings struct {
+	// Enabled turns the source on or off; when unset the source's default applies
+	Enabled *bool `json:"enabled,omitempty"`
+	// Paths lists the files read by file-based sources such as Ansible inventories
+	Paths []string `json:"paths,omitempty"`
+}
+
+// validName matches names that are safe to use as source names and file names
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Dir returns ssh-tui's directory under the user's config directory
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ssh-tui"), nil
+}
+
+// DefaultPath returns the location of the settings file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// ImportDir returns the directory holding hosts imported from other SSH clients
+func ImportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "imported"), nil
+}
+
+// Load reads the settings file at path; a missing file yields empty settings
+func Load(path string) (*Settings, error) {
+	s := &Settings{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return &Settings{}, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return &Settings{}, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// validate checks the parts of the settings that JSON decoding cannot
+func (s *Settings) validate() error {
+	seen := make(map[string]bool)
+	for _, c := range s.Commands {
+		if c.Name == "" || c.Command == "" {
+			return fmt.Errorf("commands need both a name and a command")
+		}
+		if !validName.MatchString(c.Name) {
+			return fmt.Errorf("invalid command name %q: use letters, digits, '-' and '_'", c.Name)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate command name %q", c.Name)
+		}
+		seen[c.Name] = true
+		if _, err := c.TimeoutDuration(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SourcePaths returns the paths configured for the named source
+func (s *Settings) SourcePaths(name string) []string {
+	if s == nil {
+		return nil
+	}
+	return s.Sources[name].Paths
+}
+
+// SourceEnabled reports whether the named source is enabled, falling back to defaultEnabled
+// when the settings do not say
+func (s *Settings) SourceEnabled(name string, defaultEnabled bool) bool {
+	if s == nil {
+		return defaultEnabled
+	}
+	if src, ok := s.Sources[name]; ok && src.Enabled != nil {
+		return *src.Enabled
+	}
+	return defaultEnabled
+}
@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load on missing file returned error: %v", err)
+	}
+	if !s.SourceEnabled("config", true) || s.SourceEnabled("hosts", false) {
+		t.Fatalf("expected defaults to apply when settings are empty")
+	}
+}
+
+func TestLoad_SourceEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"sources": {"known_hosts": {"enabled": false}, "hosts": {"enabled": true}, "config": {}, "ansible": {"paths": ["~/inventory"]}}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		defaultEnabled bool
+		want           bool
+	}{
+		{"known_hosts", true, false},
+		{"hosts", false, true},
+		{"config", true, true},
+		{"unknown", false, false},
+	}
+	if paths := s.SourcePaths("ansible"); len(paths) != 1 || paths[0] != "~/inventory" {
+		t.Errorf("unexpected ansible paths: %v", paths)
+	}
+	for _, tt := range tests {
+		if got := s.SourceEnabled(tt.name, tt.defaultEnabled); got != tt.want {
+			t.Errorf("SourceEnabled(%q, %v) = %v, want %v", tt.name, tt.defaultEnabled, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected an error for invalid JSON")
+	}
+}
+
+func TestLoad_Commands(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", `{"commands": [{"name": "cmdb", "command": "/usr/local/bin/cmdb-export", "timeout": "30s"}]}`, false},
+		{"missing command", `{"commands": [{"name": "cmdb"}]}`, true},
+		{"bad name", `{"commands": [{"name": "../cmdb", "command": "x"}]}`, true},
+		{"duplicate", `{"commands": [{"name": "a", "command": "x"}, {"name": "a", "command": "y"}]}`, true},
+		{"bad timeout", `{"commands": [{"name": "a", "command": "x", "timeout": "soon"}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if d, _ := s.Commands[0].TimeoutDuration(); d.Seconds() != 30 {
+					t.Errorf("expected a 30s timeout, got %v", d)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ssh
+
+import "strings"
+
+// optionsWithArg lists the ssh flags that take an argument, as in ssh's usage text
+const optionsWithArg = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// Args is an ssh command line split into its parts
+type Args struct {
+	// Options holds the flags and their arguments, in order
+	Options []string
+	// Destination is the first non-option argument ("" if there is none)
+	Destination string
+	// Command holds the remote command and its arguments after the destination
+	Command []string
+	// standalone is set by flags that make ssh run without a destination (-V, -Q)
+	standalone bool
+}
+
+// ParseArgs splits ssh arguments (without the leading "ssh") like ssh's own option parser:
+// flags may be grouped ("-vvC"), and an option's argument may be attached ("-p2222") or follow
+// it ("-p 2222"). Parsing stops at "--" or at the first non-option argument, the destination.
+func ParseArgs(args []string) Args {
+	var a Args
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				a.Destination = args[i+1]
+				a.Command = args[i+2:]
+			}
+			return a
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			a.Destination = arg
+			a.Command = args[i+1:]
+			return a
+		}
+
+		a.Options = append(a.Options, arg)
+		if strings.ContainsAny(flagLetters(arg), "VQ") {
+			a.standalone = true
+		}
+		if OptionTakesNextArg(arg) && i+1 < len(args) {
+			i++
+			a.Options = append(a.Options, args[i])
+		}
+	}
+	return a
+}
+
+// OptionTakesNextArg reports whether the ssh option token arg (e.g. "-p" or "-vL") expects its
+// argument in the following token rather than attached to it
+func OptionTakesNextArg(arg string) bool {
+	letters := flagLetters(arg)
+	return letters != "" && strings.IndexByte(optionsWithArg, letters[len(letters)-1]) != -1 &&
+		len(letters) == len(arg)-1
+}
+
+// flagLetters returns the flags grouped in an option token, stopping after the first flag that
+// takes an argument since the rest of the token is that argument
+func flagLetters(arg string) string {
+	if len(arg) < 2 || arg[0] != '-' {
+		return ""
+	}
+	for j := 1; j < len(arg); j++ {
+		if strings.IndexByte(optionsWithArg, arg[j]) != -1 {
+			return arg[1 : j+1]
+		}
+	}
+	return arg[1:]
+}
+
+// NeedsDestination reports whether ssh would fail for lack of a destination, i.e. the
+// arguments hold only options and none of them makes ssh run on its own (-V, -Q)
+func (a Args) NeedsDestination() bool {
+	return a.Destination == "" && !a.standalone
+}
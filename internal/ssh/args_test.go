@@ -0,0 +1,36 @@
+package ssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args        []string
+		options     []string
+		destination string
+		command     []string
+		needsDest   bool
+	}{
+		{[]string{"user@host"}, nil, "user@host", []string{}, false},
+		{[]string{"-L", "8080:localhost:80"}, []string{"-L", "8080:localhost:80"}, "", nil, true},
+		{[]string{"-vC", "-p2222", "host", "uptime", "-a"}, []string{"-vC", "-p2222"}, "host", []string{"uptime", "-a"}, false},
+		{[]string{"-A", "-i", "~/.ssh/key"}, []string{"-A", "-i", "~/.ssh/key"}, "", nil, true},
+		{[]string{"-Cp", "2222", "host"}, []string{"-Cp", "2222"}, "host", []string{}, false},
+		{[]string{"-o", "User=root", "--", "-weird-host"}, []string{"-o", "User=root"}, "-weird-host", []string{}, false},
+		{[]string{"-V"}, []string{"-V"}, "", nil, false},
+		{[]string{"-Q", "cipher"}, []string{"-Q", "cipher"}, "", nil, false},
+		{[]string{"-p"}, []string{"-p"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		a := ParseArgs(tt.args)
+		if !reflect.DeepEqual(a.Options, tt.options) || a.Destination != tt.destination || !reflect.DeepEqual(a.Command, tt.command) {
+			t.Errorf("ParseArgs(%q) = %+v", tt.args, a)
+		}
+		if a.NeedsDestination() != tt.needsDest {
+			t.Errorf("ParseArgs(%q).NeedsDestination() = %v, want %v", tt.args, a.NeedsDestination(), tt.needsDest)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"regexp"
 	"ssh-tui/internal/types"
 	"strings"
 )
@@ -44,3 +45,19 @@ func BuildSSHCommand(host *types.SSHHost, options string) string {
 
 	return strings.Join(parts, " ")
 }
+
+// safeShellWord matches arguments that need no quoting in POSIX shells
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// JoinArgs joins args into a command line that a POSIX shell splits back into the same args
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if safeShellWord.MatchString(arg) {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -1,9 +1,11 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
@@ -12,23 +14,27 @@ import (
 	"ssh-tui/internal/parser"
 )
 
-// ExecuteSSHCommand executes the SSH command with proper handling for different platforms
+// ExecuteSSHCommand executes the SSH command with proper handling for different platforms.
+// The command is split at whitespace; use ExecuteSSH for arguments containing spaces.
 func ExecuteSSHCommand(command string) error {
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
 		return fmt.Errorf("empty command")
 	}
+	return ExecuteSSH(parts[1:])
+}
 
+// ExecuteSSH runs ssh with args, passed on as they are. Where ssh replaces the current process
+// it only returns if that fails; otherwise a non-zero exit status is returned as an *ExitError.
+func ExecuteSSH(args []string) error {
 	sshPath, err := exec.LookPath("ssh")
 	if err != nil {
 		return fmt.Errorf("ssh command not found in PATH: %w", err)
 	}
 
-	args := parts[1:]
-
 	// Print only the full command before executing so the user can see exactly what's run
-	if command != "ssh" {
-		fmt.Println("\x1b[1;36m" + strings.Join(parts, " ") + "\x1b[0m")
+	if len(args) > 0 {
+		fmt.Println("\x1b[1;36m" + JoinArgs(append([]string{"ssh"}, args...)) + "\x1b[0m")
 	}
 
 	// Execute based on platform
@@ -40,19 +46,67 @@ func ExecuteSSHCommand(command string) error {
 	}
 }
 
-// executeSSHWindows executes SSH on Windows
+// ExitError reports that ssh ran but exited with a non-zero status
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("ssh exited with status %d", e.Code)
+}
+
+// forwardedSignals are passed on to ssh when it runs as a child process
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// executeSSHWindows executes SSH on Windows, where the process cannot be replaced
 func executeSSHWindows(sshPath string, args []string) error {
+	return runSSH(sshPath, args)
+}
+
+// runSSH runs ssh as a child process, forwarding signals to it, and returns an *ExitError
+// carrying its exit status if it fails. A child killed by a signal is reported as 128+signal
+// like a shell would.
+func runSSH(sshPath string, args []string) error {
 	cmd := exec.Command(sshPath, args...)
 
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	err := cmd.Run()
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("SSH connection failed with exit code %d", exitError.ExitCode())
+	// Catch signals before starting so none can end ssh-tui while ssh is running
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to execute SSH command: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				// Not every signal can be sent on every platform (e.g. Windows only supports Kill);
+				// the console already delivers Ctrl+C to ssh there
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			code = 128 + int(status.Signal())
 		}
+		return &ExitError{Code: code}
+	}
+	if err != nil {
 		return fmt.Errorf("failed to execute SSH command: %w", err)
 	}
 
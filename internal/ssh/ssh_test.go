@@ -1,7 +1,10 @@
 package ssh
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"ssh-tui/internal/types"
@@ -72,6 +75,14 @@ func TestBuildSSHCommand_AdditionalCases(t *testing.T) {
 	}
 }
 
+func TestJoinArgs(t *testing.T) {
+	got := JoinArgs([]string{"ssh", "-p", "2222", "deploy@web.example.com", "-o", "ProxyCommand=nc %h %p", "it's"})
+	want := `ssh -p 2222 deploy@web.example.com -o 'ProxyCommand=nc %h %p' 'it'\''s'`
+	if got != want {
+		t.Errorf("JoinArgs = %s, want %s", got, want)
+	}
+}
+
 func TestCheckSSHAvailable_Failure(t *testing.T) {
 	// Save original PATH and clear it to force LookPath to fail
 	orig := os.Getenv("PATH")
@@ -167,3 +178,29 @@ func TestBuildSSHCommand_KnownHostWithDefaultPort(t *testing.T) {
 		t.Errorf("Expected '%s', got '%s'", expected, command)
 	}
 }
+
+func TestRunSSH_ExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ssh")
+	}
+	fake := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\nexit 7\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var exitErr *ExitError
+	if err := runSSH(fake, []string{"host"}); !errors.As(err, &exitErr) || exitErr.Code != 7 {
+		t.Fatalf("expected exit status 7, got %v", err)
+	}
+
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\nkill -TERM $$\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSSH(fake, nil); !errors.As(err, &exitErr) || exitErr.Code != 128+15 {
+		t.Fatalf("expected exit status 143 for SIGTERM, got %v", err)
+	}
+
+	if err := runSSH(filepath.Join(t.TempDir(), "missing"), nil); err == nil || errors.As(err, &exitErr) {
+		t.Fatalf("expected a start failure, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package sshagent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lifetimes are the key lifetimes offered when loading a key; 0 keeps the key until the agent
+// exits
+var Lifetimes = []time.Duration{0, 15 * time.Minute, time.Hour, 4 * time.Hour, 8 * time.Hour}
+
+// Constraints limit how the agent uses a loaded key
+type Constraints struct {
+	// Lifetime removes the key from the agent after the given time; 0 keeps it
+	Lifetime time.Duration
+	// Confirm makes the agent ask (through ssh-askpass) before each use of the key
+	Confirm bool
+}
+
+// String describes the constraints for display
+func (c Constraints) String() string {
+	var parts []string
+	switch {
+	case c.Lifetime <= 0:
+		parts = append(parts, "until the agent exits")
+	case c.Lifetime%time.Hour == 0:
+		parts = append(parts, fmt.Sprintf("for %dh", c.Lifetime/time.Hour))
+	case c.Lifetime%time.Minute == 0:
+		parts = append(parts, fmt.Sprintf("for %dm", c.Lifetime/time.Minute))
+	default:
+		parts = append(parts, "for "+c.Lifetime.String())
+	}
+	if c.Confirm {
+		parts = append(parts, "confirming each use")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AddArgs returns the ssh-add arguments loading the keys at paths with constraints c
+func AddArgs(paths []string, c Constraints) []string {
+	var args []string
+	if c.Lifetime > 0 {
+		args = append(args, "-t", strconv.Itoa(int(c.Lifetime.Seconds())))
+	}
+	if c.Confirm {
+		args = append(args, "-c")
+	}
+	return append(args, paths...)
+}
+
+// Add runs ssh-add in the terminal to load the keys at paths, so that it can ask for their
+// passphrases
+func Add(paths []string, c Constraints) error {
+	path, err := exec.LookPath("ssh-add")
+	if err != nil {
+		return fmt.Errorf("ssh-add command not found in PATH: %w", err)
+	}
+	args := AddArgs(paths, c)
+	fmt.Println("\x1b[1;36m" + "ssh-add " + strings.Join(args, " ") + "\x1b[0m")
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ssh-add failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package sshagent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyFile is an IdentityFile of a host and whether the agent holds its key
+type KeyFile struct {
+	// Path is the resolved path of the private key
+	Path        string
+	Fingerprint string
+	Loaded      bool
+	// Err is set when the key's fingerprint cannot be read; the key cannot be compared
+	Err error
+}
+
+// Report is what the agent holds for a host
+type Report struct {
+	Socket     string
+	Identities []Identity
+	Files      []KeyFile
+	// Err is set when the agent cannot be reached
+	Err error
+}
+
+// Missing returns the paths of the key files that exist but are not loaded
+func (r Report) Missing() []string {
+	var paths []string
+	for _, f := range r.Files {
+		if f.Err == nil && !f.Loaded {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// Check compares the host's IdentityFile keys with the identities held by the agent at socket
+func Check(socket string, host types.SSHHost) Report {
+	r := Report{Socket: socket}
+	paths := IdentityFiles(host)
+	r.Identities, r.Err = List(socket)
+
+	loaded := make(map[string]bool, len(r.Identities))
+	for _, id := range r.Identities {
+		loaded[id.Fingerprint] = true
+	}
+	for _, path := range paths {
+		f := KeyFile{Path: path}
+		f.Fingerprint, f.Err = Fingerprint(path)
+		f.Loaded = f.Err == nil && loaded[f.Fingerprint]
+		r.Files = append(r.Files, f)
+	}
+	return r
+}
+
+// supported removes the tokens IdentityFiles expands, leaving a % only for the others
+var supported = strings.NewReplacer("%%", "", "%d", "", "%h", "", "%r", "", "%u", "")
+
+// IdentityFiles returns the host's IdentityFile paths with ~ and the %d, %h, %r, %u and %%
+// tokens expanded; paths using other tokens or environment variables are left out
+func IdentityFiles(host types.SSHHost) []string {
+	home, _ := os.UserHomeDir()
+	localUser := ""
+	if u, err := user.Current(); err == nil {
+		localUser = u.Username
+	}
+	hostName := host.HostName
+	if hostName == "" {
+		hostName = host.Name
+	}
+	remoteUser := host.User
+	if remoteUser == "" {
+		remoteUser = localUser
+	}
+	replacer := strings.NewReplacer("%%", "%", "%d", home, "%h", hostName, "%r", remoteUser, "%u", localUser)
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, d := range host.Directives {
+		if !strings.EqualFold(d.Key, "identityfile") {
+			continue
+		}
+		path := strings.Trim(d.Value, "\"")
+		if strings.EqualFold(path, "none") || strings.Contains(path, "${") {
+			continue
+		}
+		if strings.Contains(supported.Replace(path), "%") {
+			continue
+		}
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			path = home + path[1:]
+		}
+		path = filepath.Clean(replacer.Replace(path))
+		if !filepath.IsAbs(path) || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Fingerprint returns the SHA256 fingerprint of the private key at path, read from its .pub file
+// or, failing that, from the key itself; the public part of OpenSSH keys is readable without the
+// passphrase
+func Fingerprint(path string) (string, error) {
+	if data, err := os.ReadFile(path + ".pub"); err == nil {
+		if key, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+			return ssh.FingerprintSHA256(key), nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return ssh.FingerprintSHA256(signer.PublicKey()), nil
+	case errors.As(err, &missing) && missing.PublicKey != nil:
+		return ssh.FingerprintSHA256(missing.PublicKey), nil
+	default:
+		return "", fmt.Errorf("cannot read the public key of %s: %w", path, err)
+	}
+}
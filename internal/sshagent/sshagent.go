@@ -0,0 +1,76 @@
+package sshagent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// DefaultTimeout bounds connecting to the agent
+const DefaultTimeout = 2 * time.Second
+
+// windowsPipe is where the OpenSSH agent service listens on Windows
+const windowsPipe = `\\.\pipe\openssh-ssh-agent`
+
+// ErrNoAgent is returned when SSH_AUTH_SOCK is not set
+var ErrNoAgent = errors.New("SSH_AUTH_SOCK is not set; no ssh-agent is running")
+
+// Identity is a key held by the agent
+type Identity struct {
+	Type        string
+	Fingerprint string
+	Comment     string
+}
+
+// Socket returns the agent socket: SSH_AUTH_SOCK, or the OpenSSH agent's pipe on Windows
+func Socket() string {
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" || runtime.GOOS != "windows" {
+		return socket
+	}
+	return windowsPipe
+}
+
+// List connects to the agent at socket and returns the identities it holds
+func List(socket string) ([]Identity, error) {
+	conn, err := dial(socket)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	keys, err := agent.NewClient(conn).List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the agent's keys: %w", err)
+	}
+	identities := make([]Identity, 0, len(keys))
+	for _, k := range keys {
+		identities = append(identities, Identity{Type: k.Type(), Fingerprint: ssh.FingerprintSHA256(k), Comment: k.Comment})
+	}
+	return identities, nil
+}
+
+// dial connects to the agent; Windows named pipes are opened as files
+func dial(socket string) (io.ReadWriteCloser, error) {
+	if socket == "" {
+		return nil, ErrNoAgent
+	}
+	if socket == windowsPipe {
+		f, err := os.OpenFile(socket, os.O_RDWR, 0)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to the agent: %w", err)
+		}
+		return f, nil
+	}
+	conn, err := net.DialTimeout("unix", socket, DefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to the agent at %s: %w", socket, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,192 @@
+package sshagent
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/types"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// startAgent serves an in-memory keyring on a socket holding keys and returns the socket path
+func startAgent(t *testing.T, keys ...ed25519.PrivateKey) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test agent listens on a Unix socket")
+	}
+	keyring := agent.NewKeyring()
+	for _, k := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: k, Comment: "test@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	return socket
+}
+
+// writeKey writes key as an OpenSSH private key at path, encrypted when passphrase is set
+func writeKey(t *testing.T, path string, key ed25519.PrivateKey, passphrase string) {
+	t.Helper()
+	var block *pem.Block
+	var err error
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// generateKey returns a new ed25519 private key and its fingerprint
+func generateKey(t *testing.T) (ed25519.PrivateKey, string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, ssh.FingerprintSHA256(pub)
+}
+
+func TestList(t *testing.T) {
+	key, fingerprint := generateKey(t)
+	identities, err := List(startAgent(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Identity{{Type: ssh.KeyAlgoED25519, Fingerprint: fingerprint, Comment: "test@example.com"}}
+	if !reflect.DeepEqual(identities, want) {
+		t.Errorf("got %+v, want %+v", identities, want)
+	}
+
+	if _, err := List(""); err != ErrNoAgent {
+		t.Errorf("expected ErrNoAgent without a socket, got %v", err)
+	}
+	if _, err := List(filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Errorf("expected an error for a missing socket")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	loadedKey, _ := generateKey(t)
+	otherKey, otherFingerprint := generateKey(t)
+	writeKey(t, filepath.Join(dir, "id_loaded"), loadedKey, "")
+	// The public part of an encrypted key is read without its passphrase
+	writeKey(t, filepath.Join(dir, "id_other"), otherKey, "secret")
+
+	host := types.SSHHost{Name: "web", Directives: []types.Directive{
+		{Key: "IdentityFile", Value: filepath.Join(dir, "id_loaded")},
+		{Key: "IdentityFile", Value: filepath.Join(dir, "id_other")},
+		{Key: "IdentityFile", Value: filepath.Join(dir, "id_missing")},
+	}}
+	r := Check(startAgent(t, loadedKey), host)
+	if r.Err != nil || len(r.Identities) != 1 || len(r.Files) != 3 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if !r.Files[0].Loaded || r.Files[1].Loaded || r.Files[1].Fingerprint != otherFingerprint || r.Files[2].Err == nil {
+		t.Errorf("unexpected key files: %+v", r.Files)
+	}
+	if missing := r.Missing(); !reflect.DeepEqual(missing, []string{filepath.Join(dir, "id_other")}) {
+		t.Errorf("expected only id_other to be missing, got %v", missing)
+	}
+
+	// Without an agent nothing is loaded
+	r = Check("", host)
+	if r.Err != ErrNoAgent || len(r.Missing()) != 2 {
+		t.Errorf("unexpected report without an agent: %+v", r)
+	}
+}
+
+func TestFingerprint_PublicKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	key, fingerprint := generateKey(t)
+	pub, _ := ssh.NewPublicKey(key.Public())
+	path := filepath.Join(dir, "id_ed25519")
+	// The private key is unreadable, so the fingerprint must come from the .pub file
+	if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".pub", ssh.MarshalAuthorizedKey(pub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Fingerprint(path); err != nil || got != fingerprint {
+		t.Errorf("got %q, %v; want %q", got, err, fingerprint)
+	}
+}
+
+func TestIdentityFiles(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	host := types.SSHHost{Name: "web", HostName: "web.example.com", User: "deploy", Directives: []types.Directive{
+		{Key: "IdentityFile", Value: "~/.ssh/id_web"},
+		{Key: "identityfile", Value: "\"%d/.ssh/%h-%r\""},
+		{Key: "IdentityFile", Value: "~/.ssh/id_web"},
+		{Key: "IdentityFile", Value: "~/.ssh/%C"},
+		{Key: "IdentityFile", Value: "${HOME}/.ssh/id_env"},
+		{Key: "IdentityFile", Value: "none"},
+		{Key: "IdentityFile", Value: "relative"},
+		{Key: "User", Value: "deploy"},
+	}}
+	want := []string{
+		filepath.Join(home, ".ssh", "id_web"),
+		filepath.Join(home, ".ssh", "web.example.com-deploy"),
+	}
+	if got := IdentityFiles(host); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAddArgs(t *testing.T) {
+	got := strings.Join(AddArgs([]string{"/k1", "/k2"}, Constraints{Lifetime: time.Hour, Confirm: true}), " ")
+	if got != "-t 3600 -c /k1 /k2" {
+		t.Errorf("unexpected arguments %q", got)
+	}
+	if got := strings.Join(AddArgs([]string{"/k"}, Constraints{}), " "); got != "/k" {
+		t.Errorf("unexpected arguments %q", got)
+	}
+	if got := (Constraints{Lifetime: time.Hour, Confirm: true}).String(); got != "for 1h, confirming each use" {
+		t.Errorf("unexpected description %q", got)
+	}
+	if got := (Constraints{Lifetime: 30 * time.Minute}).String(); got != "for 30m" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
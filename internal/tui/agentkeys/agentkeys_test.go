@@ -0,0 +1,76 @@
+package agentkeys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/sshagent"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAgentKeysModel(t *testing.T) {
+	identities := []sshagent.Identity{
+		{Type: "ssh-ed25519", Fingerprint: "SHA256:first", Comment: "me@laptop"},
+	}
+	calls := 0
+	m := NewAgentKeysModel("/tmp/agent.sock", func() ([]sshagent.Identity, error) {
+		calls++
+		return identities, nil
+	})
+	m.height = 30
+	if !strings.Contains(m.View(), "Reading the agent's keys") {
+		t.Fatalf("expected the keys to be read first:\n%s", m.View())
+	}
+
+	m.Update(m.Init()())
+	view := m.View()
+	for _, want := range []string{"/tmp/agent.sock", "ssh-ed25519", "SHA256:first", "me@laptop", "1 key loaded"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// r reads the keys again
+	identities = append(identities, sshagent.Identity{Type: "ssh-rsa", Fingerprint: "SHA256:second"})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatalf("expected r to refresh")
+	}
+	m.Update(cmd())
+	if calls != 2 || !strings.Contains(m.View(), "2 keys loaded") {
+		t.Errorf("expected the refreshed keys:\n%s", m.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.IsClosed() {
+		t.Errorf("expected Esc to close the panel")
+	}
+}
+
+func TestAgentKeysModel_Errors(t *testing.T) {
+	m := NewAgentKeysModel("", func() ([]sshagent.Identity, error) {
+		return nil, sshagent.ErrNoAgent
+	})
+	m.Update(m.Init()())
+	if view := m.View(); !strings.Contains(view, "SSH_AUTH_SOCK is not set") || !strings.Contains(view, "ssh-agent") {
+		t.Errorf("expected the missing agent to be reported:\n%s", view)
+	}
+
+	m = NewAgentKeysModel("/tmp/agent.sock", func() ([]sshagent.Identity, error) {
+		return nil, errors.New("connection refused")
+	})
+	m.Update(m.Init()())
+	if view := m.View(); !strings.Contains(view, "connection refused") || strings.Contains(view, "eval") {
+		t.Errorf("expected only the connection error:\n%s", view)
+	}
+
+	m = NewAgentKeysModel("/tmp/agent.sock", func() ([]sshagent.Identity, error) {
+		return nil, nil
+	})
+	m.Update(m.Init()())
+	if !strings.Contains(m.View(), "holds no keys") {
+		t.Errorf("expected an empty agent to be reported:\n%s", m.View())
+	}
+}
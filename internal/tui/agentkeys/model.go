@@ -0,0 +1,51 @@
+package agentkeys
+
+import (
+	"ssh-tui/internal/sshagent"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listMsg delivers the identities read from the agent
+type listMsg struct {
+	identities []sshagent.Identity
+	err        error
+}
+
+// AgentKeysModel represents the panel listing the identities held by ssh-agent
+type AgentKeysModel struct {
+	socket string
+	// list reads the agent's identities; tests replace it
+	list       func() ([]sshagent.Identity, error)
+	identities []sshagent.Identity
+	err        error
+	loading    bool
+	cursor     int
+	closed     bool
+	width      int
+	height     int
+}
+
+// NewAgentKeysModel creates the panel for the agent at socket, reading its identities with list
+func NewAgentKeysModel(socket string, list func() ([]sshagent.Identity, error)) *AgentKeysModel {
+	return &AgentKeysModel{socket: socket, list: list, loading: true}
+}
+
+// Init implements the tea.Model interface
+func (m *AgentKeysModel) Init() tea.Cmd {
+	return m.load()
+}
+
+// IsClosed reports whether the user closed the panel to return to host selection
+func (m *AgentKeysModel) IsClosed() bool {
+	return m.closed
+}
+
+// load returns the command reading the agent's identities
+func (m *AgentKeysModel) load() tea.Cmd {
+	list := m.list
+	return func() tea.Msg {
+		identities, err := list()
+		return listMsg{identities: identities, err: err}
+	}
+}
@@ -0,0 +1,47 @@
+package agentkeys
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Update implements the tea.Model interface for the agent panel
+func (m *AgentKeysModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case listMsg:
+		m.loading = false
+		m.identities, m.err = msg.identities, msg.err
+		if m.cursor >= len(m.identities) {
+			m.cursor = max(0, len(m.identities)-1)
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "q":
+			m.closed = true
+			return m, tea.Quit
+
+		case "r":
+			if !m.loading {
+				m.loading = true
+				return m, m.load()
+			}
+
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down":
+			if m.cursor < len(m.identities)-1 {
+				m.cursor++
+			}
+		}
+	}
+
+	return m, nil
+}
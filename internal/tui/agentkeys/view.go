@@ -0,0 +1,58 @@
+package agentkeys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/sshagent"
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+)
+
+// typeWidth is the width of the key type column
+const typeWidth = 20
+
+// View implements the tea.Model interface for the agent panel
+func (m *AgentKeysModel) View() string {
+	var b strings.Builder
+
+	title := ui.TitleStyle.Render("SSH agent")
+	if m.socket != "" {
+		title += ui.InstructionStyle.Render(" · " + helpers.ShortenPath(m.socket))
+	}
+	b.WriteString(title + "\n\n")
+
+	switch {
+	case m.loading && m.identities == nil && m.err == nil:
+		b.WriteString(ui.InstructionStyle.Render("Reading the agent's keys…") + "\n")
+	case m.err != nil:
+		b.WriteString(ui.ErrorStyle.Render(m.err.Error()) + "\n")
+		if errors.Is(m.err, sshagent.ErrNoAgent) {
+			b.WriteString(ui.InstructionStyle.Render("Start one with: eval \"$(ssh-agent)\"") + "\n")
+		}
+	case len(m.identities) == 0:
+		b.WriteString(ui.WarningStyle.Render("The agent holds no keys; load one with ssh-add") + "\n")
+	default:
+		b.WriteString(ui.DetailTextStyle.Render(fmt.Sprintf("  %-*s %-50s %s", typeWidth, "Key type", "Fingerprint", "Comment")) + "\n")
+		start, end := helpers.ScrollRange(len(m.identities), m.cursor, max(3, m.height-7))
+		for i := start; i < end; i++ {
+			id := m.identities[i]
+			row := fmt.Sprintf("%-*s %-50s %s", typeWidth, id.Type, id.Fingerprint, id.Comment)
+			if i == m.cursor {
+				b.WriteString(ui.SelectedTextStyle.Render("> "+row) + "\n")
+			} else {
+				b.WriteString(ui.NormalStyle.Render("  "+row) + "\n")
+			}
+		}
+		count := fmt.Sprintf("%d keys loaded", len(m.identities))
+		if len(m.identities) == 1 {
+			count = "1 key loaded"
+		}
+		b.WriteString(ui.InstructionStyle.Render(count) + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(ui.InstructionStyle.Render("Use ↑/↓ to navigate, r to refresh, Esc to go back"))
+	return b.String()
+}
@@ -1,6 +1,9 @@
 package helpers
 
 import (
+	"os"
+	"strings"
+
 	"ssh-tui/internal/parser"
 	"ssh-tui/internal/types"
 
@@ -22,24 +25,11 @@ func BuildCustomHost(raw string) types.SSHHost {
 
 // ScrollRange calculates start and end indices for a list given a desired max visible count
 func ScrollRange(listLen, cursor, maxVisible int) (start, end int) {
-	if maxVisible <= 0 {
-		return 0, listLen
-	}
-	if listLen <= maxVisible {
-		return 0, listLen
-	}
-	start = 0
-	if cursor >= maxVisible/2 {
-		start = cursor - maxVisible/2
-		if start > listLen-maxVisible {
-			start = listLen - maxVisible
-		}
+	heights := make([]int, listLen)
+	for i := range heights {
+		heights[i] = 1
 	}
-	end = start + maxVisible
-	if end > listLen {
-		end = listLen
-	}
-	return start, end
+	return ScrollRangeRows(heights, cursor, maxVisible)
 }
 
 // DeleteWordBackwards deletes the word immediately before the cursor in s and returns the new string and new cursor position.
@@ -99,3 +89,70 @@ func RenderInputWithCursor(s string, cursor int, width int) string {
 
 	return left + cursorGlyph + right
 }
+
+// ShortenPath replaces the user's home directory prefix in path with ~
+func ShortenPath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" {
+		return path
+	}
+	if path == homeDir {
+		return "~"
+	}
+	if strings.HasPrefix(path, homeDir+string(os.PathSeparator)) {
+		return "~" + path[len(homeDir):]
+	}
+	return path
+}
+
+// ScrollRangeRows calculates the window of rows to show when rows have different heights
+// (e.g. group headers mixed with host entries, or a focused entry drawn with a border).
+// It returns start and end row indices such that the rows fit in maxLines, keeping the
+// cursor row visible and roughly centered.
+func ScrollRangeRows(rowHeights []int, cursor, maxLines int) (start, end int) {
+	n := len(rowHeights)
+	if n == 0 {
+		return 0, 0
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor >= n {
+		cursor = n - 1
+	}
+
+	total := 0
+	for _, h := range rowHeights {
+		total += h
+	}
+	if maxLines <= 0 || total <= maxLines {
+		return 0, n
+	}
+
+	start, end = cursor, cursor+1
+	used := rowHeights[cursor]
+	usedAbove := 0
+	for {
+		grew := false
+		// Fill up to half the space above the cursor first, then below, then any remaining space above
+		if start > 0 && usedAbove < maxLines/2 && used+rowHeights[start-1] <= maxLines {
+			start--
+			used += rowHeights[start]
+			usedAbove += rowHeights[start]
+			grew = true
+		}
+		if end < n && used+rowHeights[end] <= maxLines {
+			used += rowHeights[end]
+			end++
+			grew = true
+		} else if start > 0 && used+rowHeights[start-1] <= maxLines {
+			start--
+			used += rowHeights[start]
+			usedAbove += rowHeights[start]
+			grew = true
+		}
+		if !grew {
+			return start, end
+		}
+	}
+}
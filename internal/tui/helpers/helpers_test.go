@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"path/filepath"
 	"ssh-tui/internal/types"
 	"testing"
 )
@@ -65,3 +66,47 @@ func TestDeleteWordBackwards(t *testing.T) {
 		}
 	}
 }
+
+func TestShortenPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cases := []struct {
+		in, want string
+	}{
+		{home, "~"},
+		{filepath.Join(home, ".ssh", "config"), filepath.Join("~", ".ssh", "config")},
+		{"/etc/ssh/ssh_config", "/etc/ssh/ssh_config"},
+		{home + "other/file", home + "other/file"},
+	}
+
+	for _, c := range cases {
+		if got := ShortenPath(c.in); got != c.want {
+			t.Fatalf("ShortenPath(%q) = %q want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestScrollRangeRows(t *testing.T) {
+	cases := []struct {
+		heights            []int
+		cursor, maxLines   int
+		wantStart, wantEnd int
+	}{
+		{nil, 0, 5, 0, 0},
+		{[]int{1, 1, 3}, 2, 10, 0, 3},
+		// header, item, focused item (3 lines), item, header, item
+		{[]int{1, 1, 3, 1, 1, 1}, 2, 5, 1, 4},
+		{[]int{1, 1, 3, 1, 1, 1}, 0, 4, 0, 2},
+		{[]int{1, 1, 1, 1, 1, 3}, 5, 4, 4, 6},
+		// A focused row taller than the budget is still shown
+		{[]int{1, 4, 1}, 1, 2, 1, 2},
+	}
+
+	for _, c := range cases {
+		s, e := ScrollRangeRows(c.heights, c.cursor, c.maxLines)
+		if s != c.wantStart || e != c.wantEnd {
+			t.Fatalf("ScrollRangeRows(%v,%d,%d) = %d,%d want %d,%d", c.heights, c.cursor, c.maxLines, s, e, c.wantStart, c.wantEnd)
+		}
+	}
+}
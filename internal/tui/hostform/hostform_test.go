@@ -0,0 +1,105 @@
+package hostform
+
+import (
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeString(m tea.Model, s string) tea.Model {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestHostFormModel_CreateFromCustomHost(t *testing.T) {
+	host := &types.SSHHost{Name: "deploy@app.example.com", HostName: "app.example.com", User: "deploy", Port: types.DefaultSSHPort, Source: types.SourceCustom}
+	model := NewCreateModel(host)
+
+	block := model.Block()
+	if strings.Join(block.Patterns, " ") != "app.example.com" {
+		t.Fatalf("unexpected patterns: %v", block.Patterns)
+	}
+	if len(block.Directives) != 2 || block.Directives[0].Key != "HostName" || block.Directives[1].Value != "deploy" {
+		t.Fatalf("unexpected directives: %+v", block.Directives)
+	}
+
+	// Rename the host and add a directive in the blank row
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	updatedModel = typeString(updatedModel, "app")
+	for i := 0; i < 3; i++ {
+		updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	updatedModel = typeString(updatedModel, "Port=2222")
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	formModel := updatedModel.(*HostFormModel)
+
+	if !formModel.IsConfirmed() {
+		t.Fatalf("expected form to be confirmed, error: %q", formModel.err)
+	}
+	block = formModel.Block()
+	if block.Patterns[0] != "app" || len(block.Directives) != 3 || block.Directives[2].Key != "Port" || block.Directives[2].Value != "2222" {
+		t.Fatalf("unexpected block after editing: %+v", block)
+	}
+}
+
+func TestHostFormModel_Validation(t *testing.T) {
+	model := NewCreateModel(nil)
+
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	formModel := updatedModel.(*HostFormModel)
+	if formModel.IsConfirmed() || formModel.err == "" {
+		t.Fatalf("expected an error for a missing host name")
+	}
+
+	updatedModel = typeString(formModel, "box")
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel = typeString(updatedModel, "Port 99999")
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	formModel = updatedModel.(*HostFormModel)
+	if formModel.IsConfirmed() || !strings.Contains(formModel.err, "Port") {
+		t.Fatalf("expected a port error, got %q", formModel.err)
+	}
+	if !strings.Contains(formModel.View(), "Invalid Port") {
+		t.Fatalf("expected the error to be shown in the view")
+	}
+}
+
+func TestHostFormModel_EditAndDelete(t *testing.T) {
+	host := &types.SSHHost{
+		Name:       "db",
+		Aliases:    []string{"pg"},
+		Source:     types.SourceConfig,
+		SourceFile: "/tmp/config",
+		SourceLine: 3,
+		Directives: []types.Directive{{Key: "HostName", Value: "db.example.com"}, {Key: "User", Value: "postgres"}},
+	}
+
+	edit := NewEditModel(host)
+	block := edit.Block()
+	if strings.Join(block.Patterns, " ") != "db pg" || len(block.Directives) != 2 {
+		t.Fatalf("unexpected edit block: %+v", block)
+	}
+	if !strings.Contains(edit.View(), "Edit host db") {
+		t.Fatalf("expected edit title in view")
+	}
+
+	del := NewDeleteModel(host)
+	if !strings.Contains(del.View(), "Delete host db?") {
+		t.Fatalf("expected delete confirmation in view")
+	}
+	updatedModel, _ := del.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !updatedModel.(*HostFormModel).IsConfirmed() {
+		t.Fatalf("expected deletion to be confirmed")
+	}
+
+	del = NewDeleteModel(host)
+	updatedModel, _ = del.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !updatedModel.(*HostFormModel).IsCancelled() {
+		t.Fatalf("expected deletion to be cancelled")
+	}
+}
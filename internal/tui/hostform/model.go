@@ -0,0 +1,178 @@
+package hostform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Mode selects what the host form does with the config file
+type Mode int
+
+const (
+	// ModeCreate adds a new Host block
+	ModeCreate Mode = iota
+	// ModeEdit rewrites an existing Host block
+	ModeEdit
+	// ModeDelete removes an existing Host block after confirmation
+	ModeDelete
+)
+
+// HostFormModel represents the screen for creating, editing or deleting a Host block.
+// Field 0 holds the Host names; every following field holds one "Keyword value" directive.
+type HostFormModel struct {
+	mode      Mode
+	host      *types.SSHHost
+	fields    []string
+	focus     int
+	cursor    int
+	err       string
+	confirmed bool
+	cancelled bool
+	width     int
+	height    int
+}
+
+// NewCreateModel creates a form for a new Host block, prefilled from host when it is not nil
+// (e.g. a custom host typed into search or a known_hosts entry)
+func NewCreateModel(host *types.SSHHost) *HostFormModel {
+	m := &HostFormModel{mode: ModeCreate, host: host, fields: []string{""}}
+	if host != nil {
+		hostName := host.HostName
+		if hostName == "" {
+			_, hostName = parser.ParseUserHost(host.Name)
+		}
+		m.fields[0] = hostName
+		if hostName != "" {
+			m.fields = append(m.fields, "HostName "+hostName)
+		}
+		if host.User != "" {
+			m.fields = append(m.fields, "User "+host.User)
+		}
+		if host.Port != "" && host.Port != types.DefaultSSHPort {
+			m.fields = append(m.fields, "Port "+host.Port)
+		}
+	}
+	m.ensureBlankRow()
+	m.cursor = len(m.fields[0])
+	return m
+}
+
+// NewEditModel creates a form for editing the config Host block of host
+func NewEditModel(host *types.SSHHost) *HostFormModel {
+	m := &HostFormModel{mode: ModeEdit, host: host}
+	m.fields = append(m.fields, strings.Join(append([]string{host.Name}, host.Aliases...), " "))
+	for _, d := range host.Directives {
+		m.fields = append(m.fields, d.Key+" "+d.Value)
+	}
+	m.ensureBlankRow()
+	m.cursor = len(m.fields[0])
+	return m
+}
+
+// NewDeleteModel creates a confirmation screen for deleting the config Host block of host
+func NewDeleteModel(host *types.SSHHost) *HostFormModel {
+	return &HostFormModel{mode: ModeDelete, host: host}
+}
+
+// Init implements the tea.Model interface
+func (m *HostFormModel) Init() tea.Cmd {
+	return nil
+}
+
+// Mode returns what the form was opened for
+func (m *HostFormModel) Mode() Mode {
+	return m.mode
+}
+
+// Host returns the host the form was opened for (nil when creating from scratch)
+func (m *HostFormModel) Host() *types.SSHHost {
+	return m.host
+}
+
+// IsConfirmed returns whether the user saved the form or confirmed the deletion
+func (m *HostFormModel) IsConfirmed() bool {
+	return m.confirmed
+}
+
+// IsCancelled returns whether the user left the form without saving
+func (m *HostFormModel) IsCancelled() bool {
+	return m.cancelled
+}
+
+// Block returns the Host block described by the form fields
+func (m *HostFormModel) Block() parser.HostBlock {
+	var block parser.HostBlock
+	if len(m.fields) == 0 {
+		return block
+	}
+	block.Patterns = strings.Fields(m.fields[0])
+	for _, field := range m.fields[1:] {
+		key, value := splitField(field)
+		if key == "" {
+			continue
+		}
+		block.Directives = append(block.Directives, types.Directive{Key: key, Value: value})
+	}
+	return block
+}
+
+// ensureBlankRow keeps an empty directive row at the end of the form for adding directives
+func (m *HostFormModel) ensureBlankRow() {
+	if len(m.fields) < 2 || strings.TrimSpace(m.fields[len(m.fields)-1]) != "" {
+		m.fields = append(m.fields, "")
+	}
+}
+
+// validate checks the form fields, returning a message describing the first problem found
+func (m *HostFormModel) validate() string {
+	names := strings.Fields(m.fields[0])
+	if len(names) == 0 {
+		return "Host name is required"
+	}
+	if strings.ContainsAny(names[0], "*?!") {
+		return "The primary host name cannot be a pattern"
+	}
+	if !parser.IsValidSSHOption(m.fields[0]) {
+		return "Host names contain invalid characters"
+	}
+
+	for _, field := range m.fields[1:] {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		key, value := splitField(field)
+		if value == "" {
+			return fmt.Sprintf("Directive %q needs a value", key)
+		}
+		switch strings.ToLower(key) {
+		case "host", "match":
+			return fmt.Sprintf("%s cannot be used inside a Host block", key)
+		case "hostname":
+			if !parser.IsValidHost(value) {
+				return fmt.Sprintf("Invalid HostName: %s", value)
+			}
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Sprintf("Invalid Port: %s", value)
+			}
+		}
+	}
+	return ""
+}
+
+// splitField splits a "Keyword value" field, also accepting the "Keyword=value" form
+func splitField(field string) (key, value string) {
+	field = strings.TrimSpace(field)
+	end := strings.IndexAny(field, " \t=")
+	if end == -1 {
+		return field, ""
+	}
+	return field[:end], strings.TrimSpace(strings.TrimLeft(field[end:], " \t="))
+}
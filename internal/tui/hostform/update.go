@@ -0,0 +1,128 @@
+package hostform
+
+import (
+	"ssh-tui/internal/tui/helpers"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Update implements the tea.Model interface for the host form
+func (m *HostFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.mode == ModeDelete {
+			return m.updateDelete(msg)
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc":
+			m.cancelled = true
+			return m, tea.Quit
+
+		case "ctrl+s":
+			if problem := m.validate(); problem != "" {
+				m.err = problem
+				return m, nil
+			}
+			m.confirmed = true
+			return m, tea.Quit
+
+		case "up", "shift+tab":
+			m.moveFocus(-1)
+
+		case "down", "tab", "enter":
+			m.moveFocus(1)
+
+		case "left", "ctrl+b":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "right", "ctrl+f":
+			if m.cursor < len(m.fields[m.focus]) {
+				m.cursor++
+			}
+
+		case "home", "ctrl+a":
+			m.cursor = 0
+
+		case "end", "ctrl+e":
+			m.cursor = len(m.fields[m.focus])
+
+		case "backspace", "ctrl+h":
+			field := m.fields[m.focus]
+			if m.cursor > 0 && len(field) > 0 {
+				m.setField(field[:m.cursor-1]+field[m.cursor:], m.cursor-1)
+			}
+
+		case "delete", "ctrl+d":
+			field := m.fields[m.focus]
+			if m.cursor < len(field) {
+				m.setField(field[:m.cursor]+field[m.cursor+1:], m.cursor)
+			}
+
+		case "ctrl+u":
+			// Delete from cursor to beginning
+			m.setField(m.fields[m.focus][m.cursor:], 0)
+
+		case "ctrl+k":
+			// Delete from cursor to end
+			m.setField(m.fields[m.focus][:m.cursor], m.cursor)
+
+		case "ctrl+w":
+			// Delete word backwards
+			if m.cursor > 0 {
+				m.setField(helpers.DeleteWordBackwards(m.fields[m.focus], m.cursor))
+			}
+
+		default:
+			// Handle regular character input
+			if len(msg.String()) == 1 {
+				field := m.fields[m.focus]
+				m.setField(field[:m.cursor]+msg.String()+field[m.cursor:], m.cursor+1)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// updateDelete handles keys on the delete confirmation screen
+func (m *HostFormModel) updateDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.confirmed = true
+		return m, tea.Quit
+	case "n", "N", "esc":
+		m.cancelled = true
+		return m, tea.Quit
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// moveFocus moves focus by delta fields, placing the cursor at the end of the newly focused field
+func (m *HostFormModel) moveFocus(delta int) {
+	focus := m.focus + delta
+	if focus < 0 || focus >= len(m.fields) {
+		return
+	}
+	m.focus = focus
+	m.cursor = len(m.fields[m.focus])
+}
+
+// setField replaces the focused field's content and cursor, clearing any validation error
+func (m *HostFormModel) setField(value string, cursor int) {
+	m.fields[m.focus] = value
+	m.cursor = cursor
+	m.err = ""
+	m.ensureBlankRow()
+}
@@ -0,0 +1,84 @@
+package hostform
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// View implements the tea.Model interface for the host form
+func (m *HostFormModel) View() string {
+	if m.mode == ModeDelete {
+		return m.viewDelete()
+	}
+
+	var b strings.Builder
+
+	title := "New host"
+	if m.mode == ModeEdit {
+		title = "Edit host " + m.host.Name
+	}
+	b.WriteString(ui.TitleStyle.Render(title) + "\n\n")
+
+	if m.host != nil && m.host.SourceFile != "" && m.mode == ModeEdit {
+		location := fmt.Sprintf("%s:%d", helpers.ShortenPath(m.host.SourceFile), m.host.SourceLine)
+		b.WriteString(ui.DetailTextStyle.Render("Editing "+location) + "\n\n")
+	}
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86")).
+		Bold(true)
+
+	b.WriteString(labelStyle.Render("Host") + "\n")
+	b.WriteString(m.renderField(0) + "\n\n")
+
+	b.WriteString(labelStyle.Render("Directives") + "\n")
+	for i := 1; i < len(m.fields); i++ {
+		b.WriteString(m.renderField(i) + "\n")
+	}
+
+	if m.err != "" {
+		b.WriteString("\n" + ui.ErrorStyle.Render(m.err) + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(ui.InstructionStyle.Render(ui.InstructionHostForm))
+
+	return b.String()
+}
+
+// renderField renders a single form field, showing the cursor when it is focused
+func (m *HostFormModel) renderField(i int) string {
+	if i == m.focus {
+		return ui.SearchStyle.Render("> ") + helpers.RenderInputWithCursor(m.fields[i], m.cursor, 60)
+	}
+	if m.fields[i] == "" {
+		return "  " + ui.DetailTextStyle.Render("(new directive, e.g. IdentityFile ~/.ssh/id_ed25519)")
+	}
+	return "  " + ui.NormalStyle.Render(m.fields[i])
+}
+
+// viewDelete renders the delete confirmation screen
+func (m *HostFormModel) viewDelete() string {
+	var b strings.Builder
+
+	b.WriteString(ui.ErrorStyle.Render("Delete host "+m.host.Name+"?") + "\n\n")
+	if m.host.SourceFile != "" {
+		location := fmt.Sprintf("%s:%d", helpers.ShortenPath(m.host.SourceFile), m.host.SourceLine)
+		b.WriteString(ui.DetailTextStyle.Render("The Host block at "+location+" will be removed (a .bak backup is kept).") + "\n\n")
+	}
+
+	b.WriteString(ui.NormalStyle.Render("Host "+strings.Join(append([]string{m.host.Name}, m.host.Aliases...), " ")) + "\n")
+	for _, d := range m.host.Directives {
+		b.WriteString(ui.DetailTextStyle.Render("    "+d.Key+" "+d.Value) + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(ui.InstructionStyle.Render("Press y to delete, n or Esc to cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package hostkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-tui/internal/hostkey"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// authorizedKey generates an ed25519 key in authorized_keys format
+func authorizedKey(t *testing.T) string {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+// newModel writes lines to a known_hosts file and opens the screen on it
+func newModel(t *testing.T, lines []string, contacts map[string]time.Time) (*HostKeysModel, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := hostkey.LoadKnownHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewHostKeysModel(file, contacts)
+	m.width, m.height = 160, 40
+	return m, path
+}
+
+// press sends a key to the model
+func press(m *HostKeysModel, key string) {
+	switch key {
+	case "esc":
+		m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	case "down":
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	case " ":
+		m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	default:
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+}
+
+func TestHostKeysModel_View(t *testing.T) {
+	key := authorizedKey(t)
+	m, _ := newModel(t, []string{
+		"# comment",
+		"web.example.com " + key + " ops@example.com",
+		knownhosts.HashHostname("db") + " " + key,
+		"@cert-authority *.corp " + key,
+		"@revoked old " + key,
+	}, nil)
+
+	view := m.View()
+	for _, want := range []string{"4 entries", "web.example.com", "ssh-ed25519", "SHA256:", "ops@example.com", "hashed |1|", "@cert-authority *.corp", "@revoked old"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(view, "2     web.example.com") {
+		t.Errorf("expected the line number of the first entry:\n%s", view)
+	}
+}
+
+func TestHostKeysModel_DeleteDuplicates(t *testing.T) {
+	key := authorizedKey(t)
+	m, path := newModel(t, []string{
+		"web " + key,
+		"db " + key,
+		"web " + key,
+		"db " + key,
+	}, nil)
+
+	press(m, "u")
+	if len(m.shown) != 2 || !strings.Contains(m.View(), "2 duplicates") {
+		t.Fatalf("expected lines 3 and 4 as duplicates, got %v", m.shown)
+	}
+
+	press(m, "a")
+	press(m, "d")
+	if !strings.Contains(m.View(), "Delete 2 entries") {
+		t.Fatalf("expected a confirmation prompt:\n%s", m.View())
+	}
+	press(m, "y")
+	if !m.Changed() || !strings.Contains(m.View(), "2 entries deleted") {
+		t.Fatalf("expected the duplicates to be deleted:\n%s", m.View())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "web "+key+"\ndb "+key+"\n" {
+		t.Errorf("unexpected file after deletion:\n%s", data)
+	}
+
+	// With the filter active, Esc clears it before closing the screen
+	press(m, "esc")
+	if m.filter != FilterNone || m.IsClosed() {
+		t.Fatalf("expected Esc to clear the filter")
+	}
+	press(m, "esc")
+	if !m.IsClosed() {
+		t.Fatalf("expected Esc to close the screen")
+	}
+}
+
+func TestHostKeysModel_StaleAndHash(t *testing.T) {
+	key := authorizedKey(t)
+	contacts := map[string]time.Time{
+		"web": time.Now().AddDate(0, -2, 0),
+		"db":  time.Now().AddDate(0, -8, 0),
+	}
+	m, path := newModel(t, []string{"web " + key, "db " + key, "cache " + key}, contacts)
+
+	press(m, "s")
+	if len(m.shown) != 2 || !strings.Contains(m.View(), "in 6 months") {
+		t.Fatalf("expected db and cache to be stale, got %v", m.shown)
+	}
+	press(m, "+")
+	press(m, "+")
+	press(m, "+")
+	if len(m.shown) != 1 || m.entries[m.shown[0]].Hosts != "cache" {
+		t.Fatalf("expected only cache to be stale after 9 months, got %v", m.shown)
+	}
+
+	// Declining leaves the file alone
+	press(m, "h")
+	press(m, "n")
+	press(m, "h")
+	press(m, "y")
+	if !strings.Contains(m.View(), "3 host names hashed") {
+		t.Fatalf("expected the names to be hashed:\n%s", m.View())
+	}
+	file, err := hostkey.LoadKnownHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range file.Entries() {
+		if !e.Hashed() {
+			t.Errorf("expected line %d to be hashed: %+v", e.Line, e)
+		}
+	}
+	// Hashed entries are still matched against the history
+	if len(m.shown) != 1 || !hostkey.MatchHosts(m.entries[m.shown[0]].Hosts, "cache") {
+		t.Errorf("expected the hashed cache entry to stay stale")
+	}
+}
+
+func TestHostKeysModel_MarkAndDelete(t *testing.T) {
+	key := authorizedKey(t)
+	m, path := newModel(t, []string{"a " + key, "b " + key, "c " + key}, nil)
+
+	// Space marks and moves down; d deletes the marked entries
+	press(m, " ")
+	press(m, "down")
+	press(m, " ")
+	press(m, "d")
+	press(m, "y")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "b "+key+"\n" {
+		t.Errorf("expected a and c to be deleted, got:\n%s", data)
+	}
+
+	// Without marks, d deletes the focused entry
+	press(m, "d")
+	press(m, "y")
+	if len(m.entries) != 0 || !strings.Contains(m.View(), "no entries") {
+		t.Errorf("expected the last entry to be deleted:\n%s", m.View())
+	}
+}
+
+func TestHostKeysModel_HiddenMarksKept(t *testing.T) {
+	key := authorizedKey(t)
+	m, path := newModel(t, []string{"web " + key, "db " + key, "web " + key}, nil)
+
+	// Mark everything, then filter down to the duplicate: only it is deleted
+	press(m, "a")
+	press(m, "u")
+	press(m, "d")
+	view := m.View()
+	if !strings.Contains(view, "Delete 1 entry") || !strings.Contains(view, "2 marked entries hidden by the filter will be kept") {
+		t.Fatalf("expected only the shown entry to be deleted:\n%s", view)
+	}
+	press(m, "y")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "web "+key+"\ndb "+key+"\n" {
+		t.Errorf("expected the hidden entries to be kept, got:\n%s", data)
+	}
+}
@@ -0,0 +1,176 @@
+package hostkeys
+
+import (
+	"sort"
+	"time"
+
+	"ssh-tui/internal/hostkey"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Filter restricts the entries shown
+type Filter int
+
+const (
+	// FilterNone shows every entry
+	FilterNone Filter = iota
+	// FilterDuplicates shows entries whose keys are all recorded on earlier lines
+	FilterDuplicates
+	// FilterStale shows entries for hosts not connected to in the last staleMonths months
+	FilterStale
+)
+
+// defaultStaleMonths is how long a host may go without connections before it counts as stale
+const defaultStaleMonths = 6
+
+// confirmation is a change waiting for the user to confirm it
+type confirmation int
+
+const (
+	confirmNone confirmation = iota
+	confirmDelete
+	confirmHash
+)
+
+// HostKeysModel represents the screen for managing the entries of a known_hosts file
+type HostKeysModel struct {
+	file    *hostkey.KnownHostsFile
+	entries []hostkey.KnownHostsEntry
+	// shown indexes the entries that pass the filter; the cursor indexes shown
+	shown       []int
+	filter      Filter
+	staleMonths int
+	// contacts maps known_hosts names to their last connection (see hostkey.Contacts)
+	contacts map[string]time.Time
+	// marked holds the line numbers of the entries marked for deletion
+	marked  map[int]bool
+	cursor  int
+	confirm confirmation
+	status  string
+	err     string
+	changed bool
+	closed  bool
+	width   int
+	height  int
+}
+
+// NewHostKeysModel creates the screen for file; contacts tells when hosts were last connected to
+func NewHostKeysModel(file *hostkey.KnownHostsFile, contacts map[string]time.Time) *HostKeysModel {
+	m := &HostKeysModel{file: file, contacts: contacts, staleMonths: defaultStaleMonths, marked: make(map[int]bool)}
+	m.refresh()
+	return m
+}
+
+// Init implements the tea.Model interface
+func (m *HostKeysModel) Init() tea.Cmd {
+	return nil
+}
+
+// Changed reports whether the known_hosts file was modified
+func (m *HostKeysModel) Changed() bool {
+	return m.changed
+}
+
+// IsClosed reports whether the user closed the screen to return to host selection
+func (m *HostKeysModel) IsClosed() bool {
+	return m.closed
+}
+
+// refresh re-reads the entries from the file and re-applies the filter
+func (m *HostKeysModel) refresh() {
+	m.entries = m.file.Entries()
+
+	var lines []int
+	switch m.filter {
+	case FilterDuplicates:
+		lines = hostkey.Duplicates(m.entries)
+	case FilterStale:
+		lines = hostkey.Stale(m.entries, m.contacts, time.Now().AddDate(0, -m.staleMonths, 0))
+	}
+	keep := make(map[int]bool, len(lines))
+	for _, n := range lines {
+		keep[n] = true
+	}
+
+	m.shown = m.shown[:0]
+	for i, e := range m.entries {
+		if m.filter == FilterNone || keep[e.Line] {
+			m.shown = append(m.shown, i)
+		}
+	}
+	if m.cursor >= len(m.shown) {
+		m.cursor = max(0, len(m.shown)-1)
+	}
+}
+
+// setFilter switches to filter, or back to all entries when it is already active
+func (m *HostKeysModel) setFilter(filter Filter) {
+	if m.filter == filter {
+		filter = FilterNone
+	}
+	m.filter = filter
+	m.cursor = 0
+	m.refresh()
+}
+
+// focused returns the entry under the cursor, or nil if none is shown
+func (m *HostKeysModel) focused() *hostkey.KnownHostsEntry {
+	if m.cursor >= len(m.shown) {
+		return nil
+	}
+	return &m.entries[m.shown[m.cursor]]
+}
+
+// targets returns the lines to delete: the shown marked entries, or the focused one if none is
+// marked. Marks hidden by the filter are left out, so d only deletes what is on screen.
+func (m *HostKeysModel) targets() []int {
+	var lines []int
+	for _, i := range m.shown {
+		if n := m.entries[i].Line; m.marked[n] {
+			lines = append(lines, n)
+		}
+	}
+	if len(lines) == 0 {
+		if e := m.focused(); e != nil {
+			lines = append(lines, e.Line)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// hiddenMarks returns the number of marked entries the filter hides
+func (m *HostKeysModel) hiddenMarks() int {
+	hidden := len(m.marked)
+	for _, i := range m.shown {
+		if m.marked[m.entries[i].Line] {
+			hidden--
+		}
+	}
+	return hidden
+}
+
+// apply runs a confirmed change and saves the file. Line numbers shift, so marks are cleared.
+func (m *HostKeysModel) apply(c confirmation) {
+	switch c {
+	case confirmDelete:
+		lines := m.targets()
+		m.file.RemoveLines(lines)
+		m.status = plural(len(lines), "entry", "entries") + " deleted"
+	case confirmHash:
+		n := m.file.HashAll()
+		m.status = plural(n, "host name", "host names") + " hashed"
+	}
+	m.marked = make(map[int]bool)
+
+	if err := m.file.Save(); err != nil {
+		m.status, m.err = "", err.Error()
+		if file, loadErr := hostkey.LoadKnownHosts(m.file.Path); loadErr == nil {
+			m.file = file
+		}
+	} else {
+		m.changed = true
+	}
+	m.refresh()
+}
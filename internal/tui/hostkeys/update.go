@@ -0,0 +1,120 @@
+package hostkeys
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Update implements the tea.Model interface for the known_hosts screen
+func (m *HostKeysModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.confirm != confirmNone {
+			return m.updateConfirm(msg)
+		}
+		m.status, m.err = "", ""
+
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc":
+			// Clear the filter first if one is active
+			if m.filter != FilterNone {
+				m.setFilter(FilterNone)
+				return m, nil
+			}
+			m.closed = true
+			return m, tea.Quit
+
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down":
+			if m.cursor < len(m.shown)-1 {
+				m.cursor++
+			}
+
+		case "pgup":
+			m.cursor = max(0, m.cursor-m.pageSize())
+
+		case "pgdown":
+			m.cursor = max(0, min(len(m.shown)-1, m.cursor+m.pageSize()))
+
+		case " ":
+			if e := m.focused(); e != nil {
+				if m.marked[e.Line] {
+					delete(m.marked, e.Line)
+				} else {
+					m.marked[e.Line] = true
+				}
+				if m.cursor < len(m.shown)-1 {
+					m.cursor++
+				}
+			}
+
+		case "a":
+			// Mark every shown entry, or clear the marks if they all are marked already
+			all := true
+			for _, i := range m.shown {
+				all = all && m.marked[m.entries[i].Line]
+			}
+			for _, i := range m.shown {
+				if all {
+					delete(m.marked, m.entries[i].Line)
+				} else {
+					m.marked[m.entries[i].Line] = true
+				}
+			}
+
+		case "d", "delete":
+			if len(m.targets()) > 0 {
+				m.confirm = confirmDelete
+			}
+
+		case "h":
+			m.confirm = confirmHash
+
+		case "u":
+			m.setFilter(FilterDuplicates)
+
+		case "s":
+			m.setFilter(FilterStale)
+
+		case "+", "=":
+			m.staleMonths++
+			m.refresh()
+
+		case "-":
+			if m.staleMonths > 1 {
+				m.staleMonths--
+				m.refresh()
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// updateConfirm handles keys while a change waits for confirmation
+func (m *HostKeysModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		c := m.confirm
+		m.confirm = confirmNone
+		m.apply(c)
+	case "n", "N", "esc":
+		m.confirm = confirmNone
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// pageSize returns the number of entries that fit on the screen
+func (m *HostKeysModel) pageSize() int {
+	return max(3, m.height-9)
+}
@@ -0,0 +1,129 @@
+package hostkeys
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+)
+
+// Column widths of the entry table
+const (
+	hostWidth = 32
+	typeWidth = 20
+)
+
+// View implements the tea.Model interface for the known_hosts screen
+func (m *HostKeysModel) View() string {
+	var b strings.Builder
+
+	title := ui.TitleStyle.Render("Known hosts")
+	title += ui.InstructionStyle.Render(fmt.Sprintf(" · %s · %s", helpers.ShortenPath(m.file.Path), plural(len(m.entries), "entry", "entries")))
+	b.WriteString(title + "\n")
+	switch m.filter {
+	case FilterDuplicates:
+		b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("%s recording keys already listed on earlier lines", plural(len(m.shown), "duplicate", "duplicates"))) + "\n")
+	case FilterStale:
+		b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("%s for hosts ssh-tui has not connected to in %s (+/- to change)",
+			plural(len(m.shown), "entry", "entries"), plural(m.staleMonths, "month", "months"))) + "\n")
+	}
+	b.WriteString("\n")
+
+	if len(m.shown) == 0 {
+		if m.filter == FilterNone {
+			b.WriteString(ui.InstructionStyle.Render("known_hosts has no entries") + "\n")
+		} else {
+			b.WriteString(ui.InstructionStyle.Render("No entries found") + "\n")
+		}
+	} else {
+		header := fmt.Sprintf("    %-5s %-*s %-*s %s", "Line", hostWidth, "Host", typeWidth, "Key type", "Fingerprint")
+		b.WriteString(ui.DetailTextStyle.Render(header) + "\n")
+		start, end := helpers.ScrollRange(len(m.shown), m.cursor, m.pageSize())
+		for i := start; i < end; i++ {
+			b.WriteString(m.renderEntry(m.entries[m.shown[i]], i == m.cursor) + "\n")
+		}
+		if start > 0 || end < len(m.shown) {
+			b.WriteString(ui.InstructionStyle.Render(fmt.Sprintf("%d/%d entries", m.cursor+1, len(m.shown))) + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if m.err != "" {
+		b.WriteString(ui.ErrorStyle.Render(m.err) + "\n\n")
+	} else if m.status != "" {
+		b.WriteString(ui.SearchStyle.Render(m.status) + "\n\n")
+	}
+
+	switch m.confirm {
+	case confirmDelete:
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Delete %s from %s?", plural(len(m.targets()), "entry", "entries"), helpers.ShortenPath(m.file.Path))) + "\n")
+		if hidden := m.hiddenMarks(); hidden > 0 {
+			b.WriteString(ui.DetailTextStyle.Render(plural(hidden, "marked entry", "marked entries")+" hidden by the filter will be kept") + "\n")
+		}
+		b.WriteString(ui.InstructionStyle.Render("A .bak backup is kept. Press y to delete, n or Esc to cancel"))
+	case confirmHash:
+		b.WriteString(ui.ErrorStyle.Render("Hash every host name in "+helpers.ShortenPath(m.file.Path)+"?") + "\n")
+		b.WriteString(ui.InstructionStyle.Render("Hashed names cannot be read back. A .bak backup is kept. Press y to hash, n or Esc to cancel"))
+	default:
+		b.WriteString(ui.InstructionStyle.Render("Use ↑/↓ to navigate, Space to mark, a to mark all, d to delete, h to hash all names") + "\n")
+		b.WriteString(ui.InstructionStyle.Render("u duplicates, s stale hosts, Esc to go back"))
+	}
+	return b.String()
+}
+
+// renderEntry renders one entry as a table row, marked entries with a *
+func (m *HostKeysModel) renderEntry(e hostkey.KnownHostsEntry, focused bool) string {
+	prefix := "  "
+	if focused {
+		prefix = "> "
+	}
+	mark := "  "
+	if m.marked[e.Line] {
+		mark = "* "
+	}
+
+	host := e.Hosts
+	if e.Hashed() {
+		host = "hashed " + host
+	}
+	if e.Marker != "" {
+		host = e.Marker + " " + host
+	}
+	host = truncate(host, hostWidth)
+
+	row := fmt.Sprintf("%-5d %-*s %-*s %s", e.Line, hostWidth, host, typeWidth, truncate(e.Type, typeWidth), e.Fingerprint())
+	if e.Comment != "" {
+		row += "  " + e.Comment
+	}
+
+	switch {
+	case focused:
+		return ui.SelectedTextStyle.Render(prefix + mark + row)
+	case e.Marker == "@revoked":
+		return ui.ErrorStyle.Render(prefix + mark + row)
+	case e.Marker != "":
+		return ui.WarningStyle.Render(prefix + mark + row)
+	case e.Hashed():
+		return ui.DetailTextStyle.Render(prefix + mark + row)
+	}
+	return ui.NormalStyle.Render(prefix + mark + row)
+}
+
+// truncate shortens s to width characters, ending it with … when cut
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// plural formats a count with the singular or plural noun
+func plural(n int, singular, pluralNoun string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, pluralNoun)
+}
@@ -0,0 +1,138 @@
+package hostselector
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+	"ssh-tui/internal/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailsPanelMinWidth is the terminal width from which the detail panel is shown beside the list
+const detailsPanelMinWidth = 100
+
+// wideLayout reports whether the terminal is wide enough to show the detail panel beside the list
+func (m *HostSelectorModel) wideLayout() bool {
+	return m.width >= detailsPanelMinWidth
+}
+
+// focusedHost returns the host under the cursor, or nil if the list is empty or a group header is focused
+func (m *HostSelectorModel) focusedHost() *types.SSHHost {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].header {
+		return nil
+	}
+	return &m.filteredHosts[m.rows[m.cursor].host]
+}
+
+// hostDiagnostics returns the config problems found within host's Host block
+func (m *HostSelectorModel) hostDiagnostics(host types.SSHHost) []parser.Diagnostic {
+	if host.Source != types.SourceConfig || len(m.diagnostics) == 0 {
+		return nil
+	}
+	last := host.SourceLine
+	for _, d := range host.Directives {
+		last = max(last, d.Line)
+	}
+
+	var found []parser.Diagnostic
+	for _, d := range m.diagnostics {
+		if d.File == host.SourceFile && d.Line >= host.SourceLine && d.Line <= last {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// renderDetails renders everything known about host, including the problems found in its
+// config block, wrapped to the given width
+func renderDetails(host *types.SSHHost, problems []parser.Diagnostic, width int) string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("183")).
+		Bold(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86")).
+		Bold(true)
+
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(host.Name) + "\n")
+	if len(host.Aliases) > 0 {
+		b.WriteString(ui.DetailTextStyle.Render("aliases: "+strings.Join(host.Aliases, ", ")) + "\n")
+	}
+	if host.Description != "" {
+		b.WriteString(ui.NormalStyle.Render(host.Description) + "\n")
+	}
+	if len(host.Tags) > 0 {
+		b.WriteString(ui.TagStyle.Render("#"+strings.Join(host.Tags, " #")) + "\n")
+	}
+
+	b.WriteString("\n" + labelStyle.Render("Source") + "\n")
+	source := host.Source
+	if host.SourceFile != "" {
+		source += fmt.Sprintf(" (%s:%d)", helpers.ShortenPath(host.SourceFile), host.SourceLine)
+	}
+	b.WriteString(ui.NormalStyle.Render(source) + "\n")
+
+	b.WriteString("\n" + labelStyle.Render("Configuration") + "\n")
+	if len(host.Directives) > 0 {
+		for _, d := range host.Directives {
+			b.WriteString(ui.NormalStyle.Render(d.Key+" ") + ui.DetailTextStyle.Render(d.Value) + "\n")
+		}
+	} else {
+		if host.HostName != "" {
+			b.WriteString(ui.NormalStyle.Render("HostName ") + ui.DetailTextStyle.Render(host.HostName) + "\n")
+		}
+		if host.User != "" {
+			b.WriteString(ui.NormalStyle.Render("User ") + ui.DetailTextStyle.Render(host.User) + "\n")
+		}
+		if host.Port != "" {
+			b.WriteString(ui.NormalStyle.Render("Port ") + ui.DetailTextStyle.Render(host.Port) + "\n")
+		}
+		if host.HostName == "" && host.User == "" && host.Port == "" {
+			b.WriteString(ui.DetailTextStyle.Render("no directives") + "\n")
+		}
+	}
+
+	b.WriteString("\n" + labelStyle.Render("Host keys") + "\n")
+	if len(host.HostKeys) == 0 {
+		b.WriteString(ui.DetailTextStyle.Render("not in known_hosts") + "\n")
+	}
+	for _, key := range host.HostKeys {
+		b.WriteString(ui.NormalStyle.Render(key.Type) + "\n")
+		b.WriteString(ui.DetailTextStyle.Render(key.Fingerprint) + "\n")
+	}
+
+	b.WriteString("\n" + labelStyle.Render("Last connected") + "\n")
+	if host.LastConnected.IsZero() {
+		b.WriteString(ui.DetailTextStyle.Render("never") + "\n")
+	} else {
+		b.WriteString(ui.NormalStyle.Render(host.LastConnected.Local().Format("2006-01-02 15:04")) + "\n")
+	}
+
+	if len(problems) > 0 {
+		b.WriteString("\n" + ui.WarningStyle.Bold(true).Render("Config problems") + "\n")
+		for _, d := range problems {
+			b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("line %d: ", d.Line)) + ui.NormalStyle.Render(d.Message) + "\n")
+		}
+	}
+
+	if len(host.Notes) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Notes") + "\n")
+		for _, note := range host.Notes {
+			b.WriteString(ui.DetailTextStyle.Render(note) + "\n")
+		}
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(strings.TrimRight(b.String(), "\n"))
+}
+
+// detailsPanelStyle frames the detail panel shown beside the host list
+var detailsPanelStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("240")).
+	Padding(0, 1)
@@ -0,0 +1,193 @@
+package hostselector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/tui/ui"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// spinnerFrames are shown in turn while discovery is running
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is the delay between spinner frames
+const spinnerInterval = 100 * time.Millisecond
+
+// sourceStatus tracks the progress of one discovery source
+type sourceStatus struct {
+	name  string
+	hosts []types.SSHHost
+	done  bool
+	err   error
+}
+
+// batchMsg delivers the result of one discovery source to the model
+type batchMsg parser.Batch
+
+// discoveryDoneMsg is sent once every discovery source has reported
+type discoveryDoneMsg struct{}
+
+// spinnerTickMsg advances the discovery spinner
+type spinnerTickMsg struct{}
+
+// sourcesChangedMsg is sent when a watched source reports that its hosts may have changed
+type sourcesChangedMsg struct{}
+
+// NewStreamingHostSelectorModel creates a host selector that starts empty and fills in as the
+// given discovery sources deliver batches. Batches are merged in the order of sources,
+// regardless of the order in which they arrive.
+func NewStreamingHostSelectorModel(sources []string, batches <-chan parser.Batch) *HostSelectorModel {
+	m := NewHostSelectorModel(nil)
+	m.batches = batches
+	m.loading = true
+	for _, name := range sources {
+		m.sources = append(m.sources, sourceStatus{name: name})
+	}
+	return m
+}
+
+// waitForBatch returns a command that waits for the next discovery batch
+func waitForBatch(batches <-chan parser.Batch) tea.Cmd {
+	return func() tea.Msg {
+		batch, ok := <-batches
+		if !ok {
+			return discoveryDoneMsg{}
+		}
+		return batchMsg(batch)
+	}
+}
+
+// WatchSources makes the selector discover its hosts again, from the batches returned by
+// discover, each time changes receives a value. sources names the discovery sources in merge
+// order; a selector created with hosts attributes them to the sources they came from.
+func (m *HostSelectorModel) WatchSources(sources []string, changes <-chan struct{}, discover func() <-chan parser.Batch) {
+	m.changes, m.discover = changes, discover
+	if m.sources != nil {
+		return
+	}
+	for _, name := range sources {
+		status := sourceStatus{name: name, done: true}
+		for _, h := range m.hosts {
+			if h.Source == name {
+				status.hosts = append(status.hosts, h)
+			}
+		}
+		m.sources = append(m.sources, status)
+	}
+}
+
+// waitForChange returns a command that waits for the next change of a watched source
+func waitForChange(changes <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return sourcesChangedMsg{}
+	}
+}
+
+// rediscover starts discovering the hosts again; each source keeps its hosts until it reports.
+// A change during discovery is picked up once that discovery is done.
+func (m *HostSelectorModel) rediscover() tea.Cmd {
+	if m.loading {
+		m.stale = true
+		return nil
+	}
+	m.stale = false
+	m.batches = m.discover()
+	m.loading = true
+	for i := range m.sources {
+		m.sources[i].done = false
+	}
+	return tea.Batch(waitForBatch(m.batches), spinnerTick())
+}
+
+// spinnerTick returns a command that advances the spinner after spinnerInterval
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// handleBatch records a source's result and re-merges the host list
+func (m *HostSelectorModel) handleBatch(msg batchMsg) {
+	for i := range m.sources {
+		if m.sources[i].name == msg.Source {
+			m.sources[i].hosts = msg.Hosts
+			m.sources[i].err = msg.Err
+			m.sources[i].done = true
+		}
+	}
+
+	lists := make([][]types.SSHHost, 0, len(m.sources))
+	for _, s := range m.sources {
+		lists = append(lists, s.hosts)
+	}
+	m.setHosts(parser.MergeHosts(lists...))
+}
+
+// setHosts replaces the host list, re-applying the filter and keeping focus on the same host
+func (m *HostSelectorModel) setHosts(hosts []types.SSHHost) {
+	focusedName := ""
+	if host := m.focusedHost(); host != nil {
+		focusedName = host.Name
+	}
+
+	m.hosts = hosts
+	m.filteredHosts = parser.FilterHosts(m.hosts, m.searchInput)
+	m.rebuildRows()
+
+	m.cursor = m.firstHostRow()
+	if focusedName == "" {
+		return
+	}
+	for i, r := range m.rows {
+		if !r.header && m.filteredHosts[r.host].Name == focusedName {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// renderDiscoveryStatus renders the per-source progress line while discovering, and any
+// source errors once discovery has finished; it returns "" when there is nothing to report
+func (m *HostSelectorModel) renderDiscoveryStatus() string {
+	var parts []string
+	var errs []string
+	for _, s := range m.sources {
+		switch {
+		case s.err != nil:
+			parts = append(parts, s.name+" ✗")
+			errs = append(errs, fmt.Sprintf("%s: %v", s.name, s.err))
+		case s.done:
+			parts = append(parts, fmt.Sprintf("%s ✓ %d", s.name, len(s.hosts)))
+		default:
+			parts = append(parts, s.name+" …")
+		}
+	}
+
+	var b strings.Builder
+	if m.loading {
+		frame := spinnerFrames[m.spinnerFrame%len(spinnerFrames)]
+		b.WriteString(ui.SearchStyle.Render(frame) + " " + ui.InstructionStyle.Render("Discovering hosts: "+strings.Join(parts, " · ")) + "\n")
+	}
+	for _, e := range errs {
+		b.WriteString(ui.ErrorStyle.Render(e) + "\n")
+	}
+	return b.String()
+}
+
+// Hosts returns all hosts known to the selector (the merged discovery results so far)
+func (m *HostSelectorModel) Hosts() []types.SSHHost {
+	return m.hosts
+}
+
+// DiscoveryDone reports whether every discovery source has finished
+func (m *HostSelectorModel) DiscoveryDone() bool {
+	return !m.loading
+}
@@ -0,0 +1,231 @@
+package hostselector
+
+import (
+	"net"
+	"strings"
+
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/types"
+)
+
+// GroupMode selects how the host list is grouped
+type GroupMode int
+
+const (
+	// GroupNone shows a flat list
+	GroupNone GroupMode = iota
+	// GroupByTag groups hosts by their @tags (a host with several tags appears in each group)
+	GroupByTag
+	// GroupByDomain groups hosts by the domain suffix of their HostName
+	GroupByDomain
+	// GroupByFile groups hosts by the file they were read from
+	GroupByFile
+	// GroupBySource groups hosts by their source (config, known_hosts, ...)
+	GroupBySource
+)
+
+// groupModeCount is the number of grouping modes cycled through with Ctrl+G
+const groupModeCount = 5
+
+// String returns the label shown in the title for the grouping mode
+func (g GroupMode) String() string {
+	switch g {
+	case GroupByTag:
+		return "tag"
+	case GroupByDomain:
+		return "domain"
+	case GroupByFile:
+		return "file"
+	case GroupBySource:
+		return "source"
+	default:
+		return "none"
+	}
+}
+
+// Placeholder group names for hosts lacking the grouped attribute; these sort last
+const (
+	groupUntagged = "(untagged)"
+	groupNoDomain = "(no domain)"
+	groupNoFile   = "(no file)"
+)
+
+// row is one line of the host list: either a group header or a host entry
+type row struct {
+	header bool
+	group  string
+	// count is the number of hosts in the group (headers only)
+	count int
+	// host indexes filteredHosts (entries only)
+	host int
+}
+
+// groupKeys returns the groups a host belongs to under the given mode
+func groupKeys(host types.SSHHost, mode GroupMode) []string {
+	switch mode {
+	case GroupByTag:
+		if len(host.Tags) == 0 {
+			return []string{groupUntagged}
+		}
+		return host.Tags
+	case GroupByDomain:
+		return []string{domainSuffix(host)}
+	case GroupByFile:
+		if host.SourceFile == "" {
+			return []string{groupNoFile}
+		}
+		return []string{helpers.ShortenPath(host.SourceFile)}
+	case GroupBySource:
+		return []string{host.Source}
+	}
+	return nil
+}
+
+// domainSuffix returns everything after the first label of the host's HostName (or Name),
+// e.g. "db1.eu.example.com" -> "eu.example.com"
+func domainSuffix(host types.SSHHost) string {
+	name := host.HostName
+	if name == "" {
+		name = host.Name
+	}
+	if at := strings.LastIndex(name, "@"); at != -1 {
+		name = name[at+1:]
+	}
+	if net.ParseIP(name) != nil {
+		return groupNoDomain
+	}
+	if dot := strings.Index(name, "."); dot != -1 && dot < len(name)-1 {
+		return strings.ToLower(name[dot+1:])
+	}
+	return groupNoDomain
+}
+
+// isPlaceholderGroup reports whether a group collects hosts lacking the grouped attribute
+func isPlaceholderGroup(group string) bool {
+	return group == groupUntagged || group == groupNoDomain || group == groupNoFile
+}
+
+// buildRows lays out hosts as list rows. Groups appear in order of first appearance with
+// placeholder groups last; collapsed groups contribute only their header.
+func buildRows(hosts []types.SSHHost, mode GroupMode, collapsed map[string]bool) []row {
+	rows := make([]row, 0, len(hosts))
+	if mode == GroupNone {
+		for i := range hosts {
+			rows = append(rows, row{host: i})
+		}
+		return rows
+	}
+
+	var order []string
+	members := make(map[string][]int)
+	for i, host := range hosts {
+		for _, key := range groupKeys(host, mode) {
+			if _, seen := members[key]; !seen {
+				order = append(order, key)
+			}
+			members[key] = append(members[key], i)
+		}
+	}
+
+	var placeholders []string
+	for _, group := range order {
+		if isPlaceholderGroup(group) {
+			placeholders = append(placeholders, group)
+			continue
+		}
+		rows = appendGroupRows(rows, group, members[group], collapsed[group])
+	}
+	for _, group := range placeholders {
+		rows = appendGroupRows(rows, group, members[group], collapsed[group])
+	}
+	return rows
+}
+
+// appendGroupRows appends a group's header and, unless collapsed, its host entries
+func appendGroupRows(rows []row, group string, hostIndexes []int, collapsed bool) []row {
+	rows = append(rows, row{header: true, group: group, count: len(hostIndexes)})
+	if collapsed {
+		return rows
+	}
+	for _, i := range hostIndexes {
+		rows = append(rows, row{group: group, host: i})
+	}
+	return rows
+}
+
+// rebuildRows recomputes the list rows from the filtered hosts and grouping state.
+// Groups are always expanded while searching so matches are never hidden.
+func (m *HostSelectorModel) rebuildRows() {
+	collapsed := m.collapsed
+	if m.searchInput != "" {
+		collapsed = nil
+	}
+	m.rows = buildRows(m.filteredHosts, m.groupMode, collapsed)
+}
+
+// firstHostRow returns the index of the first host entry row, or 0 if there is none
+func (m *HostSelectorModel) firstHostRow() int {
+	for i, r := range m.rows {
+		if !r.header {
+			return i
+		}
+	}
+	return 0
+}
+
+// headerRow returns the index of the header of the group containing row i
+func (m *HostSelectorModel) headerRow(i int) int {
+	for ; i > 0; i-- {
+		if m.rows[i].header {
+			return i
+		}
+	}
+	return 0
+}
+
+// setGroupCollapsed collapses or expands the group of the focused row, keeping focus on its header
+func (m *HostSelectorModel) setGroupCollapsed(collapsed bool) {
+	if m.groupMode == GroupNone || m.cursor >= len(m.rows) {
+		return
+	}
+	group := m.rows[m.cursor].group
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[group] = collapsed
+	m.rebuildRows()
+
+	for i, r := range m.rows {
+		if r.header && r.group == group {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// jumpGroup moves the cursor to the next (delta > 0) or previous (delta < 0) group header
+func (m *HostSelectorModel) jumpGroup(delta int) {
+	if m.groupMode == GroupNone {
+		return
+	}
+	if delta < 0 {
+		// From inside a group, first jump to its own header
+		if h := m.headerRow(m.cursor); h != m.cursor {
+			m.cursor = h
+			return
+		}
+	}
+	for i := m.cursor + delta; i >= 0 && i < len(m.rows); i += delta {
+		if m.rows[i].header {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// cycleGroupMode switches to the next grouping mode
+func (m *HostSelectorModel) cycleGroupMode() {
+	m.groupMode = (m.groupMode + 1) % groupModeCount
+	m.rebuildRows()
+	m.cursor = m.firstHostRow()
+}
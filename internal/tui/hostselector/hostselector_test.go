@@ -1,9 +1,19 @@
 package hostselector
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/probe"
 	"ssh-tui/internal/types"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -112,3 +122,528 @@ func TestHostSelectorModel_CustomHost(t *testing.T) {
 		t.Errorf("Expected custom host 'user@example.com' to be selected")
 	}
 }
+
+func TestHostSelectorModel_Details(t *testing.T) {
+	hosts := []types.SSHHost{
+		{
+			Name:       "db",
+			HostName:   "db.example.com",
+			Source:     types.SourceConfig,
+			SourceFile: "/etc/ssh-tui-test/config",
+			SourceLine: 12,
+			Directives: []types.Directive{{Key: "IdentityFile", Value: "~/.ssh/db_key"}},
+			HostKeys:   []types.HostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:abc"}},
+			Notes:      []string{"Primary database"},
+		},
+	}
+
+	// Wide terminals show the panel beside the list
+	model := NewHostSelectorModel(hosts)
+	model.width = 140
+	model.height = 30
+	view := model.View()
+	for _, want := range []string{"config:12", "IdentityFile", "SHA256:abc", "never", "Primary database"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("wide view should contain %q", want)
+		}
+	}
+
+	// Narrow terminals toggle a full-screen detail view
+	model = NewHostSelectorModel(hosts)
+	model.width = 60
+	model.height = 30
+	if strings.Contains(model.View(), "SHA256:abc") {
+		t.Errorf("narrow view should not show details until toggled")
+	}
+
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	hsModel := updatedModel.(*HostSelectorModel)
+	if !hsModel.showDetails || !strings.Contains(hsModel.View(), "SHA256:abc") {
+		t.Errorf("expected ctrl+d to open the detail view")
+	}
+
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.showDetails {
+		t.Errorf("expected esc to close the detail view")
+	}
+}
+
+func TestHostSelectorModel_ManageActions(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "cfg", HostName: "cfg.example.com", Source: types.SourceConfig},
+		{Name: "known.example.com", HostName: "known.example.com", Source: types.SourceKnownHosts},
+	}
+
+	// Editing is offered for config hosts
+	model := NewHostSelectorModel(hosts)
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	hsModel := updatedModel.(*HostSelectorModel)
+	if hsModel.RequestedAction() != ActionEditHost || hsModel.GetSelectedHost().Name != "cfg" {
+		t.Fatalf("expected edit action for config host")
+	}
+
+	// ...but not for known_hosts entries
+	model = NewHostSelectorModel(hosts)
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.IsSelected() {
+		t.Fatalf("expected delete to be ignored for known_hosts host")
+	}
+
+	// New host is prefilled from a known_hosts entry
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.RequestedAction() != ActionNewHost || hsModel.GetSelectedHost() == nil || hsModel.GetSelectedHost().Name != "known.example.com" {
+		t.Fatalf("expected new host action prefilled from known host")
+	}
+
+	// ...or from a custom host typed into search
+	model = NewHostSelectorModel(hosts)
+	updatedModel = model
+	for _, r := range "me@new.example.org" {
+		updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.GetSelectedHost() == nil || hsModel.GetSelectedHost().Source != types.SourceCustom {
+		t.Fatalf("expected new host action prefilled from custom host")
+	}
+}
+
+func TestHostSelectorModel_TagsShown(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "pg1", Tags: []string{"prod", "db"}, Description: "Primary Postgres", Source: types.SourceConfig},
+		{Name: "web", Tags: []string{"staging"}, Source: types.SourceConfig},
+	}
+
+	model := NewHostSelectorModel(hosts)
+	model.width = 80
+	model.height = 24
+	view := model.View()
+	for _, want := range []string{"#prod #db", "Primary Postgres", "#staging"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+}
+
+func TestHostSelectorModel_Grouping(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "pg1", HostName: "pg1.db.example.com", Tags: []string{"prod", "db"}, Source: types.SourceConfig},
+		{Name: "web1", HostName: "web1.example.com", Tags: []string{"prod"}, Source: types.SourceConfig},
+		{Name: "lab", HostName: "10.0.0.5", Source: types.SourceConfig},
+		{Name: "git.example.com", HostName: "git.example.com", Source: types.SourceKnownHosts},
+	}
+
+	model := NewHostSelectorModel(hosts)
+	model.width = 80
+	model.height = 40
+
+	// Ctrl+G switches to grouping by tag; the cursor lands on the first host, not the header
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	hsModel := updatedModel.(*HostSelectorModel)
+	if hsModel.groupMode != GroupByTag {
+		t.Fatalf("expected tag grouping, got %v", hsModel.groupMode)
+	}
+	// prod(2): pg1, web1; db(1): pg1; (untagged)(2): lab, git
+	if len(hsModel.rows) != 8 || !hsModel.rows[0].header || hsModel.rows[0].group != "prod" || hsModel.rows[7].header {
+		t.Fatalf("unexpected rows: %+v", hsModel.rows)
+	}
+	if hsModel.rows[5].group != groupUntagged {
+		t.Fatalf("expected untagged group last, got %+v", hsModel.rows[5])
+	}
+	if hsModel.focusedHost() == nil || hsModel.focusedHost().Name != "pg1" {
+		t.Fatalf("expected focus on first host")
+	}
+	view := hsModel.View()
+	for _, want := range []string{"grouped by tag", "prod (2)", "db (1)", "(untagged) (2)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+
+	// Left collapses the focused host's group and moves focus to its header
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.cursor != 0 || len(hsModel.rows) != 6 || hsModel.focusedHost() != nil {
+		t.Fatalf("expected prod group to collapse, rows: %+v cursor %d", hsModel.rows, hsModel.cursor)
+	}
+
+	// Enter on a header toggles it back open
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.selected || len(hsModel.rows) != 8 {
+		t.Fatalf("expected enter on header to expand without selecting")
+	}
+
+	// PgDown skips to the next group header
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if hsModel.cursor != 3 || hsModel.rows[3].group != "db" {
+		t.Fatalf("expected cursor on db header, got %d", hsModel.cursor)
+	}
+
+	// Enter into the group and select its host
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	hsModel = updatedModel.(*HostSelectorModel)
+	if !hsModel.selected || hsModel.selectedHost.Name != "pg1" {
+		t.Fatalf("expected pg1 to be selected from the db group")
+	}
+}
+
+func TestHostSelectorModel_WideLayoutOnGroupHeader(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "pg1", HostName: "pg1.db.example.com", Tags: []string{"prod"}, Source: types.SourceConfig},
+		{Name: "web1", HostName: "web1.example.com", Tags: []string{"prod"}, Source: types.SourceConfig},
+		{Name: "lab", HostName: "10.0.0.5", Source: types.SourceConfig},
+	}
+	model := NewHostSelectorModel(hosts)
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if model.focusedHost() != nil || !model.wideLayout() {
+		t.Fatalf("expected the cursor on a header in the wide layout")
+	}
+
+	// The list stays visible beside a summary of the focused group
+	view := model.View()
+	for _, want := range []string{"prod (2)", "(untagged) (1)", "lab", "2 hosts (collapsed)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+}
+
+func TestGroupKeys(t *testing.T) {
+	host := types.SSHHost{Name: "db1", HostName: "db1.eu.example.com", Source: types.SourceConfig}
+	if got := groupKeys(host, GroupByDomain); len(got) != 1 || got[0] != "eu.example.com" {
+		t.Fatalf("unexpected domain group: %v", got)
+	}
+	if got := groupKeys(types.SSHHost{Name: "box"}, GroupByDomain); got[0] != groupNoDomain {
+		t.Fatalf("expected no-domain group for a bare name, got %v", got)
+	}
+	if got := groupKeys(host, GroupBySource); got[0] != types.SourceConfig {
+		t.Fatalf("unexpected source group: %v", got)
+	}
+	if got := groupKeys(host, GroupByFile); got[0] != groupNoFile {
+		t.Fatalf("expected no-file group, got %v", got)
+	}
+}
+
+func TestHostSelectorModel_StreamingDiscovery(t *testing.T) {
+	batches := make(chan parser.Batch, 2)
+	model := NewStreamingHostSelectorModel([]string{types.SourceConfig, types.SourceKnownHosts}, batches)
+	model.width = 80
+	model.height = 24
+
+	if model.Init() == nil {
+		t.Fatalf("expected Init to start waiting for batches")
+	}
+	if !strings.Contains(model.View(), "Discovering hosts") {
+		t.Fatalf("expected discovery status while loading")
+	}
+
+	// known_hosts arrives first, but config hosts must still come first and win the dedupe
+	batches <- parser.Batch{Source: types.SourceKnownHosts, Hosts: []types.SSHHost{
+		{Name: "shared", HostName: "shared", Source: types.SourceKnownHosts, HostKeys: []types.HostKey{{Type: "ssh-ed25519"}}},
+		{Name: "known-only", Source: types.SourceKnownHosts},
+	}}
+	msg := waitForBatch(batches)()
+	updatedModel, cmd := model.Update(msg)
+	hsModel := updatedModel.(*HostSelectorModel)
+	if cmd == nil || len(hsModel.filteredHosts) != 2 {
+		t.Fatalf("expected known_hosts batch to be shown and the next batch awaited")
+	}
+
+	// Focus the second host so we can check it survives the re-merge
+	updatedModel, _ = hsModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	batches <- parser.Batch{Source: types.SourceConfig, Hosts: []types.SSHHost{
+		{Name: "shared", HostName: "shared", User: "admin", Source: types.SourceConfig},
+	}}
+	updatedModel, _ = updatedModel.Update(waitForBatch(batches)())
+	hsModel = updatedModel.(*HostSelectorModel)
+
+	if len(hsModel.hosts) != 2 || hsModel.hosts[0].Source != types.SourceConfig || hsModel.hosts[0].User != "admin" {
+		t.Fatalf("expected config host first after merge, got %+v", hsModel.hosts)
+	}
+	if len(hsModel.hosts[0].HostKeys) != 1 {
+		t.Fatalf("expected known_hosts keys to be attached to the config host")
+	}
+	if hsModel.focusedHost() == nil || hsModel.focusedHost().Name != "known-only" {
+		t.Fatalf("expected focus to stay on known-only")
+	}
+
+	close(batches)
+	updatedModel, _ = hsModel.Update(waitForBatch(batches)())
+	hsModel = updatedModel.(*HostSelectorModel)
+	if !hsModel.DiscoveryDone() || strings.Contains(hsModel.View(), "Discovering hosts") {
+		t.Fatalf("expected discovery to be finished")
+	}
+}
+
+func TestHostSelectorModel_DiscoveryError(t *testing.T) {
+	batches := make(chan parser.Batch, 1)
+	model := NewStreamingHostSelectorModel([]string{types.SourceConfig}, batches)
+	model.width = 80
+	model.height = 24
+
+	updatedModel, _ := model.Update(batchMsg{Source: types.SourceConfig, Err: errors.New("permission denied")})
+	updatedModel, _ = updatedModel.Update(discoveryDoneMsg{})
+	view := updatedModel.(*HostSelectorModel).View()
+	if !strings.Contains(view, "config: permission denied") {
+		t.Fatalf("expected source error in view, got:\n%s", view)
+	}
+}
+
+func TestHostSelectorModel_WatchSources(t *testing.T) {
+	model := NewHostSelectorModel([]types.SSHHost{
+		{Name: "web", Source: types.SourceConfig},
+		{Name: "old", Source: types.SourceKnownHosts},
+	})
+	model.width = 80
+	model.height = 24
+
+	changes := make(chan struct{}, 1)
+	var streams []chan parser.Batch
+	model.WatchSources([]string{types.SourceConfig, types.SourceKnownHosts}, changes, func() <-chan parser.Batch {
+		streams = append(streams, make(chan parser.Batch, 2))
+		return streams[len(streams)-1]
+	})
+	if model.Init() == nil {
+		t.Fatalf("expected Init to wait for changes")
+	}
+
+	// A change starts discovery again; known_hosts keeps its host until it reports
+	changes <- struct{}{}
+	model.Update(waitForChange(changes)())
+	if len(streams) != 1 || model.DiscoveryDone() {
+		t.Fatalf("expected discovery to restart")
+	}
+	streams[0] <- parser.Batch{Source: types.SourceConfig, Hosts: []types.SSHHost{
+		{Name: "web", Source: types.SourceConfig},
+		{Name: "db", Source: types.SourceConfig},
+	}}
+	model.Update(waitForBatch(streams[0])())
+	if len(model.hosts) != 3 {
+		t.Fatalf("expected the new config host alongside the known_hosts host, got %+v", model.hosts)
+	}
+
+	// A change during discovery restarts it once the running one is done
+	changes <- struct{}{}
+	model.Update(waitForChange(changes)())
+	if len(streams) != 1 {
+		t.Fatalf("expected discovery not to restart while running")
+	}
+	streams[0] <- parser.Batch{Source: types.SourceKnownHosts}
+	model.Update(waitForBatch(streams[0])())
+	close(streams[0])
+	model.Update(waitForBatch(streams[0])())
+	if len(model.hosts) != 2 || len(streams) != 2 || model.DiscoveryDone() {
+		t.Fatalf("expected a second discovery after the first, got %d hosts and %d runs", len(model.hosts), len(streams))
+	}
+}
+
+func TestHostSelectorModel_PickMode(t *testing.T) {
+	hosts := []types.SSHHost{{Name: "web", Source: types.SourceConfig}}
+	model := NewHostSelectorModel(hosts)
+	model.SetPickMode(true)
+	model.width = 80
+	model.height = 24
+
+	updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if cmd != nil || updatedModel.(*HostSelectorModel).RequestedAction() != ActionConnect {
+		t.Fatalf("expected host management to be disabled in pick mode")
+	}
+	if strings.Contains(model.View(), "Ctrl+N") || !strings.Contains(model.View(), "Enter to pick") {
+		t.Fatalf("expected pick instructions in view")
+	}
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	hsModel := updatedModel.(*HostSelectorModel)
+	if !hsModel.IsSelected() || hsModel.OpenOptionsRequested() {
+		t.Fatalf("expected Tab to select without opening options in pick mode")
+	}
+}
+
+func TestHostSelectorModel_SetQuery(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "db", Source: types.SourceConfig},
+		{Name: "web", Source: types.SourceConfig},
+	}
+	model := NewHostSelectorModel(hosts)
+	model.SetQuery("web")
+
+	if len(model.filteredHosts) != 1 || model.filteredHosts[0].Name != "web" {
+		t.Fatalf("expected the query to filter the list, got %v", model.filteredHosts)
+	}
+	if !strings.Contains(model.View(), "Search: web") {
+		t.Fatalf("expected the query in the search field")
+	}
+}
+
+func TestHostSelectorModel_ConfigDiagnostics(t *testing.T) {
+	hosts := []types.SSHHost{
+		{Name: "web", Source: types.SourceConfig, SourceFile: "/home/me/.ssh/config", SourceLine: 4,
+			Directives: []types.Directive{{Key: "Port", Value: "70000", Line: 5}}},
+		{Name: "db", Source: types.SourceConfig, SourceFile: "/home/me/.ssh/config", SourceLine: 7},
+	}
+	model := NewHostSelectorModel(hosts)
+	model.width = 120
+	model.height = 40
+	model.SetConfigDiagnostics([]parser.Diagnostic{
+		{File: "/home/me/.ssh/config", Line: 1, Severity: parser.SeverityError, Message: "unknown keyword \"Frobnicate\""},
+		{File: "/home/me/.ssh/config", Line: 5, Severity: parser.SeverityError, Message: "Port \"70000\" is not a port number"},
+	})
+
+	if got := model.hostDiagnostics(hosts[0]); len(got) != 1 || got[0].Line != 5 {
+		t.Errorf("expected the Port problem for web, got %v", got)
+	}
+	if got := model.hostDiagnostics(hosts[1]); len(got) != 0 {
+		t.Errorf("expected no problems for db, got %v", got)
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "2 config problems") {
+		t.Errorf("expected the problem count in the title:\n%s", view)
+	}
+	if !strings.Contains(view, "Config problems") || !strings.Contains(view, "line 5: Port") {
+		t.Errorf("expected the focused host's problems in its details:\n%s", view)
+	}
+	if strings.Count(view, "⚠") != 2 {
+		t.Errorf("expected the title and web to be marked:\n%s", view)
+	}
+}
+
+func TestHostSelectorModel_Probing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedHost, closedPort, _ := net.SplitHostPort(closed.Addr().String())
+	closed.Close()
+	upHost, upPort, _ := net.SplitHostPort(listener.Addr().String())
+
+	hosts := []types.SSHHost{
+		{Name: "up", HostName: upHost, Port: upPort, Source: types.SourceConfig},
+		{Name: "down", HostName: closedHost, Port: closedPort, Source: types.SourceConfig},
+		{Name: "inner", Source: types.SourceConfig, Directives: []types.Directive{{Key: "User", Value: "ops"}}},
+	}
+	// inner's ProxyJump comes from a pattern block, not its own
+	config := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(config, []byte("Host inner\n  User ops\n\nHost inn*\n  ProxyJump bastion\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	model := NewHostSelectorModel(hosts)
+	model.width = 80
+	model.height = 40
+	model.configOptions = func() *parser.ConfigOptions { return parser.LoadConfigOptions(config) }
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !model.Probing() || cmd == nil {
+		t.Fatalf("expected Ctrl+R to start probing")
+	}
+	msg := cmd()
+	if _, cmd = model.Update(msg); cmd == nil {
+		t.Fatalf("expected the next round to be scheduled")
+	}
+
+	view := model.View()
+	for _, want := range []string{"ms", "● down", "↪ via jump"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// Results and ticks from before probing was switched off are dropped
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.Probing() || strings.Contains(model.View(), "● down") {
+		t.Fatalf("expected Ctrl+R to stop probing and clear the badges")
+	}
+	if _, cmd = model.Update(msg); cmd != nil || len(model.reachability) != 0 {
+		t.Errorf("expected stale results to be ignored")
+	}
+	if _, cmd = model.Update(probeTickMsg{gen: 1}); cmd != nil {
+		t.Errorf("expected stale ticks to be ignored")
+	}
+}
+
+func TestHostSelectorModel_ProbingRestored(t *testing.T) {
+	var hosts []types.SSHHost
+	for i := range 10 {
+		hosts = append(hosts, types.SSHHost{Name: fmt.Sprintf("host%d", i), Source: types.SourceKnownHosts})
+	}
+	// Same name as a known_hosts host, but reached through a jump host
+	hosts = append(hosts, types.SSHHost{Name: "host0", Source: types.SourceConfig, Directives: []types.Directive{{Key: "ProxyJump", Value: "bastion"}}})
+
+	model := NewHostSelectorModel(hosts)
+	model.SetProbing(true)
+	// Without a config each host only has its own directives
+	model.configOptions = func() *parser.ConfigOptions { return nil }
+	var mu sync.Mutex
+	dialed := make(map[string]bool)
+	model.prober = &probe.Prober{Timeout: time.Second, Concurrency: 4, Dial: func(_ context.Context, _, address string) (net.Conn, error) {
+		mu.Lock()
+		dialed[address] = true
+		mu.Unlock()
+		return nil, errors.New("refused")
+	}}
+
+	// The first round waits for the terminal size, which decides the visible hosts
+	if cmd := model.Init(); cmd != nil {
+		t.Fatalf("expected no probes before the terminal size is known")
+	}
+	_, cmd := model.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	if cmd == nil {
+		t.Fatalf("expected the first WindowSizeMsg to start probing")
+	}
+	model.Update(cmd())
+	if len(dialed) != 10 {
+		t.Errorf("expected every visible host to be probed, got %v", dialed)
+	}
+	if _, cmd := model.Update(tea.WindowSizeMsg{Width: 80, Height: 30}); cmd != nil {
+		t.Errorf("expected later resizes not to start rounds")
+	}
+
+	// Results are kept apart for hosts sharing a name
+	if r := model.reachability[probe.Key(hosts[0])]; r.State != probe.StateDown {
+		t.Errorf("expected the known_hosts host to be down, got %+v", r)
+	}
+	if r := model.reachability[probe.Key(hosts[10])]; r.State != probe.StateViaJump {
+		t.Errorf("expected the config host to be reached via a jump host, got %+v", r)
+	}
+}
+
+func TestHostSelectorModel_ScreenActions(t *testing.T) {
+	model := NewHostSelectorModel([]types.SSHHost{{Name: "web", Source: types.SourceConfig}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if cmd == nil || model.RequestedAction() != ActionKnownHosts || model.IsSelected() {
+		t.Fatalf("expected Ctrl+K to open the known_hosts screen")
+	}
+
+	model = NewHostSelectorModel([]types.SSHHost{{Name: "web", Source: types.SourceConfig}})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if cmd == nil || model.RequestedAction() != ActionAgent || model.IsSelected() {
+		t.Fatalf("expected Ctrl+A to open the agent panel")
+	}
+
+	model = NewHostSelectorModel([]types.SSHHost{{Name: "web", Source: types.SourceConfig}})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if cmd == nil || model.RequestedAction() != ActionKeys || model.GetSelectedHost() == nil || model.GetSelectedHost().Name != "web" {
+		t.Fatalf("expected Ctrl+T to open the key screen for the focused host")
+	}
+
+	model = NewHostSelectorModel([]types.SSHHost{{Name: "web", Source: types.SourceConfig}})
+	model.SetPickMode(true)
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlK}); cmd != nil {
+		t.Fatalf("expected Ctrl+K to be disabled in pick mode")
+	}
+}
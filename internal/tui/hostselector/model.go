@@ -1,47 +1,136 @@
 package hostselector
 
 import (
+	"time"
+
 	"ssh-tui/internal/parser"
+	"ssh-tui/internal/probe"
 	"ssh-tui/internal/types"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Action identifies what the user asked to do when leaving the host selector
+type Action int
+
+const (
+	// ActionConnect connects to the selected host (optionally via the options screen)
+	ActionConnect Action = iota
+	// ActionNewHost opens the form for adding a Host block, prefilled from the selected host if any
+	ActionNewHost
+	// ActionEditHost opens the form for editing the selected host's Host block
+	ActionEditHost
+	// ActionDeleteHost asks to delete the selected host's Host block
+	ActionDeleteHost
+	// ActionKnownHosts opens the known_hosts management screen
+	ActionKnownHosts
+	// ActionAgent opens the ssh-agent panel
+	ActionAgent
+	// ActionKeys opens the screen generating keys and installing them on hosts
+	ActionKeys
+)
+
 // HostSelectorModel represents the host selection screen
 type HostSelectorModel struct {
 	hosts         []types.SSHHost
 	filteredHosts []types.SSHHost
-	cursor        int
-	searchInput   string
-	selected      bool
-	selectedHost  *types.SSHHost
+	// rows is the rendered list: host entries, plus group headers when grouping is on.
+	// The cursor indexes rows.
+	rows         []row
+	groupMode    GroupMode
+	collapsed    map[string]bool
+	cursor       int
+	searchInput  string
+	selected     bool
+	selectedHost *types.SSHHost
 	// If true, user requested to open the options screen after selection.
 	openOptions bool
+	// Action requested when the selector was left
+	action Action
+	// In pick mode the selector only chooses a host: host management and the options screen are off
+	pickMode bool
+	// If true, the focused host's details are shown full-screen (narrow terminals only)
+	showDetails bool
 	width       int
 	height      int
+
+	// Problems found in the SSH config (see SetConfigDiagnostics)
+	diagnostics []parser.Diagnostic
+
+	// Asynchronous discovery state (see NewStreamingHostSelectorModel)
+	batches      <-chan parser.Batch
+	sources      []sourceStatus
+	loading      bool
+	spinnerFrame int
+	// Source watching (see WatchSources); stale is set by a change during discovery
+	changes  <-chan struct{}
+	discover func() <-chan parser.Batch
+	stale    bool
+
+	// Reachability probe state (see SetProbing)
+	probing       bool
+	probeGen      int
+	prober        *probe.Prober
+	probeInterval time.Duration
+	reachability  map[string]probe.Result
+	// configOptions loads the ssh options applied to the probed hosts each round; tests replace it
+	configOptions func() *parser.ConfigOptions
+	// probeOnResize is set while a round waits for the first WindowSizeMsg
+	probeOnResize bool
 }
 
 // NewHostSelectorModel creates a new host selector model
 func NewHostSelectorModel(hosts []types.SSHHost) *HostSelectorModel {
-	return &HostSelectorModel{
+	m := &HostSelectorModel{
 		hosts:         hosts,
 		filteredHosts: hosts,
 		cursor:        0,
 		selected:      false,
 	}
+	m.rebuildRows()
+	return m
+}
+
+// SetPickMode turns pick mode on or off. In pick mode the selector is only used to choose a
+// host, so the keys for managing hosts are disabled and Tab selects like Enter.
+func (m *HostSelectorModel) SetPickMode(on bool) {
+	m.pickMode = on
+}
+
+// SetConfigDiagnostics sets the problems found by linting the SSH config. Their number is shown
+// in the title, and hosts whose Host block has problems are marked and list them in their details.
+func (m *HostSelectorModel) SetConfigDiagnostics(diagnostics []parser.Diagnostic) {
+	m.diagnostics = diagnostics
+}
+
+// SetQuery sets the search input and filters the list as if it had been typed
+func (m *HostSelectorModel) SetQuery(query string) {
+	m.searchInput = query
+	m.updateFilter()
 }
 
 // Init implements the tea.Model interface
 func (m *HostSelectorModel) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if m.batches != nil {
+		cmds = append(cmds, waitForBatch(m.batches), spinnerTick())
+	}
+	if m.changes != nil {
+		cmds = append(cmds, waitForChange(m.changes))
+	}
+	if m.probing {
+		cmds = append(cmds, m.probeVisible())
+	}
+	return tea.Batch(cmds...)
 }
 
 // updateFilter updates the filtered hosts based on search input
 func (m *HostSelectorModel) updateFilter() {
 	m.filteredHosts = parser.FilterHosts(m.hosts, m.searchInput)
+	m.rebuildRows()
 
 	// Whenever the filter changes (search input modified), reset focus to the first entry
-	m.cursor = 0
+	m.cursor = m.firstHostRow()
 }
 
 // GetSelectedHost returns the selected host
@@ -61,3 +150,8 @@ func (m *HostSelectorModel) IsSelected() bool {
 func (m *HostSelectorModel) OpenOptionsRequested() bool {
 	return m.openOptions
 }
+
+// RequestedAction returns the action the user asked for when leaving the selector
+func (m *HostSelectorModel) RequestedAction() Action {
+	return m.action
+}
@@ -0,0 +1,125 @@
+package hostselector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ssh-tui/internal/parser"
+	"ssh-tui/internal/probe"
+	"ssh-tui/internal/tui/ui"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// probeInterval is the delay between the end of one round of probes and the next
+const probeInterval = 15 * time.Second
+
+// probeTickMsg starts the next round of probes. gen identifies the probing session, so ticks
+// from a session that was switched off are dropped.
+type probeTickMsg struct{ gen int }
+
+// probeResultMsg delivers the results of a round of probes
+type probeResultMsg struct {
+	gen     int
+	results map[string]probe.Result
+}
+
+// SetProbing turns the reachability probes on or off; Ctrl+R toggles them too. While on, the
+// visible hosts are dialed periodically and marked up, down or reached via a jump host.
+func (m *HostSelectorModel) SetProbing(on bool) {
+	m.probing = on
+}
+
+// Probing reports whether the reachability probes are on
+func (m *HostSelectorModel) Probing() bool {
+	return m.probing
+}
+
+// toggleProbing switches the probes on or off, returning the command starting the first round
+func (m *HostSelectorModel) toggleProbing() tea.Cmd {
+	m.probing = !m.probing
+	m.probeGen++
+	m.reachability = nil
+	m.probeOnResize = false
+	if !m.probing {
+		return nil
+	}
+	return m.probeVisible()
+}
+
+// probeVisible returns a command probing the hosts currently shown in the list. Until the
+// terminal size is known the visible hosts are not either, so the first WindowSizeMsg starts
+// the round instead.
+func (m *HostSelectorModel) probeVisible() tea.Cmd {
+	if m.height == 0 {
+		m.probeOnResize = true
+		return nil
+	}
+	if m.prober == nil {
+		m.prober = probe.New()
+	}
+	if m.configOptions == nil {
+		m.configOptions = parser.UserConfigOptions
+	}
+	var hosts []types.SSHHost
+	start, end := m.visibleRange()
+	for i := start; i < end; i++ {
+		if r := m.rows[i]; !r.header {
+			hosts = append(hosts, m.filteredHosts[r.host])
+		}
+	}
+
+	// The config is read in the command, so edits made while probing are picked up next round
+	prober, gen, configOptions := m.prober, m.probeGen, m.configOptions
+	return func() tea.Msg {
+		options := configOptions()
+		targets := make([]probe.Target, len(hosts))
+		for i, host := range hosts {
+			targets[i] = probe.TargetFor(host, options.For(host))
+		}
+		return probeResultMsg{gen: gen, results: prober.Probe(context.Background(), targets)}
+	}
+}
+
+// handleProbeResults records a round of results and schedules the next round
+func (m *HostSelectorModel) handleProbeResults(msg probeResultMsg) tea.Cmd {
+	if !m.probing || msg.gen != m.probeGen {
+		return nil
+	}
+	if m.reachability == nil {
+		m.reachability = make(map[string]probe.Result)
+	}
+	for key, r := range msg.results {
+		m.reachability[key] = r
+	}
+	interval := m.probeInterval
+	if interval == 0 {
+		interval = probeInterval
+	}
+	gen := m.probeGen
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return probeTickMsg{gen: gen}
+	})
+}
+
+// reachabilityBadge renders the last probe result for host, or "" if it has not been probed
+func (m *HostSelectorModel) reachabilityBadge(host types.SSHHost) string {
+	r, ok := m.reachability[probe.Key(host)]
+	if !ok {
+		return ""
+	}
+	switch r.State {
+	case probe.StateUp:
+		latency := fmt.Sprintf("%dms", r.Latency.Milliseconds())
+		if r.Latency < time.Millisecond {
+			latency = "<1ms"
+		}
+		return ui.SearchStyle.Render(" ● " + latency)
+	case probe.StateDown:
+		return ui.ErrorStyle.Render(" ● down")
+	default:
+		return ui.DetailTextStyle.Render(" ↪ via jump")
+	}
+}
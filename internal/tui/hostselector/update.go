@@ -3,6 +3,7 @@ package hostselector
 import (
 	"ssh-tui/internal/parser"
 	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/types"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -13,26 +14,123 @@ func (m *HostSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.probeOnResize && m.probing {
+			m.probeOnResize = false
+			return m, m.probeVisible()
+		}
+
+	case batchMsg:
+		m.handleBatch(msg)
+		return m, waitForBatch(m.batches)
+
+	case discoveryDoneMsg:
+		m.loading = false
+		if m.stale {
+			return m, m.rediscover()
+		}
+
+	case sourcesChangedMsg:
+		return m, tea.Batch(m.rediscover(), waitForChange(m.changes))
+
+	case spinnerTickMsg:
+		if m.loading {
+			m.spinnerFrame++
+			return m, spinnerTick()
+		}
+
+	case probeResultMsg:
+		return m, m.handleProbeResults(msg)
+
+	case probeTickMsg:
+		if m.probing && msg.gen == m.probeGen {
+			return m, m.probeVisible()
+		}
 
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 
+		case "ctrl+d":
+			// Toggle the full-screen detail view (wide terminals always show the panel)
+			if !m.wideLayout() {
+				m.showDetails = !m.showDetails
+			}
+
+		case "ctrl+n":
+			if m.pickMode {
+				break
+			}
+			// Add a Host block, prefilled from the focused host or a valid custom host
+			m.action = ActionNewHost
+			m.selected = true
+			m.selectedHost = nil
+			if host := m.focusedHost(); host != nil && host.Source != types.SourceConfig {
+				m.selectedHost = host
+			} else if len(m.filteredHosts) == 0 && parser.IsValidHost(m.searchInput) {
+				ch := helpers.BuildCustomHost(m.searchInput)
+				m.selectedHost = &ch
+			}
+			return m, tea.Quit
+
+		case "ctrl+e", "ctrl+x":
+			// Only hosts from the SSH config have a Host block to edit or delete
+			if host := m.focusedHost(); !m.pickMode && host != nil && host.Source == types.SourceConfig {
+				m.action = ActionEditHost
+				if msg.String() == "ctrl+x" {
+					m.action = ActionDeleteHost
+				}
+				m.selectedHost = host
+				m.selected = true
+				return m, tea.Quit
+			}
+
+		case "ctrl+k", "ctrl+a":
+			if !m.pickMode {
+				m.action = ActionKnownHosts
+				if msg.String() == "ctrl+a" {
+					m.action = ActionAgent
+				}
+				m.selected = false
+				return m, tea.Quit
+			}
+
+		case "ctrl+t":
+			// The focused host is preselected for installing a key
+			if !m.pickMode {
+				m.action = ActionKeys
+				m.selectedHost = m.focusedHost()
+				m.selected = true
+				return m, tea.Quit
+			}
+
 		case "esc":
+			// Close the detail view first if it is open
+			if m.showDetails {
+				m.showDetails = false
+				return m, nil
+			}
+
 			// If there's search input, clear it; otherwise quit the app
 			if m.searchInput != "" {
 				m.searchInput = ""
 				m.filteredHosts = m.hosts
-				m.cursor = 0
+				m.rebuildRows()
+				m.cursor = m.firstHostRow()
 			} else {
 				return m, tea.Quit
 			}
 
 		case "enter":
+			// On a group header, Enter expands or collapses the group
+			if m.cursor < len(m.rows) && m.rows[m.cursor].header {
+				m.setGroupCollapsed(!m.collapsed[m.rows[m.cursor].group])
+				return m, nil
+			}
+
 			// If there are filtered hosts, select the focused one
-			if len(m.filteredHosts) > 0 && m.cursor < len(m.filteredHosts) {
-				m.selectedHost = &m.filteredHosts[m.cursor]
+			if host := m.focusedHost(); host != nil {
+				m.selectedHost = host
 				m.selected = true
 				m.openOptions = false
 				return m, tea.Quit
@@ -49,11 +147,12 @@ func (m *HostSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "tab":
-			// Open options for the selected host when possible (works while searching)
-			if len(m.filteredHosts) > 0 && m.cursor < len(m.filteredHosts) {
-				m.selectedHost = &m.filteredHosts[m.cursor]
+			// Open options for the selected host when possible (works while searching);
+			// pick mode has no options screen, so Tab just selects
+			if host := m.focusedHost(); host != nil {
+				m.selectedHost = host
 				m.selected = true
-				m.openOptions = true
+				m.openOptions = !m.pickMode
 				return m, tea.Quit
 			}
 
@@ -63,7 +162,7 @@ func (m *HostSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					ch := helpers.BuildCustomHost(m.searchInput)
 					m.selectedHost = &ch
 					m.selected = true
-					m.openOptions = true
+					m.openOptions = !m.pickMode
 					return m, tea.Quit
 				}
 			}
@@ -74,10 +173,28 @@ func (m *HostSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "down":
-			if m.cursor < len(m.filteredHosts)-1 {
+			if m.cursor < len(m.rows)-1 {
 				m.cursor++
 			}
 
+		case "ctrl+g":
+			m.cycleGroupMode()
+
+		case "ctrl+r":
+			return m, m.toggleProbing()
+
+		case "left":
+			m.setGroupCollapsed(true)
+
+		case "right":
+			m.setGroupCollapsed(false)
+
+		case "pgdown":
+			m.jumpGroup(1)
+
+		case "pgup":
+			m.jumpGroup(-1)
+
 		case "backspace":
 			if len(m.searchInput) > 0 {
 				m.searchInput = m.searchInput[:len(m.searchInput)-1]
@@ -90,7 +207,6 @@ func (m *HostSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				ch := msg.String()
 				m.searchInput += ch
 				m.updateFilter()
-				m.cursor = 0
 			}
 		}
 	}
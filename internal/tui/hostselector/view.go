@@ -16,10 +16,26 @@ import (
 func (m *HostSelectorModel) View() string {
 	var b strings.Builder
 
-	b.WriteString(ui.TitleStyle.Render("Host selection") + "\n\n")
+	title := ui.TitleStyle.Render("Host selection")
+	if m.groupMode != GroupNone {
+		title += ui.InstructionStyle.Render(" \u00b7 grouped by " + m.groupMode.String())
+	}
+	if n := len(m.diagnostics); n > 0 {
+		problems := "problems"
+		if n == 1 {
+			problems = "problem"
+		}
+		title += ui.WarningStyle.Render(fmt.Sprintf(" \u00b7 \u26a0 %d config %s (run ssh-tui doctor)", n, problems))
+	}
+	b.WriteString(title + "\n\n")
+	b.WriteString(m.renderDiscoveryStatus())
 
 	renderedSearch := helpers.RenderInputWithCursor(m.searchInput, len(m.searchInput), 40)
-	b.WriteString(ui.SearchStyle.Render("Search: "+renderedSearch) + "\n\n")
+	b.WriteString(ui.SearchStyle.Render("Search: " + renderedSearch))
+	if m.searchInput == "" {
+		b.WriteString(" " + ui.InstructionStyle.Render(ui.SearchHint))
+	}
+	b.WriteString("\n\n")
 
 	// If no hosts in the filtered list, show helpful messages and return early
 	if len(m.filteredHosts) == 0 {
@@ -29,27 +45,40 @@ func (m *HostSelectorModel) View() string {
 			} else {
 				b.WriteString(ui.ErrorStyle.Render("No hosts found matching: " + m.searchInput))
 			}
+		} else if m.loading {
+			b.WriteString(ui.InstructionStyle.Render("Discovering hosts\u2026"))
 		} else {
 			b.WriteString(ui.ErrorStyle.Render("No SSH hosts found.\nCheck that ~/.ssh/config or ~/.ssh/known_hosts exist and contain host entries."))
 		}
 
 		b.WriteString("\n\n")
-		b.WriteString(ui.InstructionStyle.Render(ui.InstructionNav))
+		b.WriteString(ui.InstructionStyle.Render(m.instructions(false)))
 		return b.String()
 	}
 
-	// Calculate visible range for scrolling
-	linesPerHost := 1
-	maxVisible := (m.height - 8) / linesPerHost
-	if maxVisible < 3 {
-		maxVisible = 3
+	// On narrow terminals the detail view replaces the list entirely
+	if m.showDetails && !m.wideLayout() {
+		if host := m.focusedHost(); host != nil {
+			b.WriteString(renderDetails(host, m.hostDiagnostics(*host), max(40, m.width-4)))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(ui.InstructionStyle.Render(ui.InstructionCloseDetails))
+		return b.String()
 	}
 
-	start, end := helpers.ScrollRange(len(m.filteredHosts), m.cursor, maxVisible)
+	start, end := m.visibleRange()
+
+	var list strings.Builder
 
-	// Render visible hosts
+	// Render visible rows
 	for i := start; i < end; i++ {
-		host := m.filteredHosts[i]
+		r := m.rows[i]
+		if r.header {
+			list.WriteString(m.formatGroupHeader(r, i == m.cursor) + "\n")
+			continue
+		}
+
+		host := m.filteredHosts[r.host]
 		hostDisplay := parser.FormatHostDisplay(host)
 		lines := strings.Split(hostDisplay, "\n")
 
@@ -60,28 +89,80 @@ func (m *HostSelectorModel) View() string {
 			for j := 1; j < len(lines); j++ {
 				content.WriteString("\n" + ui.DetailTextStyle.Render(lines[j]))
 			}
-			b.WriteString(ui.SelectedContainerStyle.Render(content.String()) + "\n")
+			list.WriteString(ui.SelectedContainerStyle.Render(content.String()) + "\n")
 		} else {
 			styledHostLine := m.formatHostLineWithAliases(host, ui.NormalStyle, ui.DetailTextStyle)
-			b.WriteString(ui.NormalContainerStyle.Render(styledHostLine) + "\n")
+			list.WriteString(ui.NormalContainerStyle.Render(styledHostLine) + "\n")
 		}
 	}
 
 	// Scroll indicator
-	if len(m.filteredHosts) > maxVisible {
-		scrollInfo := fmt.Sprintf("\n%d/%d hosts", m.cursor+1, len(m.filteredHosts))
-		if start > 0 || end < len(m.filteredHosts) {
-			scrollInfo += " (scroll with \u2191/\u2193)"
+	if start > 0 || end < len(m.rows) {
+		var scrollInfo string
+		if m.groupMode == GroupNone {
+			scrollInfo = fmt.Sprintf("\n%d/%d hosts (scroll with \u2191/\u2193)", m.cursor+1, len(m.filteredHosts))
+		} else {
+			scrollInfo = fmt.Sprintf("\n%d hosts (scroll with \u2191/\u2193, PgUp/PgDn to jump between groups)", len(m.filteredHosts))
 		}
-		b.WriteString(ui.InstructionStyle.Render(scrollInfo))
+		list.WriteString(ui.InstructionStyle.Render(scrollInfo))
+	}
+
+	// On wide terminals, show the focused host's details in a panel beside the list
+	if m.wideLayout() {
+		panelWidth := m.width * 2 / 5
+		listWidth := m.width - panelWidth - 4
+		listBlock := lipgloss.NewStyle().Width(listWidth).Render(strings.TrimRight(list.String(), "\n"))
+		switch {
+		case m.focusedHost() != nil:
+			host := m.focusedHost()
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listBlock, detailsPanelStyle.Render(renderDetails(host, m.hostDiagnostics(*host), panelWidth-4))))
+		case m.cursor < len(m.rows) && m.rows[m.cursor].header:
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listBlock, detailsPanelStyle.Render(m.renderGroupSummary(m.rows[m.cursor], panelWidth-4))))
+		default:
+			b.WriteString(listBlock)
+		}
+		b.WriteString("\n\n")
+		b.WriteString(ui.InstructionStyle.Render(m.instructions(false)))
+		return b.String()
 	}
 
+	b.WriteString(list.String())
 	b.WriteString("\n\n")
-	b.WriteString(ui.InstructionStyle.Render(ui.InstructionNav))
+	b.WriteString(ui.InstructionStyle.Render(m.instructions(true)))
 
 	return b.String()
 }
 
+// visibleRange returns the range of rows shown in the list; the focused host is drawn with a
+// border and detail lines
+func (m *HostSelectorModel) visibleRange() (start, end int) {
+	maxLines := m.height - 8
+	if maxLines < 3 {
+		maxLines = 3
+	}
+
+	heights := make([]int, len(m.rows))
+	for i, r := range m.rows {
+		heights[i] = 1
+		if i == m.cursor && !r.header {
+			heights[i] = strings.Count(parser.FormatHostDisplay(m.filteredHosts[r.host]), "\n") + 3
+		}
+	}
+	return helpers.ScrollRangeRows(heights, m.cursor, maxLines)
+}
+
+// instructions returns the key help shown under the list; withDetails adds the details toggle
+func (m *HostSelectorModel) instructions(withDetails bool) string {
+	nav, manage := ui.InstructionNav, ui.InstructionManageHosts
+	if m.pickMode {
+		nav, manage = ui.InstructionPick, ui.InstructionGroup
+	}
+	if withDetails {
+		nav += ", " + ui.InstructionDetails
+	}
+	return nav + "\n" + manage
+}
+
 // formatHostLineWithAliases formats the host name line with styled aliases
 func (m *HostSelectorModel) formatHostLineWithAliases(host types.SSHHost, normalStyle, aliasStyle lipgloss.Style) string {
 	hostName := normalStyle.Render(host.Name)
@@ -91,7 +172,7 @@ func (m *HostSelectorModel) formatHostLineWithAliases(host types.SSHHost, normal
 		hostName += aliasStyle.Render(aliasesStr)
 	}
 
-	return hostName
+	return hostName + formatTags(host) + m.problemBadge(host) + m.reachabilityBadge(host)
 }
 
 // formatHostLineWithAliasesSelectedEnhanced formats the host name line for enhanced selected state
@@ -104,8 +185,53 @@ func (m *HostSelectorModel) formatHostLineWithAliasesSelectedEnhanced(host types
 		hostName := selectedStyle.Render(host.Name)
 		aliasesStr := " [" + strings.Join(host.Aliases, ", ") + "]"
 		hostName += aliasStyle.Render(aliasesStr)
-		return hostName
+		return hostName + formatTags(host) + m.problemBadge(host) + m.reachabilityBadge(host)
 	}
 
-	return selectedStyle.Render(host.Name)
+	return selectedStyle.Render(host.Name) + formatTags(host) + m.problemBadge(host) + m.reachabilityBadge(host)
+}
+
+// formatTags renders a host's tags as #tag badges, or "" if it has none
+func formatTags(host types.SSHHost) string {
+	if len(host.Tags) == 0 {
+		return ""
+	}
+	return ui.TagStyle.Render(" #" + strings.Join(host.Tags, " #"))
+}
+
+// problemBadge marks a host whose config block has problems, or returns "" if it has none
+func (m *HostSelectorModel) problemBadge(host types.SSHHost) string {
+	if len(m.hostDiagnostics(host)) == 0 {
+		return ""
+	}
+	return ui.WarningStyle.Render(" \u26a0")
+}
+
+// renderGroupSummary renders the panel shown beside the list while a group header is focused
+func (m *HostSelectorModel) renderGroupSummary(r row, width int) string {
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render(r.group) + "\n\n")
+	if r.count == 1 {
+		b.WriteString("1 host")
+	} else {
+		b.WriteString(fmt.Sprintf("%d hosts", r.count))
+	}
+	if m.collapsed[r.group] && m.searchInput == "" {
+		b.WriteString(" (collapsed)")
+	}
+	b.WriteString("\n\n" + ui.InstructionStyle.Render("Enter or \u2190/\u2192 to collapse or expand"))
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
+
+// formatGroupHeader renders a group header row with its host count and collapsed state
+func (m *HostSelectorModel) formatGroupHeader(r row, focused bool) string {
+	marker := "\u25be"
+	if m.collapsed[r.group] && m.searchInput == "" {
+		marker = "\u25b8"
+	}
+	text := fmt.Sprintf("%s %s (%d)", marker, r.group, r.count)
+	if focused {
+		return ui.SelectedTextStyle.Render("> " + text)
+	}
+	return ui.TitleStyle.Render("  " + text)
 }
@@ -0,0 +1,74 @@
+package keychange
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// generateKey generates an ed25519 public key
+func generateKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyChangeModel(t *testing.T) {
+	oldKey, newKey := generateKey(t), generateKey(t)
+	host := &types.SSHHost{Name: "web", HostName: "web.example.com", Source: types.SourceConfig}
+	report := hostkey.Report{
+		Address:        "web.example.com:22",
+		KnownHostsName: "web.example.com",
+		Key:            newKey,
+		Known:          hostkey.KnownChanged,
+		Recorded:       []knownhosts.KnownKey{{Key: oldKey, Filename: "/tmp/known_hosts", Line: 7}},
+	}
+	model := NewKeyChangeModel(host, report, "/tmp/known_hosts")
+
+	view := model.View()
+	for _, want := range []string{"host key of web has changed", ssh.FingerprintSHA256(oldKey), "/tmp/known_hosts:7", ssh.FingerprintSHA256(newKey), "Press r"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// r asks for confirmation; n returns to the comparison
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if view := model.View(); !strings.Contains(view, "Remove the keys recorded for web.example.com") || !strings.Contains(view, "wildcard patterns are left alone") {
+		t.Fatalf("expected a confirmation prompt:\n%s", view)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if model.IsConfirmed() || strings.Contains(model.View(), "Remove the keys") {
+		t.Fatalf("expected n to cancel the removal")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !model.IsConfirmed() || model.IsCancelled() || cmd == nil {
+		t.Fatalf("expected y to confirm the removal")
+	}
+}
+
+func TestKeyChangeModel_Back(t *testing.T) {
+	host := &types.SSHHost{Name: "web"}
+	model := NewKeyChangeModel(host, hostkey.Report{Key: generateKey(t), Known: hostkey.KnownChanged}, "/tmp/known_hosts")
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !model.IsCancelled() || model.IsConfirmed() {
+		t.Fatalf("expected Esc to go back")
+	}
+}
@@ -0,0 +1,44 @@
+package keychange
+
+import (
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// KeyChangeModel represents the screen shown when a host presents a different key than the one
+// recorded in known_hosts. It compares the fingerprints and offers to remove the recorded keys
+// after confirmation.
+type KeyChangeModel struct {
+	host           *types.SSHHost
+	report         hostkey.Report
+	knownHostsPath string
+	// Set while asking to confirm the removal
+	confirming bool
+	confirmed  bool
+	cancelled  bool
+	width      int
+	height     int
+}
+
+// NewKeyChangeModel creates the screen for host, whose key check found a change; the stale
+// entries would be removed from knownHostsPath
+func NewKeyChangeModel(host *types.SSHHost, report hostkey.Report, knownHostsPath string) *KeyChangeModel {
+	return &KeyChangeModel{host: host, report: report, knownHostsPath: knownHostsPath}
+}
+
+// Init implements the tea.Model interface
+func (m *KeyChangeModel) Init() tea.Cmd {
+	return nil
+}
+
+// IsConfirmed returns whether the user confirmed removing the recorded keys
+func (m *KeyChangeModel) IsConfirmed() bool {
+	return m.confirmed
+}
+
+// IsCancelled returns whether the user went back without removing anything
+func (m *KeyChangeModel) IsCancelled() bool {
+	return m.cancelled
+}
@@ -0,0 +1,45 @@
+package keychange
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Update implements the tea.Model interface for the key change screen
+func (m *KeyChangeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.confirming {
+			return m.updateConfirm(msg)
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc":
+			m.cancelled = true
+			return m, tea.Quit
+
+		case "r":
+			m.confirming = true
+		}
+	}
+
+	return m, nil
+}
+
+// updateConfirm handles keys while asking to confirm the removal
+func (m *KeyChangeModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.confirmed = true
+		return m, tea.Quit
+	case "n", "N", "esc":
+		m.confirming = false
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package keychange
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/crypto/ssh"
+)
+
+// View implements the tea.Model interface for the key change screen
+func (m *KeyChangeModel) View() string {
+	var b strings.Builder
+	r := m.report
+
+	b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("⚠ The host key of %s has changed", m.host.Name)) + "\n\n")
+
+	text := "The key presented by " + r.Address + " differs from the one recorded in known_hosts, so ssh " +
+		"would refuse to connect. The host may have been reinstalled or its key rotated, but someone may " +
+		"also be intercepting the connection. Check the new fingerprint with the host's administrator " +
+		"before trusting it."
+	b.WriteString(lipgloss.NewStyle().Width(max(40, min(m.width, 100))).Render(ui.NormalStyle.Render(text)) + "\n\n")
+
+	b.WriteString(ui.TitleStyle.Render("Recorded in known_hosts:") + "\n")
+	for _, k := range r.Recorded {
+		location := fmt.Sprintf("%s:%d", helpers.ShortenPath(k.Filename), k.Line)
+		b.WriteString(fmt.Sprintf("  %s %s  %s\n", k.Key.Type(), ssh.FingerprintSHA256(k.Key), ui.DetailTextStyle.Render(location)))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(ui.TitleStyle.Render("Presented by the server:") + "\n")
+	b.WriteString(fmt.Sprintf("  %s %s\n", r.Key.Type(), ssh.FingerprintSHA256(r.Key)))
+	switch {
+	case r.TrustedErr != nil:
+		b.WriteString("  " + ui.ErrorStyle.Render(fmt.Sprintf("Cannot compare with the published fingerprints: %v", r.TrustedErr)) + "\n")
+	case !r.TrustedChecked:
+	case r.Trusted == hostkey.VerdictMatch:
+		b.WriteString("  " + ui.SearchStyle.Render(fmt.Sprintf("✓ Matches the published fingerprint (%s:%d)", r.Entry.File, r.Entry.Line)) + "\n")
+	case r.Trusted == hostkey.VerdictMismatch:
+		b.WriteString("  " + ui.ErrorStyle.Render("✗ Does not match the fingerprints published for this host") + "\n")
+	default:
+		b.WriteString("  " + ui.InstructionStyle.Render("No fingerprint is published for this host") + "\n")
+	}
+	b.WriteString("\n")
+
+	if m.confirming {
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Remove the keys recorded for %s from %s?", r.KnownHostsName, helpers.ShortenPath(m.knownHostsPath))) + "\n")
+		b.WriteString(ui.DetailTextStyle.Render("Lines naming other hosts keep them, and lines covering it only through wildcard patterns are left alone.") + "\n")
+		b.WriteString(ui.DetailTextStyle.Render("A .bak backup is kept. ssh will then ask you to accept the new key.") + "\n\n")
+		b.WriteString(ui.InstructionStyle.Render("Press y to remove them and connect, n or Esc to cancel"))
+		return b.String()
+	}
+	b.WriteString(ui.InstructionStyle.Render("Press r to remove the recorded key, Esc to go back"))
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package keydeploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ssh-tui/internal/keys"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends keys to the model: names like "enter" or text typed rune by rune
+func press(m *KeyDeployModel, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		switch k {
+		case "enter":
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		case "esc":
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		case "down":
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		case "tab":
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		case "space":
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		default:
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+	return cmd
+}
+
+// runAll runs the commands of a batch and feeds their messages to the model
+func runAll(m *KeyDeployModel, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runAll(m, c)
+		}
+		return
+	}
+	m.Update(msg)
+}
+
+func TestKeyDeployModel_Generate(t *testing.T) {
+	dir := t.TempDir()
+	m := NewKeyDeployModel(dir, nil, nil)
+	if !strings.Contains(m.View(), "Generate a new ed25519 key") {
+		t.Fatalf("expected the generate entry:\n%s", m.View())
+	}
+
+	press(m, "enter")
+	if m.step != stepGenerate || m.fields[fieldPath] != keys.ConfigPath(filepath.Join(dir, "id_ed25519")) || m.focus != fieldPassphrase {
+		t.Fatalf("expected the form to be prefilled, got %+v", m.fields)
+	}
+	if !strings.Contains(m.View(), "stored unencrypted") {
+		t.Errorf("expected a warning about the empty passphrase:\n%s", m.View())
+	}
+
+	// Mismatched passphrases are refused
+	press(m, "secret", "enter", "secreT", "enter")
+	if m.step != stepGenerate || !strings.Contains(m.View(), "do not match") {
+		t.Fatalf("expected the mismatch to be reported:\n%s", m.View())
+	}
+	if strings.Contains(m.View(), "secret") {
+		t.Fatalf("expected the passphrase to be masked:\n%s", m.View())
+	}
+
+	m.fields[fieldConfirm] = ""
+	press(m, "secret", "enter")
+	if m.step != stepHosts || m.key.Path != filepath.Join(dir, "id_ed25519") {
+		t.Fatalf("expected the key to be generated:\n%s", m.View())
+	}
+	if m.fields[fieldPassphrase] != "" || m.fields[fieldConfirm] != "" {
+		t.Errorf("expected the passphrase to be forgotten")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "id_ed25519.pub")); err != nil {
+		t.Errorf("expected the public key to be written: %v", err)
+	}
+
+	// The new key is listed when going back
+	press(m, "esc")
+	if len(m.keys) != 1 || !strings.Contains(m.View(), m.key.Fingerprint) {
+		t.Errorf("expected the new key to be listed:\n%s", m.View())
+	}
+	press(m, "esc")
+	if !m.IsClosed() {
+		t.Errorf("expected Esc to close the screen")
+	}
+}
+
+func TestKeyDeployModel_Install(t *testing.T) {
+	dir := t.TempDir()
+	key, err := keys.Generate(filepath.Join(dir, "id_deploy"), "deploy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("Host web\n  HostName web.example.com\n\nHost db\n  HostName db.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts := []types.SSHHost{
+		{Name: "web", Source: types.SourceConfig, SourceFile: config, SourceLine: 1},
+		{Name: "db", Source: types.SourceConfig, SourceFile: config, SourceLine: 4},
+		{Name: "cache.example.com", Source: types.SourceCustom},
+	}
+
+	m := NewKeyDeployModel(dir, hosts, &hosts[1])
+	m.installer.Run = func(_ context.Context, args []string) ([]byte, error) {
+		switch {
+		case strings.Contains(strings.Join(args, " "), " web "):
+			return []byte("added\n"), nil
+		case strings.Contains(strings.Join(args, " "), " db "):
+			return []byte("present\n"), nil
+		}
+		return nil, errors.New("Permission denied (publickey,password).")
+	}
+
+	press(m, "down", "enter")
+	if m.step != stepHosts || m.key.Path != key.Path || !m.marked[1] {
+		t.Fatalf("expected the key to be chosen with db marked")
+	}
+	press(m, "a", "i")
+	cmd := press(m, "enter")
+	if m.step != stepInstall || !strings.Contains(m.View(), "0 of 3 hosts done") {
+		t.Fatalf("expected the installation to start:\n%s", m.View())
+	}
+	// The screen stays open while hosts are pending
+	press(m, "esc")
+	if m.IsClosed() {
+		t.Fatalf("expected Esc to wait for the installation")
+	}
+
+	runAll(m, cmd)
+	view := m.View()
+	for _, want := range []string{"✓ web", "key added, IdentityFile added", "✓ db", "key already installed", "! cache.example.com", "Permission denied", "p to install interactively"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// Both blocks get the IdentityFile line, although the first one moved the second down
+	data, _ := os.ReadFile(config)
+	line := "  IdentityFile " + keys.ConfigPath(key.Path) + "\n"
+	if want := "Host web\n  HostName web.example.com\n" + line + "\nHost db\n  HostName db.example.com\n" + line; string(data) != want {
+		t.Errorf("unexpected config:\n%s", data)
+	}
+	if !m.Changed() {
+		t.Errorf("expected the config change to be reported")
+	}
+
+	// p runs ssh with the terminal for the host that asked for a password
+	var interactive []string
+	m.interactive = func(host types.SSHHost, _ keys.PublicKey) (*exec.Cmd, func(error) (keys.Result, error)) {
+		interactive = append(interactive, host.Name)
+		return exec.Command("true"), func(err error) (keys.Result, error) { return keys.ResultAdded, err }
+	}
+	if cmd := press(m, "p"); cmd == nil || m.remaining != 1 || m.runs[2].status != statusRunning {
+		t.Fatalf("expected the host to be installed on interactively")
+	}
+	if len(interactive) != 1 || interactive[0] != "cache.example.com" {
+		t.Fatalf("expected only the host needing a password to run interactively, got %v", interactive)
+	}
+	m.Update(installMsg{index: 2, err: errors.New("Connection closed by remote host"), interactive: true})
+	if m.runs[2].status != statusFailed || !strings.Contains(m.View(), "✗ cache.example.com") {
+		t.Fatalf("expected an interactive failure to be final:\n%s", m.View())
+	}
+
+	// b goes back with only the failed host marked
+	press(m, "b")
+	if m.step != stepHosts || m.markedCount() != 1 || !m.marked[2] {
+		t.Fatalf("expected only the failed host to be marked, got %v", m.marked)
+	}
+}
+
+func TestKeyDeployModel_RetryAfterConfigEdit(t *testing.T) {
+	dir := t.TempDir()
+	key, err := keys.Generate(filepath.Join(dir, "id_deploy"), "deploy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("Host web\n  HostName web.example.com\n\nHost db\n  HostName db.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts := []types.SSHHost{
+		{Name: "web", Source: types.SourceConfig, SourceFile: config, SourceLine: 1},
+		{Name: "db", Source: types.SourceConfig, SourceFile: config, SourceLine: 4},
+	}
+
+	m := NewKeyDeployModel(dir, hosts, nil)
+	dbUp := false
+	m.installer.Run = func(_ context.Context, args []string) ([]byte, error) {
+		if strings.Contains(strings.Join(args, " "), " db ") && !dbUp {
+			return nil, errors.New("Connection refused")
+		}
+		return []byte("added\n"), nil
+	}
+
+	press(m, "down", "enter")
+	if m.key.Path != key.Path {
+		t.Fatalf("expected the generated key to be chosen")
+	}
+	press(m, "a", "i")
+	runAll(m, press(m, "enter"))
+	if m.runs[0].status != statusAdded || m.runs[1].status != statusFailed {
+		t.Fatalf("expected web to succeed and db to fail:\n%s", m.View())
+	}
+
+	// The retry finds db's block although web's IdentityFile line moved it down
+	dbUp = true
+	press(m, "b")
+	runAll(m, press(m, "enter"))
+	if len(m.runs) != 1 || !m.runs[0].identityAdded || m.runs[0].identityErr != nil {
+		t.Fatalf("expected the retry to add db's IdentityFile line:\n%s", m.View())
+	}
+	data, _ := os.ReadFile(config)
+	line := "  IdentityFile " + keys.ConfigPath(key.Path) + "\n"
+	if want := "Host web\n  HostName web.example.com\n" + line + "\nHost db\n  HostName db.example.com\n" + line; string(data) != want {
+		t.Errorf("unexpected config:\n%s", data)
+	}
+}
+
+func TestKeyDeployModel_HostsNeedMarks(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := keys.Generate(filepath.Join(dir, "id_deploy"), "", nil); err != nil {
+		t.Fatal(err)
+	}
+	m := NewKeyDeployModel(dir, []types.SSHHost{{Name: "web", Source: types.SourceConfig}}, nil)
+	press(m, "down", "enter")
+	if cmd := press(m, "enter"); cmd != nil || m.step != stepHosts || !strings.Contains(m.View(), "Mark the hosts") {
+		t.Fatalf("expected an error without marked hosts:\n%s", m.View())
+	}
+	press(m, "space")
+	if m.markedCount() != 1 {
+		t.Fatalf("expected Space to mark the host")
+	}
+}
@@ -0,0 +1,286 @@
+package keydeploy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"ssh-tui/internal/keys"
+	"ssh-tui/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// step is a stage of the screen
+type step int
+
+const (
+	// stepKey chooses an existing key or to generate one
+	stepKey step = iota
+	// stepGenerate asks for the new key's file, comment and passphrase
+	stepGenerate
+	// stepHosts chooses the hosts to install the key on
+	stepHosts
+	// stepInstall shows the progress of the installation on each host
+	stepInstall
+)
+
+// Fields of the key generation form
+const (
+	fieldPath = iota
+	fieldComment
+	fieldPassphrase
+	fieldConfirm
+	fieldCount
+)
+
+// installConcurrency limits the hosts the key is installed on at once
+const installConcurrency = 4
+
+// status is the progress of the installation on one host
+type status int
+
+const (
+	statusRunning status = iota
+	statusAdded
+	statusPresent
+	statusFailed
+	// statusNeedsTerminal means ssh wanted to ask for a password or to accept the host key
+	statusNeedsTerminal
+)
+
+// hostRun is the installation of the key on one host
+type hostRun struct {
+	host   types.SSHHost
+	status status
+	err    error
+	// identityAdded is set when an IdentityFile line was added to the host's config block
+	identityAdded bool
+	identityErr   error
+}
+
+// installMsg delivers the result of installing the key on runs[index]; interactive is set when ssh
+// ran with the terminal
+type installMsg struct {
+	index       int
+	result      keys.Result
+	err         error
+	interactive bool
+}
+
+// KeyDeployModel represents the screen generating keys and installing them on hosts
+type KeyDeployModel struct {
+	step   step
+	sshDir string
+
+	// Key choice: cursor 0 generates a new key, the others pick keys[cursor-1]
+	keys      []keys.PublicKey
+	keyCursor int
+	key       keys.PublicKey
+
+	// Key generation form
+	fields [fieldCount]string
+	focus  int
+
+	// Host choice; marked holds indexes in hosts
+	hosts       []types.SSHHost
+	marked      map[int]bool
+	hostCursor  int
+	addIdentity bool
+
+	runs      []hostRun
+	remaining int
+	// edited holds, per config file, the original header lines of the blocks given an IdentityFile
+	// line since the screen opened; each moves the blocks below it down a line. It is kept across
+	// rounds, since hosts keep the lines they were loaded with.
+	edited    map[string][]int
+	installer *keys.Installer
+	// queue holds the runs waiting to be installed with the terminal, one at a time
+	queue       []int
+	interactive func(types.SSHHost, keys.PublicKey) (*exec.Cmd, func(error) (keys.Result, error))
+
+	status  string
+	err     string
+	changed bool
+	closed  bool
+	width   int
+	height  int
+}
+
+// NewKeyDeployModel creates the screen for the keys in sshDir and the given hosts, with selected
+// (if any) marked for installation
+func NewKeyDeployModel(sshDir string, hosts []types.SSHHost, selected *types.SSHHost) *KeyDeployModel {
+	m := &KeyDeployModel{sshDir: sshDir, hosts: hosts, marked: make(map[int]bool), edited: make(map[string][]int), installer: keys.NewInstaller(), interactive: keys.Interactive}
+	m.loadKeys()
+	if selected != nil {
+		for i, h := range hosts {
+			if h.Name == selected.Name && h.Source == selected.Source {
+				m.marked[i] = true
+				m.hostCursor = i
+				break
+			}
+		}
+	}
+	return m
+}
+
+// Init implements the tea.Model interface
+func (m *KeyDeployModel) Init() tea.Cmd {
+	return nil
+}
+
+// Changed reports whether IdentityFile lines were added to the SSH config
+func (m *KeyDeployModel) Changed() bool {
+	return m.changed
+}
+
+// IsClosed reports whether the user closed the screen to return to host selection
+func (m *KeyDeployModel) IsClosed() bool {
+	return m.closed
+}
+
+// loadKeys lists the keys in the SSH directory
+func (m *KeyDeployModel) loadKeys() {
+	found, err := keys.ListPublicKeys(m.sshDir)
+	if err != nil {
+		m.err = err.Error()
+	}
+	m.keys = found
+}
+
+// startGenerate opens the key generation form with a free file name and the default comment
+func (m *KeyDeployModel) startGenerate() {
+	m.fields = [fieldCount]string{keys.ConfigPath(keys.NextKeyPath(m.sshDir)), keys.DefaultComment(), "", ""}
+	m.focus = fieldPassphrase
+	m.step = stepGenerate
+}
+
+// generate creates the key described by the form and moves on to choosing hosts
+func (m *KeyDeployModel) generate() {
+	path := strings.TrimSpace(m.fields[fieldPath])
+	switch {
+	case path == "":
+		m.err = "Enter the file to write the key to"
+		return
+	case m.fields[fieldPassphrase] != m.fields[fieldConfirm]:
+		m.err = "The passphrases do not match"
+		m.focus = fieldConfirm
+		return
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	key, err := keys.Generate(path, strings.TrimSpace(m.fields[fieldComment]), []byte(m.fields[fieldPassphrase]))
+	// The passphrase is not kept once the key is written
+	m.fields[fieldPassphrase], m.fields[fieldConfirm] = "", ""
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.loadKeys()
+	m.key = key
+	m.status = "Generated " + keys.ConfigPath(key.Path) + " (" + key.Fingerprint + ")"
+	m.step = stepHosts
+}
+
+// install starts installing the key on the marked hosts
+func (m *KeyDeployModel) install() tea.Cmd {
+	m.runs = nil
+	for i, h := range m.hosts {
+		if m.marked[i] {
+			m.runs = append(m.runs, hostRun{host: h})
+		}
+	}
+	m.remaining = len(m.runs)
+	m.step = stepInstall
+
+	slots := make(chan struct{}, installConcurrency)
+	installer, key := m.installer, m.key
+	cmds := make([]tea.Cmd, len(m.runs))
+	for i, run := range m.runs {
+		cmds[i] = func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			result, err := installer.Install(context.Background(), run.host, key)
+			return installMsg{index: i, result: result, err: err}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// installInteractive installs the key with the terminal on the hosts that need a password or to
+// accept their host key, one at a time
+func (m *KeyDeployModel) installInteractive() tea.Cmd {
+	m.queue = nil
+	for i := range m.runs {
+		if m.runs[i].status == statusNeedsTerminal {
+			m.runs[i].status = statusRunning
+			m.queue = append(m.queue, i)
+		}
+	}
+	m.remaining += len(m.queue)
+	return m.nextInteractive()
+}
+
+// nextInteractive suspends the screen to run ssh for the next queued host, or returns nil when
+// the queue is empty
+func (m *KeyDeployModel) nextInteractive() tea.Cmd {
+	if len(m.queue) == 0 {
+		return nil
+	}
+	index := m.queue[0]
+	m.queue = m.queue[1:]
+	cmd, result := m.interactive(m.runs[index].host, m.key)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		r, err := result(err)
+		return installMsg{index: index, result: r, err: err, interactive: true}
+	})
+}
+
+// handleInstall records the result for one host and moves on to the next host waiting for the
+// terminal
+func (m *KeyDeployModel) handleInstall(msg installMsg) tea.Cmd {
+	m.recordInstall(msg)
+	if msg.interactive {
+		return m.nextInteractive()
+	}
+	return nil
+}
+
+// recordInstall records the result for one host, adding the IdentityFile line when asked.
+// Config edits happen here, one at a time, since several hosts may share a file.
+func (m *KeyDeployModel) recordInstall(msg installMsg) {
+	run := &m.runs[msg.index]
+	m.remaining--
+	switch {
+	case msg.err != nil && !msg.interactive && keys.NeedsTerminal(msg.err):
+		run.status, run.err = statusNeedsTerminal, msg.err
+		return
+	case msg.err != nil:
+		run.status, run.err = statusFailed, msg.err
+		return
+	}
+	run.status = statusAdded
+	if msg.result == keys.ResultPresent {
+		run.status = statusPresent
+	}
+	if !m.addIdentity {
+		return
+	}
+	host := run.host
+	for _, line := range m.edited[host.SourceFile] {
+		if line < run.host.SourceLine {
+			host.SourceLine++
+		}
+	}
+	run.identityAdded, run.identityErr = keys.AddIdentityFile(host, m.key.Path)
+	if run.identityAdded {
+		m.edited[host.SourceFile] = append(m.edited[host.SourceFile], run.host.SourceLine)
+		m.changed = true
+	}
+}
@@ -0,0 +1,182 @@
+package keydeploy
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Update implements the tea.Model interface for the key screen
+func (m *KeyDeployModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case installMsg:
+		return m, m.handleInstall(msg)
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.step {
+		case stepKey:
+			return m.updateKey(msg)
+		case stepGenerate:
+			return m.updateGenerate(msg)
+		case stepHosts:
+			return m.updateHosts(msg)
+		case stepInstall:
+			return m.updateInstall(msg)
+		}
+	}
+
+	return m, nil
+}
+
+// updateKey handles keys while choosing the key to install
+func (m *KeyDeployModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status, m.err = "", ""
+	switch msg.String() {
+	case "esc":
+		m.closed = true
+		return m, tea.Quit
+	case "up":
+		if m.keyCursor > 0 {
+			m.keyCursor--
+		}
+	case "down":
+		if m.keyCursor < len(m.keys) {
+			m.keyCursor++
+		}
+	case "enter":
+		if m.keyCursor == 0 {
+			m.startGenerate()
+			return m, nil
+		}
+		m.key = m.keys[m.keyCursor-1]
+		m.step = stepHosts
+	}
+	return m, nil
+}
+
+// updateGenerate handles keys in the key generation form
+func (m *KeyDeployModel) updateGenerate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.err = ""
+	field := &m.fields[m.focus]
+	switch msg.String() {
+	case "esc":
+		m.fields[fieldPassphrase], m.fields[fieldConfirm] = "", ""
+		m.step = stepKey
+	case "up", "shift+tab":
+		m.focus = (m.focus + fieldCount - 1) % fieldCount
+	case "down", "tab":
+		m.focus = (m.focus + 1) % fieldCount
+	case "enter":
+		// Enter moves through the form and generates the key from its last field
+		if m.focus < fieldConfirm {
+			m.focus++
+			return m, nil
+		}
+		m.generate()
+	case "ctrl+s":
+		m.generate()
+	case "backspace", "ctrl+h":
+		if r := []rune(*field); len(r) > 0 {
+			*field = string(r[:len(r)-1])
+		}
+	case "ctrl+u":
+		*field = ""
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			*field += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// updateHosts handles keys while choosing the hosts to install the key on
+func (m *KeyDeployModel) updateHosts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.err = ""
+	switch msg.String() {
+	case "esc":
+		m.status = ""
+		m.step = stepKey
+	case "up":
+		if m.hostCursor > 0 {
+			m.hostCursor--
+		}
+	case "down":
+		if m.hostCursor < len(m.hosts)-1 {
+			m.hostCursor++
+		}
+	case "pgup":
+		m.hostCursor = max(0, m.hostCursor-m.pageSize())
+	case "pgdown":
+		m.hostCursor = max(0, min(len(m.hosts)-1, m.hostCursor+m.pageSize()))
+	case " ":
+		if len(m.hosts) > 0 {
+			m.marked[m.hostCursor] = !m.marked[m.hostCursor]
+			if m.hostCursor < len(m.hosts)-1 {
+				m.hostCursor++
+			}
+		}
+	case "a":
+		// Mark every host, or clear the marks when all are marked
+		all := m.markedCount() == len(m.hosts)
+		for i := range m.hosts {
+			m.marked[i] = !all
+		}
+	case "i":
+		m.addIdentity = !m.addIdentity
+	case "enter":
+		if m.markedCount() == 0 {
+			m.err = "Mark the hosts to install the key on with Space"
+			return m, nil
+		}
+		m.status = ""
+		return m, m.install()
+	}
+	return m, nil
+}
+
+// updateInstall handles keys while the key is installed; the screen waits for every host
+func (m *KeyDeployModel) updateInstall(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.remaining > 0 {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "enter":
+		m.closed = true
+		return m, tea.Quit
+	case "p":
+		return m, m.installInteractive()
+	case "b":
+		// Back to the host list, with only the failed hosts marked for another try
+		failed := make(map[string]bool)
+		for _, run := range m.runs {
+			if run.status == statusFailed || run.status == statusNeedsTerminal {
+				failed[run.host.Source+"\x00"+run.host.Name] = true
+			}
+		}
+		m.marked = make(map[int]bool)
+		for i, h := range m.hosts {
+			m.marked[i] = failed[h.Source+"\x00"+h.Name]
+		}
+		m.step = stepHosts
+	}
+	return m, nil
+}
+
+// markedCount returns the number of hosts marked for installation
+func (m *KeyDeployModel) markedCount() int {
+	n := 0
+	for _, marked := range m.marked {
+		if marked {
+			n++
+		}
+	}
+	return n
+}
+
+// pageSize is the number of hosts listed at once
+func (m *KeyDeployModel) pageSize() int {
+	return max(3, m.height-10)
+}
@@ -0,0 +1,186 @@
+package keydeploy
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/keys"
+	"ssh-tui/internal/tui/helpers"
+	"ssh-tui/internal/tui/ui"
+	"ssh-tui/internal/types"
+)
+
+// fieldLabels names the fields of the key generation form
+var fieldLabels = [fieldCount]string{"Key file", "Comment", "Passphrase", "Confirm"}
+
+// View implements the tea.Model interface for the key screen
+func (m *KeyDeployModel) View() string {
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render("SSH keys") + ui.InstructionStyle.Render(" · "+helpers.ShortenPath(m.sshDir)) + "\n\n")
+
+	switch m.step {
+	case stepKey:
+		m.viewKey(&b)
+	case stepGenerate:
+		m.viewGenerate(&b)
+	case stepHosts:
+		m.viewHosts(&b)
+	case stepInstall:
+		m.viewInstall(&b)
+	}
+	return b.String()
+}
+
+// viewKey renders the list of keys to choose from
+func (m *KeyDeployModel) viewKey(b *strings.Builder) {
+	b.WriteString("Choose the key to install on hosts:\n\n")
+	rows := []string{"+ Generate a new ed25519 key"}
+	for _, k := range m.keys {
+		row := fmt.Sprintf("%-24s %-20s %s", helpers.ShortenPath(k.Path), k.Type, k.Fingerprint)
+		if k.Comment != "" {
+			row += "  " + k.Comment
+		}
+		rows = append(rows, row)
+	}
+	for i, row := range rows {
+		if i == m.keyCursor {
+			b.WriteString(ui.SelectedTextStyle.Render("> "+row) + "\n")
+		} else {
+			b.WriteString(ui.NormalStyle.Render("  "+row) + "\n")
+		}
+	}
+	b.WriteString("\n")
+	m.viewMessages(b)
+	b.WriteString(ui.InstructionStyle.Render("Use ↑/↓ to navigate, Enter to choose, Esc to go back"))
+}
+
+// viewGenerate renders the key generation form; passphrases are masked
+func (m *KeyDeployModel) viewGenerate(b *strings.Builder) {
+	b.WriteString("Generate a new ed25519 key:\n\n")
+	for i, label := range fieldLabels {
+		value := m.fields[i]
+		if i == fieldPassphrase || i == fieldConfirm {
+			value = strings.Repeat("•", len([]rune(value)))
+		}
+		if i == m.focus {
+			b.WriteString(ui.SelectedTextStyle.Render(fmt.Sprintf("> %-11s ", label+":")) + helpers.RenderInputWithCursor(value, len(value), 0) + "\n")
+		} else {
+			b.WriteString(ui.NormalStyle.Render(fmt.Sprintf("  %-11s %s", label+":", value)) + "\n")
+		}
+	}
+	b.WriteString("\n")
+	if m.fields[fieldPassphrase] == "" {
+		b.WriteString(ui.WarningStyle.Render("Without a passphrase the private key is stored unencrypted") + "\n\n")
+	}
+	m.viewMessages(b)
+	b.WriteString(ui.InstructionStyle.Render("Use ↑/↓ to move between fields, Enter on the last field or Ctrl+S to generate, Esc to cancel"))
+}
+
+// viewHosts renders the list of hosts to install the key on
+func (m *KeyDeployModel) viewHosts(b *strings.Builder) {
+	b.WriteString(fmt.Sprintf("Install %s (%s) on:\n\n", helpers.ShortenPath(m.key.Path), m.key.Fingerprint))
+	if len(m.hosts) == 0 {
+		b.WriteString(ui.InstructionStyle.Render("No hosts found") + "\n")
+	}
+	start, end := helpers.ScrollRange(len(m.hosts), m.hostCursor, m.pageSize())
+	for i := start; i < end; i++ {
+		mark := "[ ]"
+		if m.marked[i] {
+			mark = "[x]"
+		}
+		row := fmt.Sprintf("%s %-30s %s", mark, m.hosts[i].Name, ui.DetailTextStyle.Render(destination(m.hosts[i])))
+		if i == m.hostCursor {
+			b.WriteString(ui.SelectedTextStyle.Render("> "+row) + "\n")
+		} else {
+			b.WriteString(ui.NormalStyle.Render("  "+row) + "\n")
+		}
+	}
+	if start > 0 || end < len(m.hosts) {
+		b.WriteString(ui.InstructionStyle.Render(fmt.Sprintf("%d/%d hosts", m.hostCursor+1, len(m.hosts))) + "\n")
+	}
+	b.WriteString("\n")
+
+	identity := "[ ]"
+	if m.addIdentity {
+		identity = "[x]"
+	}
+	b.WriteString(fmt.Sprintf("%s Add \"IdentityFile %s\" to the config blocks of the hosts (i)\n\n", identity, keys.ConfigPath(m.key.Path)))
+	m.viewMessages(b)
+	b.WriteString(ui.InstructionStyle.Render(fmt.Sprintf("%d marked. Space to mark, a to mark all, i to toggle IdentityFile, Enter to install, Esc to go back", m.markedCount())))
+}
+
+// viewInstall renders the progress of the installation on each host
+func (m *KeyDeployModel) viewInstall(b *strings.Builder) {
+	b.WriteString(fmt.Sprintf("Installing %s (%s):\n\n", helpers.ShortenPath(m.key.Path), m.key.Fingerprint))
+	failed, waiting := 0, 0
+	for _, run := range m.runs {
+		name := fmt.Sprintf("%-30s ", run.host.Name)
+		switch run.status {
+		case statusRunning:
+			b.WriteString(ui.InstructionStyle.Render("… "+name+"installing") + "\n")
+		case statusFailed:
+			failed++
+			b.WriteString(ui.ErrorStyle.Render("✗ "+name+run.err.Error()) + "\n")
+		case statusNeedsTerminal:
+			waiting++
+			b.WriteString(ui.WarningStyle.Render("! "+name+run.err.Error()) + "\n")
+		default:
+			result := "key added"
+			if run.status == statusPresent {
+				result = "key already installed"
+			}
+			switch {
+			case run.identityErr != nil:
+				b.WriteString(ui.SearchStyle.Render("✓ "+name+result) + ui.ErrorStyle.Render(", cannot add IdentityFile: "+run.identityErr.Error()) + "\n")
+				continue
+			case run.identityAdded:
+				result += ", IdentityFile added"
+			}
+			b.WriteString(ui.SearchStyle.Render("✓ "+name+result) + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if m.remaining > 0 {
+		b.WriteString(ui.InstructionStyle.Render(fmt.Sprintf("%d of %d hosts done", len(m.runs)-m.remaining, len(m.runs))))
+		return
+	}
+	switch {
+	case waiting > 0:
+		hosts := "hosts need"
+		if waiting == 1 {
+			hosts = "host needs"
+		}
+		b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("%d %s a password or the host key accepted: p runs ssh in the terminal for each in turn, so you can answer it", waiting, hosts)) + "\n\n")
+		b.WriteString(ui.InstructionStyle.Render("p to install interactively, b to retry the failed hosts, Enter or Esc to go back to the host list"))
+		return
+	case failed > 0:
+		b.WriteString(ui.InstructionStyle.Render("b to retry the failed hosts, Enter or Esc to go back to the host list"))
+		return
+	}
+	b.WriteString(ui.InstructionStyle.Render("Enter or Esc to go back to the host list"))
+}
+
+// viewMessages renders the status or error line, if any
+func (m *KeyDeployModel) viewMessages(b *strings.Builder) {
+	if m.err != "" {
+		b.WriteString(ui.ErrorStyle.Render(m.err) + "\n\n")
+	} else if m.status != "" {
+		b.WriteString(ui.SearchStyle.Render(m.status) + "\n\n")
+	}
+}
+
+// destination describes where a host connects to, for the host list
+func destination(h types.SSHHost) string {
+	target := h.HostName
+	if target == "" {
+		target = h.Name
+	}
+	if h.User != "" {
+		target = h.User + "@" + target
+	}
+	if h.Port != "" && h.Port != types.DefaultSSHPort {
+		target += ":" + h.Port
+	}
+	return target
+}
@@ -0,0 +1,105 @@
+package optionsentry
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/sshagent"
+	"ssh-tui/internal/tui/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agentMsg delivers the result of the agent check
+type agentMsg sshagent.Report
+
+// SetAgentCheck makes the screen compare the host's IdentityFile keys with the agent's when it
+// opens, warning about keys that are not loaded. Ctrl+L then asks for them to be loaded with
+// ssh-add before connecting (see AgentLoad).
+func (m *OptionsEntryModel) SetAgentCheck(check func() sshagent.Report) {
+	m.agentCheck = check
+}
+
+// AgentLoad returns the keys to load into the agent before connecting and the constraints to
+// load them with; nil if none should be loaded
+func (m *OptionsEntryModel) AgentLoad() ([]string, sshagent.Constraints) {
+	constraints := sshagent.Constraints{Lifetime: sshagent.Lifetimes[m.lifetime], Confirm: m.confirmUse}
+	if !m.loadKeys || m.agent == nil {
+		return nil, constraints
+	}
+	return m.agent.Missing(), constraints
+}
+
+// checkAgent returns the command running the agent check
+func (m *OptionsEntryModel) checkAgent() tea.Cmd {
+	check := m.agentCheck
+	return func() tea.Msg {
+		return agentMsg(check())
+	}
+}
+
+// canLoadKeys reports whether the agent is reachable and keys of the host are missing from it
+func (m *OptionsEntryModel) canLoadKeys() bool {
+	return m.agent != nil && m.agent.Err == nil && len(m.agent.Missing()) > 0
+}
+
+// handleAgentKey handles the keys choosing how to load the missing keys, reporting whether the
+// key was one of them
+func (m *OptionsEntryModel) handleAgentKey(key string) bool {
+	if !m.canLoadKeys() {
+		return false
+	}
+	switch key {
+	case "ctrl+l":
+		m.loadKeys = !m.loadKeys
+	case "ctrl+t":
+		m.loadKeys = true
+		m.lifetime = (m.lifetime + 1) % len(sshagent.Lifetimes)
+	case "ctrl+y":
+		m.loadKeys = true
+		m.confirmUse = !m.confirmUse
+	default:
+		return false
+	}
+	return true
+}
+
+// renderAgent renders the agent section, or "" when no check was requested
+func (m *OptionsEntryModel) renderAgent() string {
+	if m.agentCheck == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render("SSH agent:") + "\n")
+	r := m.agent
+	if r == nil {
+		b.WriteString(ui.InstructionStyle.Render("Checking the agent…") + "\n")
+		return b.String()
+	}
+	if r.Err != nil {
+		b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("⚠ %v; ssh will ask for key passphrases itself", r.Err)) + "\n")
+	}
+	for _, f := range r.Files {
+		switch {
+		case f.Err != nil:
+			b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("⚠ Cannot check %s: %v", f.Path, f.Err)) + "\n")
+		case f.Loaded:
+			b.WriteString(ui.SearchStyle.Render(fmt.Sprintf("✓ %s is loaded", f.Path)) + "\n")
+		case r.Err == nil:
+			b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("⚠ %s is not loaded in the agent", f.Path)) + "\n")
+		}
+	}
+
+	if !m.canLoadKeys() {
+		return b.String()
+	}
+	if m.loadKeys {
+		_, constraints := m.AgentLoad()
+		b.WriteString(ui.SearchStyle.Render("ssh-add will load it before connecting, "+constraints.String()) + "\n")
+		b.WriteString(ui.InstructionStyle.Render("Ctrl+L don't load, Ctrl+T lifetime, Ctrl+Y confirm each use") + "\n")
+	} else {
+		b.WriteString(ui.InstructionStyle.Render("Ctrl+L to load it with ssh-add before connecting") + "\n")
+	}
+	return b.String()
+}
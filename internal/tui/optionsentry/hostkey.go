@@ -0,0 +1,84 @@
+package optionsentry
+
+import (
+	"fmt"
+	"strings"
+
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/tui/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+)
+
+// hostKeyMsg delivers the result of the host key check
+type hostKeyMsg hostkey.Report
+
+// SetHostKeyCheck makes the screen fetch the host's key with check when it opens, and show its
+// fingerprint and how it compares with known_hosts and the published fingerprints. When the key
+// does not match the published fingerprints, Enter has to be pressed twice to connect.
+func (m *OptionsEntryModel) SetHostKeyCheck(check func() hostkey.Report) {
+	m.hostKeyCheck = check
+}
+
+// HostKeyReport returns the result of the host key check, or nil if none has finished
+func (m *OptionsEntryModel) HostKeyReport() *hostkey.Report {
+	return m.hostKey
+}
+
+// checkHostKey returns the command running the host key check
+func (m *OptionsEntryModel) checkHostKey() tea.Cmd {
+	check := m.hostKeyCheck
+	return func() tea.Msg {
+		return hostKeyMsg(check())
+	}
+}
+
+// keyMismatch reports whether the host key differs from the published fingerprints
+func (m *OptionsEntryModel) keyMismatch() bool {
+	return m.hostKey != nil && m.hostKey.TrustedChecked && m.hostKey.Trusted == hostkey.VerdictMismatch
+}
+
+// renderHostKey renders the host key section, or "" when no check was requested
+func (m *OptionsEntryModel) renderHostKey() string {
+	if m.hostKeyCheck == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render("Host key:") + "\n")
+	r := m.hostKey
+	if r == nil {
+		b.WriteString(ui.InstructionStyle.Render("Fetching the host key…") + "\n")
+		return b.String()
+	}
+	if r.Key != nil {
+		b.WriteString(fmt.Sprintf("%s %s (%s)\n", r.Key.Type(), ssh.FingerprintSHA256(r.Key), r.Address))
+	}
+	if r.Err != nil {
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Cannot check the host key: %v", r.Err)) + "\n")
+		return b.String()
+	}
+
+	switch r.Known {
+	case hostkey.KnownNew:
+		b.WriteString(ui.WarningStyle.Render("⚠ Not in known_hosts yet; ssh will ask to add it") + "\n")
+	case hostkey.KnownMatch:
+		b.WriteString(ui.SearchStyle.Render("✓ Matches the key recorded in known_hosts") + "\n")
+	case hostkey.KnownChanged:
+		b.WriteString(ui.ErrorStyle.Render("✗ Differs from the key recorded in known_hosts") + "\n")
+	}
+
+	switch {
+	case r.TrustedErr != nil:
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Cannot compare with the published fingerprints: %v", r.TrustedErr)) + "\n")
+	case !r.TrustedChecked:
+	case r.Trusted == hostkey.VerdictMatch:
+		b.WriteString(ui.SearchStyle.Render(fmt.Sprintf("✓ Matches the published fingerprint (%s:%d)", r.Entry.File, r.Entry.Line)) + "\n")
+	case r.Trusted == hostkey.VerdictMismatch:
+		b.WriteString(ui.ErrorStyle.Render("✗ Does not match the fingerprints published for this host") + "\n")
+	default:
+		b.WriteString(ui.InstructionStyle.Render("No fingerprint is published for this host") + "\n")
+	}
+	return b.String()
+}
@@ -1,7 +1,9 @@
 package optionsentry
 
 import (
+	"ssh-tui/internal/hostkey"
 	"ssh-tui/internal/ssh"
+	"ssh-tui/internal/sshagent"
 	"ssh-tui/internal/types"
 	"strings"
 
@@ -10,13 +12,32 @@ import (
 
 // OptionsEntryModel represents the SSH options entry screen
 type OptionsEntryModel struct {
-	host      *types.SSHHost
-	options   string
+	host    *types.SSHHost
+	options string
+	// ssh arguments given on the command line, passed on as they are after the typed options
+	carried   []string
 	cursor    int
 	confirmed bool
 	cancelled bool
 	width     int
 	height    int
+
+	// Host key check (see SetHostKeyCheck)
+	hostKeyCheck func() hostkey.Report
+	hostKey      *hostkey.Report
+	// Set once Enter was pressed despite a host key mismatch
+	mismatchWarned bool
+	// Set when Enter was pressed before the host key check finished
+	enterPending bool
+
+	// Agent check (see SetAgentCheck)
+	agentCheck func() sshagent.Report
+	agent      *sshagent.Report
+	// How to load the keys missing from the agent: whether to, the index in sshagent.Lifetimes
+	// and whether the agent confirms each use
+	loadKeys   bool
+	lifetime   int
+	confirmUse bool
 }
 
 // NewOptionsEntryModel creates a new options entry model
@@ -30,9 +51,25 @@ func NewOptionsEntryModel(host *types.SSHHost) *OptionsEntryModel {
 	}
 }
 
+// NewOptionsEntryModelWithOptions creates an options entry model carrying ssh arguments given on
+// the command line. They are kept as separate arguments, so values with spaces survive, and
+// the options typed on the screen are added to them.
+func NewOptionsEntryModelWithOptions(host *types.SSHHost, carried []string) *OptionsEntryModel {
+	m := NewOptionsEntryModel(host)
+	m.carried = carried
+	return m
+}
+
 // Init implements the tea.Model interface
 func (m *OptionsEntryModel) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if m.hostKeyCheck != nil {
+		cmds = append(cmds, m.checkHostKey())
+	}
+	if m.agentCheck != nil {
+		cmds = append(cmds, m.checkAgent())
+	}
+	return tea.Batch(cmds...)
 }
 
 // GetOptions returns the entered options
@@ -50,7 +87,19 @@ func (m *OptionsEntryModel) IsCancelled() bool {
 	return m.cancelled
 }
 
-// GetCommand returns the SSH command that would be executed with current options
+// GetCommand returns the SSH command that would be executed with current options, quoting
+// carried arguments that contain spaces
 func (m *OptionsEntryModel) GetCommand() string {
-	return ssh.BuildSSHCommand(m.host, m.options)
+	command := ssh.BuildSSHCommand(m.host, strings.TrimSpace(m.options))
+	if len(m.carried) > 0 {
+		command += " " + ssh.JoinArgs(m.carried)
+	}
+	return command
+}
+
+// GetArgs returns the arguments for ssh: the destination, the typed options split at spaces
+// and the carried arguments as they were given
+func (m *OptionsEntryModel) GetArgs() []string {
+	args := strings.Fields(ssh.BuildSSHCommand(m.host, m.options))[1:]
+	return append(args, m.carried...)
 }
@@ -1,12 +1,18 @@
 package optionsentry
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"reflect"
 	"strings"
 	"testing"
 
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/sshagent"
 	"ssh-tui/internal/types"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestOptionsEntryModel_Update(t *testing.T) {
@@ -222,3 +228,167 @@ func TestOptionsEntryModel_CustomHost(t *testing.T) {
 		t.Errorf("View should contain custom host name")
 	}
 }
+
+func TestNewOptionsEntryModelWithOptions(t *testing.T) {
+	host := &types.SSHHost{Name: "web", HostName: "web.example.com", Source: types.SourceConfig}
+	model := NewOptionsEntryModelWithOptions(host, []string{"-o", "ProxyCommand ssh -W %h:%p bastion", "-i", "/path/with space/key"})
+
+	var updatedModel tea.Model = model
+	for _, r := range "-L 8080:localhost:80" {
+		updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	optModel := updatedModel.(*OptionsEntryModel)
+
+	// Carried arguments keep their spaces; typed options are split at them
+	want := []string{"web", "-L", "8080:localhost:80", "-o", "ProxyCommand ssh -W %h:%p bastion", "-i", "/path/with space/key"}
+	if got := optModel.GetArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetArgs() = %q, want %q", got, want)
+	}
+	if got, want := optModel.GetCommand(), "ssh web -L 8080:localhost:80 -o 'ProxyCommand ssh -W %h:%p bastion' -i '/path/with space/key'"; got != want {
+		t.Errorf("GetCommand() = %q, want %q", got, want)
+	}
+	if !strings.Contains(optModel.View(), "From the command line: -o 'ProxyCommand") {
+		t.Errorf("expected the carried arguments to be shown:\n%s", optModel.View())
+	}
+}
+
+func TestOptionsEntryModel_HostKeyCheck(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := signer.PublicKey()
+	host := &types.SSHHost{Name: "new.example.com", HostName: "new.example.com", Port: "22", Source: types.SourceCustom}
+
+	model := NewOptionsEntryModel(host)
+	model.SetHostKeyCheck(func() hostkey.Report {
+		return hostkey.Report{Address: "new.example.com:22", Key: key, Known: hostkey.KnownNew,
+			TrustedChecked: true, Trusted: hostkey.VerdictMismatch}
+	})
+	if !strings.Contains(model.View(), "Fetching the host key") {
+		t.Fatalf("expected the key to be fetched first")
+	}
+
+	cmd := model.Init()
+	if cmd == nil {
+		t.Fatalf("expected Init to start the host key check")
+	}
+	model.Update(cmd())
+	view := model.View()
+	for _, want := range []string{ssh.FingerprintSHA256(key), "ssh-ed25519", "Not in known_hosts yet", "Does not match the fingerprints published"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// A mismatch needs a second Enter
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.IsConfirmed() || !strings.Contains(model.View(), "Press Enter again") {
+		t.Fatalf("expected the first Enter to warn about the mismatch")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !model.IsConfirmed() {
+		t.Fatalf("expected the second Enter to confirm")
+	}
+}
+
+func TestOptionsEntryModel_EnterBeforeHostKeyCheck(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := &types.SSHHost{Name: "new.example.com", HostName: "new.example.com", Source: types.SourceCustom}
+	model := NewOptionsEntryModel(host)
+	model.SetHostKeyCheck(func() hostkey.Report {
+		return hostkey.Report{Address: "new.example.com:22", Key: signer.PublicKey(), Known: hostkey.KnownNew,
+			TrustedChecked: true, Trusted: hostkey.VerdictMismatch}
+	})
+	cmd := model.Init()
+
+	// Enter does nothing until the key has been checked
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.IsConfirmed() || !strings.Contains(model.View(), "Still checking the host key") {
+		t.Fatalf("expected Enter to wait for the host key check:\n%s", model.View())
+	}
+
+	// Once the mismatch is shown, it still needs two presses of Enter
+	model.Update(cmd())
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.IsConfirmed() || !strings.Contains(model.View(), "Press Enter again") {
+		t.Fatalf("expected the mismatch warning:\n%s", model.View())
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !model.IsConfirmed() {
+		t.Fatalf("expected the second Enter to confirm")
+	}
+}
+
+func TestOptionsEntryModel_AgentCheck(t *testing.T) {
+	host := &types.SSHHost{Name: "web", HostName: "web.example.com", Source: types.SourceConfig}
+	model := NewOptionsEntryModel(host)
+	model.SetAgentCheck(func() sshagent.Report {
+		return sshagent.Report{Files: []sshagent.KeyFile{
+			{Path: "/keys/id_loaded", Loaded: true},
+			{Path: "/keys/id_work"},
+		}}
+	})
+	if !strings.Contains(model.View(), "Checking the agent") {
+		t.Fatalf("expected the agent to be checked first")
+	}
+	model.Update(model.Init()())
+
+	view := model.View()
+	for _, want := range []string{"/keys/id_loaded is loaded", "/keys/id_work is not loaded", "Ctrl+L to load it"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if keys, _ := model.AgentLoad(); keys != nil {
+		t.Fatalf("expected no keys to load before Ctrl+L, got %v", keys)
+	}
+
+	// Ctrl+T picks the next lifetime and Ctrl+Y asks the agent to confirm each use
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	keys, constraints := model.AgentLoad()
+	if len(keys) != 1 || keys[0] != "/keys/id_work" || constraints.Lifetime != sshagent.Lifetimes[1] || !constraints.Confirm {
+		t.Fatalf("unexpected keys to load: %v %+v", keys, constraints)
+	}
+	if !strings.Contains(model.View(), "ssh-add will load it before connecting, for 15m, confirming each use") {
+		t.Errorf("expected the load to be described:\n%s", model.View())
+	}
+	if model.GetOptions() != "" {
+		t.Errorf("expected the agent keys not to be typed into the options, got %q", model.GetOptions())
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if keys, _ := model.AgentLoad(); keys != nil {
+		t.Errorf("expected Ctrl+L to cancel loading, got %v", keys)
+	}
+}
+
+func TestOptionsEntryModel_AgentUnavailable(t *testing.T) {
+	host := &types.SSHHost{Name: "web", HostName: "web.example.com", Source: types.SourceConfig}
+	model := NewOptionsEntryModel(host)
+	model.SetAgentCheck(func() sshagent.Report {
+		return sshagent.Report{Err: sshagent.ErrNoAgent, Files: []sshagent.KeyFile{{Path: "/keys/id_work"}}}
+	})
+	model.Update(model.Init()())
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+
+	if !strings.Contains(model.View(), "SSH_AUTH_SOCK is not set") || strings.Contains(model.View(), "Ctrl+L") {
+		t.Errorf("expected only the agent error without a load offer:\n%s", model.View())
+	}
+	if keys, _ := model.AgentLoad(); keys != nil {
+		t.Errorf("expected no keys to load without an agent, got %v", keys)
+	}
+}
@@ -1,6 +1,8 @@
 package optionsentry
 
 import (
+	"ssh-tui/internal/hostkey"
+	"ssh-tui/internal/sshagent"
 	"ssh-tui/internal/tui/helpers"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,7 +15,18 @@ func (m *OptionsEntryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
+	case hostKeyMsg:
+		r := hostkey.Report(msg)
+		m.hostKey = &r
+
+	case agentMsg:
+		r := sshagent.Report(msg)
+		m.agent = &r
+
 	case tea.KeyMsg:
+		if m.handleAgentKey(msg.String()) {
+			return m, nil
+		}
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -23,6 +36,16 @@ func (m *OptionsEntryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case "enter":
+			// The key has to be checked before connecting
+			if m.hostKeyCheck != nil && m.hostKey == nil {
+				m.enterPending = true
+				return m, nil
+			}
+			// A key that does not match the published fingerprints needs a second Enter
+			if m.keyMismatch() && !m.mismatchWarned {
+				m.mismatchWarned = true
+				return m, nil
+			}
 			m.confirmed = true
 			return m, tea.Quit
 
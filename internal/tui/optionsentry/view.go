@@ -5,6 +5,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"ssh-tui/internal/ssh"
 	"ssh-tui/internal/tui/helpers"
 	"ssh-tui/internal/tui/ui"
 	"ssh-tui/internal/types"
@@ -21,6 +22,12 @@ func (m *OptionsEntryModel) View() string {
 	if m.host.Source == types.SourceConfig {
 		b.WriteString("\n\n")
 	}
+	if hostKey := m.renderHostKey(); hostKey != "" {
+		b.WriteString(hostKey + "\n")
+	}
+	if agent := m.renderAgent(); agent != "" {
+		b.WriteString(agent + "\n")
+	}
 
 	b.WriteString(ui.TitleStyle.Render("Options:") + "\n")
 
@@ -35,6 +42,9 @@ func (m *OptionsEntryModel) View() string {
 	b.WriteString(inputStyle.Render(rendered))
 
 	b.WriteString("\n")
+	if len(m.carried) > 0 {
+		b.WriteString(ui.InstructionStyle.Render("From the command line: "+ssh.JoinArgs(m.carried)) + "\n")
+	}
 
 	b.WriteString(ui.InstructionStyle.Render(ui.ExamplesText) + "\n\n")
 
@@ -44,7 +54,13 @@ func (m *OptionsEntryModel) View() string {
 	currentCommand := m.GetCommand()
 	b.WriteString(currentCommand + "\n\n")
 
-	b.WriteString(ui.InstructionStyle.Render("Use Enter to execute, Esc to go back") + "\n\n")
+	if m.enterPending && m.hostKey == nil {
+		b.WriteString(ui.WarningStyle.Render("Still checking the host key; press Enter again once it is shown, Esc to go back") + "\n\n")
+	} else if m.mismatchWarned {
+		b.WriteString(ui.ErrorStyle.Render("The host key does not match the published fingerprints. Press Enter again to connect anyway, Esc to go back") + "\n\n")
+	} else {
+		b.WriteString(ui.InstructionStyle.Render("Use Enter to execute, Esc to go back") + "\n\n")
+	}
 
 	return b.String()
 }
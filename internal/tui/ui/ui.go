@@ -8,6 +8,14 @@ const (
 	TabForOptions  = "Tab for options"
 	ExamplesText   = "Examples: -L 8080:localhost:80 -i ~/.ssh/id_rsa -p 2222 -X"
 	SearchLabel    = "Search: "
+	SearchHint     = "(tag:NAME filters by tag)"
+
+	InstructionDetails      = "Ctrl+D for details"
+	InstructionCloseDetails = "Ctrl+D or Esc to close details"
+	InstructionManageHosts  = "Ctrl+N new, Ctrl+E edit, Ctrl+X delete host, Ctrl+G group, Ctrl+R ping, Ctrl+K known hosts, Ctrl+A agent, Ctrl+T keys"
+	InstructionPick         = "Use \u2191/\u2193 to navigate, Enter to pick, Esc to cancel"
+	InstructionGroup        = "Ctrl+G group, Ctrl+R ping"
+	InstructionHostForm     = "Use \u2191/\u2193 to move between fields, Ctrl+S to save, Esc to cancel"
 )
 
 // Shared styles used across TUI models. Exported so other files can reference them.
@@ -45,6 +53,12 @@ var (
 	NormalStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("252"))
 
+	TagStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("141"))
+
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
 	NormalContainerStyle = lipgloss.NewStyle().
 				Padding(0, 0, 0, 3)
 )
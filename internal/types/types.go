@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // SSHHost represents a parsed SSH host with its configuration
 type SSHHost struct {
 	Name     string
@@ -8,6 +10,35 @@ type SSHHost struct {
 	Port     string
 	Source   string
 	Aliases  []string
+	// SourceFile and SourceLine locate the entry the host was parsed from
+	SourceFile string
+	SourceLine int
+	// Directives holds every directive of the host's config block in file order
+	Directives []Directive
+	// HostKeys lists the known_hosts keys recorded for the host
+	HostKeys []HostKey
+	// Description and Tags come from "# @desc ..." and "# @tags a,b" comments above the config block
+	Description string
+	Tags        []string
+	// Notes holds the other comment lines directly preceding the host's config block
+	Notes []string
+	// LastConnected is the last time ssh-tui connected to the host (zero if never)
+	LastConnected time.Time
+}
+
+// Directive is a single keyword/value pair from an SSH config block
+type Directive struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// HostKey is a public key recorded for a host in a known_hosts file
+type HostKey struct {
+	Type        string
+	Fingerprint string
+	File        string
+	Line        int
 }
 
 const (